@@ -18,19 +18,36 @@ package appcheck
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/MicahParks/keyfunc"
 	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
 
 	"firebase.google.com/go/v4/internal"
 )
 
 // JWKSUrl is the URL of the JWKS used to verify App Check tokens.
+//
+// Deprecated: JWKSUrl is only read when a client is created, and is kept for backwards
+// compatibility. Set the FIREBASE_APP_CHECK_EMULATOR_HOST environment variable to verify tokens
+// against a local emulator instead.
 var JWKSUrl = "https://firebaseappcheck.googleapis.com/v1beta/jwks"
 
-const appCheckIssuer = "https://firebaseappcheck.googleapis.com/"
+const (
+	appCheckIssuer         = "https://firebaseappcheck.googleapis.com/"
+	appCheckV1BetaEndpoint = "https://firebaseappcheck.googleapis.com/v1beta"
+	emulatorHostEnvVar     = "FIREBASE_APP_CHECK_EMULATOR_HOST"
+)
+
+var emulatorToken = &oauth2.Token{
+	AccessToken: "owner",
+}
 
 var (
 	// ErrIncorrectAlgorithm is returned when the token is signed with a non-RSA256 algorithm.
@@ -60,34 +77,126 @@ type DecodedAppCheckToken struct {
 	IssuedAt  time.Time
 	AppID     string
 	Claims    map[string]interface{}
+
+	// AlreadyConsumed indicates whether the token was already consumed before the current call
+	// to VerifyTokenWithOptions. It is only populated when the Consume option is set.
+	AlreadyConsumed bool
+}
+
+// VerifyTokenOptions specifies additional options for verifying App Check tokens.
+type VerifyTokenOptions struct {
+	// Consume marks the token as consumed with the App Check backend after it has been verified
+	// locally, so that it cannot be accepted again. Tokens that are consumed this way should be
+	// minted by client apps as limited-use tokens.
+	//
+	// Consuming a token requires an RPC call to the App Check backend.
+	Consume bool
 }
 
 // Client is the interface for the Firebase App Check service.
 type Client struct {
-	projectID string
-	jwks      *keyfunc.JWKS
+	projectID    string
+	keySource    internal.JWKSSource
+	endpoint     string
+	betaEndpoint string // verifyAppCheckToken is only available in the v1beta API.
+	clock        internal.Clock
+
+	// The signer and the authenticated HTTP client are only required to create and consume
+	// tokens. They are initialized on first use, so that verifying tokens does not require
+	// credentials.
+	opts             []option.ClientOption
+	serviceAccountID string
+	version          string
+	httpOpts         []internal.HTTPOption
+	isEmulator       bool
+
+	mutex      sync.Mutex
+	signer     internal.CryptoSigner
+	httpClient *internal.HTTPClient
 }
 
 // NewClient creates a new instance of the Firebase App Check Client.
 //
 // This function can only be invoked from within the SDK. Client applications should access the
 // the App Check service through firebase.App.
+//
+// The public keys used to verify tokens are fetched with the HTTP client options of the App, but
+// without its credentials. When the FIREBASE_APP_CHECK_EMULATOR_HOST environment variable is set,
+// the keys are fetched from, and tokens are created and consumed with, the emulator at that host.
 func NewClient(ctx context.Context, conf *internal.AppCheckConfig) (*Client, error) {
-	// TODO: Add support for overriding the HTTP client using the App one.
-	jwks, err := keyfunc.Get(JWKSUrl, keyfunc.Options{
-		Ctx:             ctx,
-		RefreshInterval: 6 * time.Hour,
-	})
-	if err != nil {
-		return nil, err
+	httpOpts := []internal.HTTPOption{
+		internal.WithHeader("X-Firebase-Client", fmt.Sprintf("fire-admin-go/%s", conf.Version)),
+		internal.WithHeader("x-goog-api-client", internal.GetMetricsHeader(conf.Version)),
+	}
+
+	endpoint, betaEndpoint, jwksURL := appCheckV1Endpoint, appCheckV1BetaEndpoint, JWKSUrl
+	emulatorHost := os.Getenv(emulatorHostEnvVar)
+	if emulatorHost != "" {
+		baseURL := fmt.Sprintf("http://%s/firebaseappcheck.googleapis.com", emulatorHost)
+		endpoint, betaEndpoint = baseURL+"/v1", baseURL+"/v1beta"
+		jwksURL = betaEndpoint + "/jwks"
+	}
+	if conf.JWKSURL != "" {
+		jwksURL = conf.JWKSURL
+	}
+
+	keySource := conf.KeySource
+	if keySource == nil {
+		// The JWKS is public, so it is fetched without the credentials of the App.
+		hc, err := internal.NewJWKSHTTPClient(ctx, conf.Opts, httpOpts...)
+		if err != nil {
+			return nil, err
+		}
+		source := internal.NewHTTPJWKSSource(jwksURL, hc)
+		source.RefreshInterval = 6 * time.Hour
+		keySource = source
 	}
 
 	return &Client{
-		projectID: conf.ProjectID,
-		jwks:      jwks,
+		projectID:        conf.ProjectID,
+		keySource:        keySource,
+		endpoint:         endpoint,
+		betaEndpoint:     betaEndpoint,
+		clock:            internal.SystemClock,
+		opts:             conf.Opts,
+		serviceAccountID: conf.ServiceAccountID,
+		version:          conf.Version,
+		httpOpts:         httpOpts,
+		isEmulator:       emulatorHost != "",
 	}, nil
 }
 
+func (c *Client) getSigner(ctx context.Context) (internal.CryptoSigner, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.signer == nil {
+		signer, err := internal.NewCryptoSigner(ctx, c.opts, c.serviceAccountID, c.version)
+		if err != nil {
+			return nil, err
+		}
+		c.signer = signer
+	}
+	return c.signer, nil
+}
+
+func (c *Client) getHTTPClient(ctx context.Context) (*internal.HTTPClient, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.httpClient == nil {
+		opts := c.opts
+		if c.isEmulator {
+			opts = []option.ClientOption{option.WithTokenSource(oauth2.StaticTokenSource(emulatorToken))}
+		}
+		hc, _, err := internal.NewHTTPClient(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		hc.Opts = c.httpOpts
+		c.httpClient = hc
+	}
+	return c.httpClient, nil
+}
+
 // VerifyToken verifies the given App Check token.
 //
 // VerifyToken considers an App Check token string to be valid if all the following conditions are met:
@@ -100,7 +209,14 @@ func NewClient(ctx context.Context, conf *internal.AppCheckConfig) (*Client, err
 //
 // If any of the above conditions are not met, an error is returned. Otherwise a pointer to a
 // decoded App Check token is returned.
+//
+// The public keys used to verify App Check tokens are fetched on first use, and cached for 6
+// hours.
 func (c *Client) VerifyToken(token string) (*DecodedAppCheckToken, error) {
+	return c.verifyToken(context.Background(), token)
+}
+
+func (c *Client) verifyToken(ctx context.Context, token string) (*DecodedAppCheckToken, error) {
 	// References for checks:
 	// https://firebase.googleblog.com/2021/10/protecting-backends-with-app-check.html
 	// https://github.com/firebase/firebase-admin-node/blob/master/src/app-check/token-verifier.ts#L106
@@ -114,7 +230,7 @@ func (c *Client) VerifyToken(token string) (*DecodedAppCheckToken, error) {
 		if t.Header["typ"] != "JWT" {
 			return nil, ErrTokenType
 		}
-		return c.jwks.Keyfunc(t)
+		return c.keySource.Keyfunc(ctx, t)
 	})
 	if err != nil {
 		return nil, err
@@ -166,6 +282,50 @@ func (c *Client) VerifyToken(token string) (*DecodedAppCheckToken, error) {
 	return &appCheckToken, nil
 }
 
+// VerifyTokenWithOptions verifies the given App Check token with the provided options.
+//
+// The token is subject to the same checks as VerifyToken. In addition, if opts.Consume is set,
+// the token is marked as consumed with the App Check backend, and the AlreadyConsumed field of
+// the returned token indicates whether it had already been consumed by an earlier call. This
+// enables replay protection for endpoints that should only ever accept a token once.
+func (c *Client) VerifyTokenWithOptions(ctx context.Context, token string, opts VerifyTokenOptions) (*DecodedAppCheckToken, error) {
+	decoded, err := c.verifyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Consume {
+		consumed, err := c.consumeToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		decoded.AlreadyConsumed = consumed
+	}
+
+	return decoded, nil
+}
+
+func (c *Client) consumeToken(ctx context.Context, token string) (bool, error) {
+	req := &internal.Request{
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("%s/projects/%s:verifyAppCheckToken", c.betaEndpoint, c.projectID),
+		Body: internal.NewJSONEntity(map[string]string{
+			"app_check_token": token,
+		}),
+	}
+	var result struct {
+		AlreadyConsumed bool `json:"alreadyConsumed"`
+	}
+	hc, err := c.getHTTPClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, err := hc.DoAndUnmarshal(ctx, req, &result); err != nil {
+		return false, err
+	}
+	return result.AlreadyConsumed, nil
+}
+
 func contains(s []string, str string) bool {
 	for _, v := range s {
 		if v == str {
@@ -4,17 +4,21 @@ import (
 	"context"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"firebase.google.com/go/v4/internal"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
 )
 
 func TestVerifyTokenHasValidClaims(t *testing.T) {
@@ -29,9 +33,9 @@ func TestVerifyTokenHasValidClaims(t *testing.T) {
 		t.Fatalf("Error loading private key: %v", err)
 	}
 
-	JWKSUrl = ts.URL
 	conf := &internal.AppCheckConfig{
 		ProjectID: "project_id",
+		JWKSURL:   ts.URL,
 	}
 
 	client, err := NewClient(context.Background(), conf)
@@ -175,9 +179,9 @@ func TestVerifyTokenMustExist(t *testing.T) {
 	}
 	defer ts.Close()
 
-	JWKSUrl = ts.URL
 	conf := &internal.AppCheckConfig{
 		ProjectID: "project_id",
+		JWKSURL:   ts.URL,
 	}
 
 	client, err := NewClient(context.Background(), conf)
@@ -208,9 +212,9 @@ func TestVerifyTokenNotExpired(t *testing.T) {
 		t.Fatalf("Error loading private key: %v", err)
 	}
 
-	JWKSUrl = ts.URL
 	conf := &internal.AppCheckConfig{
 		ProjectID: "project_id",
+		JWKSURL:   ts.URL,
 	}
 
 	client, err := NewClient(context.Background(), conf)
@@ -264,6 +268,222 @@ func TestVerifyTokenNotExpired(t *testing.T) {
 	}
 }
 
+func TestVerifyTokenWithOptionsConsume(t *testing.T) {
+	ts, err := setupFakeJWKS()
+	if err != nil {
+		t.Fatalf("Error setting up fake JWKS server: %v", err)
+	}
+	defer ts.Close()
+
+	privateKey, err := loadPrivateKey()
+	if err != nil {
+		t.Fatalf("Error loading private key: %v", err)
+	}
+
+	conf := &internal.AppCheckConfig{
+		ProjectID: "project_id",
+		Opts:      testOpts,
+		JWKSURL:   ts.URL,
+	}
+	client, err := NewClient(context.Background(), conf)
+	if err != nil {
+		t.Fatalf("Error creating NewClient: %v", err)
+	}
+
+	mockTime := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	jwt.TimeFunc = func() time.Time {
+		return mockTime
+	}
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"aud": []string{"projects/12345678", "projects/project_id"},
+		"iss": "https://firebaseappcheck.googleapis.com/12345678",
+		"sub": "12345678:app:ID",
+		"exp": mockTime.Add(time.Hour).Unix(),
+		"iat": mockTime.Unix(),
+	})
+	jwtToken.Header["kid"] = "FGQdnRlzAmKyKr6-Hg_kMQrBkj_H6i6ADnBQz4OI6BU"
+	token, err := jwtToken.SignedString(privateKey)
+	if err != nil {
+		t.Fatalf("error generating JWT: %v", err)
+	}
+
+	var calls int
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		wantPath := "/projects/project_id:verifyAppCheckToken"
+		if r.URL.Path != wantPath {
+			t.Errorf("Path = %q; want = %q", r.URL.Path, wantPath)
+		}
+		if h := r.Header.Get("Authorization"); h != "Bearer test-token" {
+			t.Errorf("Authorization = %q; want = %q", h, "Bearer test-token")
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		var req map[string]string
+		if err := json.Unmarshal(b, &req); err != nil || req["app_check_token"] != token {
+			t.Errorf("Body = %s; want = app_check_token", string(b))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			w.Write([]byte(`{}`))
+		} else {
+			w.Write([]byte(`{"alreadyConsumed": true}`))
+		}
+	}))
+	defer backend.Close()
+	client.betaEndpoint = backend.URL
+
+	for _, want := range []bool{false, true} {
+		decoded, err := client.VerifyTokenWithOptions(context.Background(), token, VerifyTokenOptions{Consume: true})
+		if err != nil {
+			t.Fatalf("VerifyTokenWithOptions() = %v", err)
+		}
+		if decoded.AppID != "12345678:app:ID" || decoded.AlreadyConsumed != want {
+			t.Errorf("VerifyTokenWithOptions() = {AppID: %q, AlreadyConsumed: %v}; want = {%q, %v}",
+				decoded.AppID, decoded.AlreadyConsumed, "12345678:app:ID", want)
+		}
+	}
+
+	if _, err := client.VerifyTokenWithOptions(context.Background(), token, VerifyTokenOptions{}); err != nil {
+		t.Fatalf("VerifyTokenWithOptions() = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("verifyAppCheckToken calls = %d; want = 2", calls)
+	}
+
+	if _, err := client.VerifyTokenWithOptions(context.Background(), "", VerifyTokenOptions{Consume: true}); err == nil {
+		t.Errorf("VerifyTokenWithOptions(\"\") = nil; want = error")
+	}
+	if calls != 2 {
+		t.Errorf("verifyAppCheckToken calls = %d; want = 2", calls)
+	}
+}
+
+func TestNewClientJWKSHTTPClient(t *testing.T) {
+	ts, err := setupFakeJWKS()
+	if err != nil {
+		t.Fatalf("Error setting up fake JWKS server: %v", err)
+	}
+	defer ts.Close()
+
+	rt := &recordingTransport{}
+	client, err := NewClient(context.Background(), &internal.AppCheckConfig{
+		ProjectID: "project_id",
+		Opts:      []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: rt})},
+		JWKSURL:   ts.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.VerifyToken(testAppCheckToken(t)); err != nil {
+		t.Fatal(err)
+	}
+	if len(rt.requests) != 1 || rt.requests[0].URL.String() != ts.URL {
+		t.Errorf("JWKS requests = %v; want = [%s]", rt.requests, ts.URL)
+	}
+}
+
+func TestNewClientKeySource(t *testing.T) {
+	jwks, err := os.ReadFile("../testdata/mock.jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keySource, err := internal.NewStaticJWKSSource(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(context.Background(), &internal.AppCheckConfig{
+		ProjectID: "project_id",
+		JWKSURL:   "http://unused",
+		KeySource: keySource,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.VerifyToken(testAppCheckToken(t)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewClientEmulator(t *testing.T) {
+	jwks, err := os.ReadFile("../testdata/mock.jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var consumeAuth string
+	emulator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/firebaseappcheck.googleapis.com/v1beta/jwks":
+			w.Write(jwks)
+		case "/firebaseappcheck.googleapis.com/v1beta/projects/project_id:verifyAppCheckToken":
+			consumeAuth = r.Header.Get("Authorization")
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("Path = %q; want = emulator path", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer emulator.Close()
+	t.Setenv(emulatorHostEnvVar, strings.TrimPrefix(emulator.URL, "http://"))
+
+	client, err := NewClient(context.Background(), &internal.AppCheckConfig{ProjectID: "project_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEndpoint := emulator.URL + "/firebaseappcheck.googleapis.com/v1"
+	if client.endpoint != wantEndpoint || client.betaEndpoint != wantEndpoint+"beta" {
+		t.Errorf("endpoints = (%q, %q); want = (%q, %q)",
+			client.endpoint, client.betaEndpoint, wantEndpoint, wantEndpoint+"beta")
+	}
+
+	opts := VerifyTokenOptions{Consume: true}
+	if _, err := client.VerifyTokenWithOptions(context.Background(), testAppCheckToken(t), opts); err != nil {
+		t.Fatal(err)
+	}
+	if consumeAuth != "Bearer owner" {
+		t.Errorf("Authorization = %q; want = %q", consumeAuth, "Bearer owner")
+	}
+}
+
+// recordingTransport records the requests sent through it, and forwards them to the default
+// transport.
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, r)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// testAppCheckToken returns a valid App Check token for the project_id project.
+func testAppCheckToken(t *testing.T) string {
+	privateKey, err := loadPrivateKey()
+	if err != nil {
+		t.Fatalf("Error loading private key: %v", err)
+	}
+	now := jwt.TimeFunc()
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"aud": []string{"projects/12345678", "projects/project_id"},
+		"iss": "https://firebaseappcheck.googleapis.com/12345678",
+		"sub": "12345678:app:ID",
+		"exp": now.Add(time.Hour).Unix(),
+		"iat": now.Add(-time.Minute).Unix(),
+	})
+	jwtToken.Header["kid"] = "FGQdnRlzAmKyKr6-Hg_kMQrBkj_H6i6ADnBQz4OI6BU"
+	token, err := jwtToken.SignedString(privateKey)
+	if err != nil {
+		t.Fatalf("error generating JWT: %v", err)
+	}
+	return token
+}
+
+var testOpts = []option.ClientOption{
+	option.WithTokenSource(&internal.MockTokenSource{AccessToken: "test-token"}),
+}
+
 func setupFakeJWKS() (*httptest.Server, error) {
 	jwks, err := os.ReadFile("../testdata/mock.jwks.json")
 	if err != nil {
@@ -0,0 +1,220 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appcheck
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"firebase.google.com/go/v4/errorutils"
+	"firebase.google.com/go/v4/internal"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+)
+
+const testAppID = "1:1234:android:1234"
+
+func TestCreateToken(t *testing.T) {
+	var gotPath string
+	var gotCustomToken string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		b, _ := ioutil.ReadAll(r.Body)
+		var req map[string]string
+		if err := json.Unmarshal(b, &req); err != nil {
+			t.Fatal(err)
+		}
+		gotCustomToken = req["customToken"]
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token": "app-check-token", "ttl": "1800.5s"}`))
+	}))
+	defer ts.Close()
+
+	client := testTokenClient(t, ts.URL)
+	token, err := client.CreateToken(context.Background(), testAppID, 30*time.Minute+500*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Token{Token: "app-check-token", TTL: 30*time.Minute + 500*time.Millisecond}
+	if !cmp.Equal(token, want) {
+		t.Errorf("CreateToken() = %#v; want = %#v", token, want)
+	}
+	wantPath := "/projects/project_id/apps/" + testAppID + ":exchangeCustomToken"
+	if gotPath != wantPath {
+		t.Errorf("Path = %q; want = %q", gotPath, wantPath)
+	}
+
+	parts := strings.Split(gotCustomToken, ".")
+	if len(parts) != 3 {
+		t.Fatalf("customToken has %d segments; want = 3", len(parts))
+	}
+	var header customTokenHeader
+	decodeSegment(t, parts[0], &header)
+	if header.Algorithm != "RS256" || header.Type != "JWT" {
+		t.Errorf("header = %#v; want = {RS256 JWT}", header)
+	}
+
+	var payload customTokenPayload
+	decodeSegment(t, parts[1], &payload)
+	now := client.clock.Now().Unix()
+	wantPayload := customTokenPayload{
+		Iss:   payload.Iss,
+		Sub:   payload.Iss,
+		Aud:   appCheckAudience,
+		Iat:   now,
+		Exp:   now + 300,
+		AppID: testAppID,
+		TTL:   "1800.5s",
+	}
+	if payload.Iss == "" || payload != wantPayload {
+		t.Errorf("payload = %#v; want = %#v", payload, wantPayload)
+	}
+}
+
+func TestCreateTokenDefaultTTL(t *testing.T) {
+	var gotCustomToken string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		var req map[string]string
+		json.Unmarshal(b, &req)
+		gotCustomToken = req["customToken"]
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token": "app-check-token", "ttl": "3600s"}`))
+	}))
+	defer ts.Close()
+
+	client := testTokenClient(t, ts.URL)
+	token, err := client.CreateToken(context.Background(), testAppID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.TTL != time.Hour {
+		t.Errorf("TTL = %v; want = %v", token.TTL, time.Hour)
+	}
+
+	var payload map[string]interface{}
+	decodeSegment(t, strings.Split(gotCustomToken, ".")[1], &payload)
+	if _, ok := payload["ttl"]; ok {
+		t.Errorf("payload[ttl] = %v; want = absent", payload["ttl"])
+	}
+}
+
+func TestCreateTokenLazyInit(t *testing.T) {
+	var gotAuth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token": "app-check-token", "ttl": "3600s"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(context.Background(), &internal.AppCheckConfig{
+		ProjectID: "project_id",
+		Opts: []option.ClientOption{
+			option.WithCredentialsFile("../testdata/service_account.json"),
+			option.WithTokenSource(&internal.MockTokenSource{AccessToken: "test-token"}),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.signer != nil || client.httpClient != nil {
+		t.Fatalf("NewClient() = {signer: %v, httpClient: %v}; want = uninitialized", client.signer, client.httpClient)
+	}
+	client.endpoint = ts.URL
+
+	if _, err := client.CreateToken(context.Background(), testAppID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.signer.(*internal.ServiceAccountSigner); !ok {
+		t.Errorf("signer = %#v; want = ServiceAccountSigner", client.signer)
+	}
+	if gotAuth != "Bearer test-token" {
+		t.Errorf("Authorization = %q; want = %q", gotAuth, "Bearer test-token")
+	}
+}
+
+func TestCreateTokenInvalidArgs(t *testing.T) {
+	client := testTokenClient(t, "http://unused")
+	cases := []struct {
+		name  string
+		appID string
+		ttl   time.Duration
+	}{
+		{"EmptyAppID", "", 0},
+		{"TTLTooShort", testAppID, 29 * time.Minute},
+		{"TTLTooLong", testAppID, 7*24*time.Hour + time.Second},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := client.CreateToken(context.Background(), tc.appID, tc.ttl)
+			if token != nil || err == nil {
+				t.Errorf("CreateToken() = (%v, %v); want = (nil, error)", token, err)
+			}
+		})
+	}
+}
+
+func TestCreateTokenError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"status": "PERMISSION_DENIED", "message": "test error"}}`))
+	}))
+	defer ts.Close()
+
+	client := testTokenClient(t, ts.URL)
+	token, err := client.CreateToken(context.Background(), testAppID, 0)
+	if token != nil || !errorutils.IsPermissionDenied(err) || err.Error() != "test error" {
+		t.Errorf("CreateToken() = (%v, %v); want = (nil, PermissionDenied)", token, err)
+	}
+}
+
+func testTokenClient(t *testing.T, endpoint string) *Client {
+	creds, err := os.ReadFile("../testdata/service_account.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := internal.SignerFromCreds(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{
+		projectID:  "project_id",
+		signer:     signer,
+		httpClient: &internal.HTTPClient{Client: http.DefaultClient},
+		endpoint:   endpoint,
+		clock:      &internal.MockClock{Timestamp: time.Unix(1600000000, 0)},
+	}
+}
+
+func decodeSegment(t *testing.T, s string, v interface{}) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatal(err)
+	}
+}
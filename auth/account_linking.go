@@ -0,0 +1,290 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// LinkProvider links an existing user account to the specified provider, and returns the updated
+// user record.
+//
+// This is a shorthand for UpdateUser with UserToUpdate.ProviderToLink. The same restrictions
+// apply; in particular, a federated identity can only be linked to one user account at a time.
+func (c *baseClient) LinkProvider(ctx context.Context, uid string, provider *UserProvider) (*UserRecord, error) {
+	if provider == nil {
+		return nil, errors.New("provider must not be nil")
+	}
+	return c.UpdateUser(ctx, uid, (&UserToUpdate{}).ProviderToLink(provider))
+}
+
+// UnlinkProvider unlinks an existing user account from the specified providers, and returns the
+// updated user record.
+//
+// This is a shorthand for UpdateUser with UserToUpdate.ProvidersToDelete.
+func (c *baseClient) UnlinkProvider(ctx context.Context, uid string, providerIDs ...string) (*UserRecord, error) {
+	if len(providerIDs) == 0 {
+		return nil, errors.New("at least one provider id must be specified")
+	}
+	return c.UpdateUser(ctx, uid, (&UserToUpdate{}).ProvidersToDelete(providerIDs))
+}
+
+// ClaimsConflictPolicy specifies how MergeUsers resolves custom claims that are set to different
+// values on the two user accounts being merged.
+type ClaimsConflictPolicy int
+
+const (
+	// KeepPrimaryClaims keeps the value of the primary user. This is the default.
+	KeepPrimaryClaims ClaimsConflictPolicy = iota
+	// KeepSecondaryClaims keeps the value of the secondary user.
+	KeepSecondaryClaims
+	// FailOnClaimsConflict aborts the merge before any change is made.
+	FailOnClaimsConflict
+)
+
+// MergeUsersOptions specifies how MergeUsers merges two user accounts.
+type MergeUsersOptions struct {
+	ClaimsConflictPolicy ClaimsConflictPolicy
+	// AllowSkippedProviders deletes the secondary user even if some of its providers, other than
+	// the password provider, cannot be moved to the primary user. By default, the merge is aborted
+	// before any change is made in that case.
+	AllowSkippedProviders bool
+	// DryRun computes and returns the changes without applying them.
+	DryRun bool
+}
+
+// MergeUsersResult describes the changes made by MergeUsers.
+type MergeUsersResult struct {
+	PrimaryUID   string
+	SecondaryUID string
+	// The providers moved from the secondary user to the primary user.
+	MovedProviders []*UserProvider
+	// The providers of the secondary user that could not be moved to the primary user.
+	SkippedProviders []*SkippedProvider
+	// The names of the custom claims set to different values on the two users.
+	ConflictingClaims []string
+	// The custom claims of the primary user after the merge, or nil if they were not changed.
+	MergedClaims map[string]interface{}
+	// Whether the custom claims of the primary user have been updated.
+	ClaimsUpdated bool
+	// Whether the secondary user has been deleted.
+	SecondaryDeleted bool
+	// The providers that could not be linked back to the secondary user after moving them to the
+	// primary user failed. These providers are no longer linked to either user.
+	RelinkFailures []*RelinkFailure
+}
+
+// SkippedProvider describes a provider that MergeUsers did not move to the primary user.
+type SkippedProvider struct {
+	ProviderID string
+	UID        string
+	Reason     string
+}
+
+// RelinkFailure describes a provider that MergeUsers unlinked from the secondary user, and could not
+// link back to it.
+type RelinkFailure struct {
+	Provider *UserProvider
+	Reason   string
+}
+
+// MergeUsers merges a secondary user account into a primary user account, and deletes the secondary
+// user account.
+//
+// The providers linked to the secondary user are moved to the primary user, except for the
+// password provider, whose credentials cannot be moved, and for the providers that the primary
+// user is already linked to. Unless MergeUsersOptions.AllowSkippedProviders is set, the merge is
+// aborted if any provider other than the password provider cannot be moved. The custom claims of
+// both users are merged, and claims that are set on both users are resolved according to the
+// ClaimsConflictPolicy. Options may be nil.
+//
+// The merge consists of several requests, which the backend cannot apply atomically. The returned
+// MergeUsersResult always describes the changes that were applied, including when an error is
+// returned part way through the merge. If linking a provider to the primary user fails, the
+// providers that were not linked are linked back to the secondary user on a best effort basis,
+// and the providers that cannot be linked back are reported in MergeUsersResult.RelinkFailures.
+func (c *baseClient) MergeUsers(
+	ctx context.Context, primaryUID, secondaryUID string, opts *MergeUsersOptions) (*MergeUsersResult, error) {
+	if err := validateUID(primaryUID); err != nil {
+		return nil, err
+	}
+	if err := validateUID(secondaryUID); err != nil {
+		return nil, err
+	}
+	if primaryUID == secondaryUID {
+		return nil, errors.New("primary and secondary uids must be different")
+	}
+	if opts == nil {
+		opts = &MergeUsersOptions{}
+	}
+
+	primary, err := c.GetUser(ctx, primaryUID)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := c.GetUser(ctx, secondaryUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MergeUsersResult{
+		PrimaryUID:   primaryUID,
+		SecondaryUID: secondaryUID,
+	}
+	result.MovedProviders, result.SkippedProviders = planProviderMoves(primary, secondary)
+	if !opts.AllowSkippedProviders {
+		var skipped []string
+		for _, p := range result.SkippedProviders {
+			if p.ProviderID != "password" {
+				skipped = append(skipped, p.ProviderID)
+			}
+		}
+		if len(skipped) > 0 {
+			return nil, fmt.Errorf("providers %v of user %q cannot be moved to user %q",
+				skipped, secondaryUID, primaryUID)
+		}
+	}
+	merged, conflicts := mergeCustomClaims(primary.CustomClaims, secondary.CustomClaims, opts.ClaimsConflictPolicy)
+	result.ConflictingClaims = conflicts
+	if len(conflicts) > 0 && opts.ClaimsConflictPolicy == FailOnClaimsConflict {
+		return nil, fmt.Errorf("custom claims %v are set to different values on users %q and %q",
+			conflicts, primaryUID, secondaryUID)
+	}
+	if !reflect.DeepEqual(merged, primary.CustomClaims) {
+		if _, err := marshalCustomClaims(merged); err != nil {
+			return nil, err
+		}
+		result.MergedClaims = merged
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	moved := result.MovedProviders
+	result.MovedProviders = nil
+	if len(moved) > 0 {
+		ids := make([]string, len(moved))
+		for i, p := range moved {
+			ids[i] = p.ProviderID
+		}
+		// Federated identities and phone numbers must be unlinked before they can be linked to
+		// another user.
+		if err := c.updateUser(ctx, secondaryUID, (&UserToUpdate{}).ProvidersToDelete(ids)); err != nil {
+			return result, err
+		}
+		for i, p := range moved {
+			if err := c.updateUser(ctx, primaryUID, (&UserToUpdate{}).ProviderToLink(p)); err != nil {
+				result.RelinkFailures = c.relinkProviders(ctx, secondaryUID, moved[i:])
+				return result, err
+			}
+			result.MovedProviders = append(result.MovedProviders, p)
+		}
+	}
+
+	if result.MergedClaims != nil {
+		if err := c.SetCustomUserClaims(ctx, primaryUID, result.MergedClaims); err != nil {
+			return result, err
+		}
+		result.ClaimsUpdated = true
+	}
+
+	if err := c.DeleteUser(ctx, secondaryUID); err != nil {
+		return result, err
+	}
+	result.SecondaryDeleted = true
+	return result, nil
+}
+
+// relinkProviders links the given providers back to a user, and returns the providers that could
+// not be linked.
+func (c *baseClient) relinkProviders(
+	ctx context.Context, uid string, providers []*UserProvider) []*RelinkFailure {
+	var failures []*RelinkFailure
+	for _, p := range providers {
+		if err := c.updateUser(ctx, uid, (&UserToUpdate{}).ProviderToLink(p)); err != nil {
+			failures = append(failures, &RelinkFailure{Provider: p, Reason: err.Error()})
+		}
+	}
+	return failures
+}
+
+func planProviderMoves(primary, secondary *UserRecord) ([]*UserProvider, []*SkippedProvider) {
+	linked := make(map[string]bool)
+	for _, info := range primary.ProviderUserInfo {
+		linked[info.ProviderID] = true
+	}
+
+	var moved []*UserProvider
+	var skipped []*SkippedProvider
+	for _, info := range secondary.ProviderUserInfo {
+		var reason string
+		switch {
+		case info.ProviderID == "password":
+			reason = "password credentials cannot be moved to another user"
+		case linked[info.ProviderID]:
+			reason = "primary user is already linked to this provider"
+		}
+		if reason != "" {
+			skipped = append(skipped, &SkippedProvider{
+				ProviderID: info.ProviderID,
+				UID:        info.UID,
+				Reason:     reason,
+			})
+			continue
+		}
+
+		uid := info.UID
+		if info.ProviderID == "phone" && info.PhoneNumber != "" {
+			uid = info.PhoneNumber
+		}
+		moved = append(moved, &UserProvider{
+			UID:         uid,
+			ProviderID:  info.ProviderID,
+			Email:       info.Email,
+			DisplayName: info.DisplayName,
+			PhotoURL:    info.PhotoURL,
+		})
+	}
+	return moved, skipped
+}
+
+func mergeCustomClaims(
+	primary, secondary map[string]interface{}, policy ClaimsConflictPolicy) (map[string]interface{}, []string) {
+	if len(secondary) == 0 {
+		return primary, nil
+	}
+
+	merged := make(map[string]interface{}, len(primary)+len(secondary))
+	for k, v := range primary {
+		merged[k] = v
+	}
+	var conflicts []string
+	for k, v := range secondary {
+		current, ok := merged[k]
+		if ok && !reflect.DeepEqual(current, v) {
+			conflicts = append(conflicts, k)
+			if policy != KeepSecondaryClaims {
+				continue
+			}
+		}
+		merged[k] = v
+	}
+	sort.Strings(conflicts)
+	return merged, conflicts
+}
@@ -0,0 +1,319 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const mergePrimaryUser = `{
+	"localId": "primary",
+	"email": "user@example.com",
+	"customAttributes": "{\"role\": \"admin\", \"a\": 1}",
+	"providerUserInfo": [
+		{"providerId": "password", "rawId": "user@example.com", "email": "user@example.com"},
+		{"providerId": "google.com", "rawId": "google-primary", "email": "user@example.com"}
+	]
+}`
+
+const mergeSecondaryUser = `{
+	"localId": "secondary",
+	"email": "user@example.org",
+	"phoneNumber": "+11234567890",
+	"customAttributes": "{\"role\": \"user\", \"b\": 2}",
+	"providerUserInfo": [
+		{"providerId": "password", "rawId": "user@example.org", "email": "user@example.org"},
+		{"providerId": "google.com", "rawId": "google-secondary"},
+		{"providerId": "facebook.com", "rawId": "fb-secondary", "displayName": "User", "email": "user@example.org"},
+		{"providerId": "phone", "rawId": "+11234567890", "phoneNumber": "+11234567890"}
+	]
+}`
+
+type mergeRequest struct {
+	path string
+	body map[string]interface{}
+}
+
+// mergeServer serves the primary and secondary users, and records all the requests made to the
+// update and delete endpoints. Requests for which fail returns true are rejected.
+func mergeServer(t *testing.T, fail func(r mergeRequest) bool) (*mockAuthServer, *[]mergeRequest) {
+	var writes []mergeRequest
+	s := echoServer(nil, t)
+	s.Srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		path := r.URL.Path[strings.LastIndex(r.URL.Path, "/"):]
+		if path == "/accounts:lookup" {
+			user := mergePrimaryUser
+			if body["localId"].([]interface{})[0] == "secondary" {
+				user = mergeSecondaryUser
+			}
+			w.Write([]byte(`{"users": [` + user + `]}`))
+			return
+		}
+
+		req := mergeRequest{path, body}
+		writes = append(writes, req)
+		if fail != nil && fail(req) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"message": "FEDERATED_USER_ID_ALREADY_LINKED"}}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	return s, &writes
+}
+
+var wantMovedProviders = []*UserProvider{
+	{UID: "fb-secondary", ProviderID: "facebook.com", DisplayName: "User", Email: "user@example.org"},
+	{UID: "+11234567890", ProviderID: "phone"},
+}
+
+var wantSkippedProviders = []*SkippedProvider{
+	{
+		ProviderID: "password",
+		UID:        "user@example.org",
+		Reason:     "password credentials cannot be moved to another user",
+	},
+	{
+		ProviderID: "google.com",
+		UID:        "google-secondary",
+		Reason:     "primary user is already linked to this provider",
+	},
+}
+
+var allowSkipped = &MergeUsersOptions{AllowSkippedProviders: true}
+
+func TestMergeUsers(t *testing.T) {
+	s, writes := mergeServer(t, nil)
+	defer s.Close()
+
+	result, err := s.Client.MergeUsers(context.Background(), "primary", "secondary", allowSkipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &MergeUsersResult{
+		PrimaryUID:        "primary",
+		SecondaryUID:      "secondary",
+		MovedProviders:    wantMovedProviders,
+		SkippedProviders:  wantSkippedProviders,
+		ConflictingClaims: []string{"role"},
+		MergedClaims:      map[string]interface{}{"role": "admin", "a": float64(1), "b": float64(2)},
+		ClaimsUpdated:     true,
+		SecondaryDeleted:  true,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("MergeUsers() = %#v; want = %#v", result, want)
+	}
+
+	wantWrites := []mergeRequest{
+		{"/accounts:update", map[string]interface{}{
+			"localId":        "secondary",
+			"deleteProvider": []interface{}{"facebook.com", "phone"},
+		}},
+		{"/accounts:update", map[string]interface{}{
+			"localId": "primary",
+			"linkProviderUserInfo": map[string]interface{}{
+				"rawId":       "fb-secondary",
+				"providerId":  "facebook.com",
+				"displayName": "User",
+				"email":       "user@example.org",
+			},
+		}},
+		{"/accounts:update", map[string]interface{}{
+			"localId":     "primary",
+			"phoneNumber": "+11234567890",
+		}},
+		{"/accounts:update", map[string]interface{}{
+			"localId":          "primary",
+			"customAttributes": `{"a":1,"b":2,"role":"admin"}`,
+		}},
+		{"/accounts:delete", map[string]interface{}{
+			"localId": "secondary",
+		}},
+	}
+	if !reflect.DeepEqual(*writes, wantWrites) {
+		t.Errorf("MergeUsers() requests = %v; want = %v", *writes, wantWrites)
+	}
+}
+
+func TestMergeUsersKeepSecondaryClaims(t *testing.T) {
+	s, writes := mergeServer(t, nil)
+	defer s.Close()
+
+	result, err := s.Client.MergeUsers(context.Background(), "primary", "secondary", &MergeUsersOptions{
+		ClaimsConflictPolicy:  KeepSecondaryClaims,
+		AllowSkippedProviders: true,
+		DryRun:                true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantClaims := map[string]interface{}{"role": "user", "a": float64(1), "b": float64(2)}
+	if !reflect.DeepEqual(result.MergedClaims, wantClaims) {
+		t.Errorf("MergedClaims = %v; want = %v", result.MergedClaims, wantClaims)
+	}
+	if !reflect.DeepEqual(result.MovedProviders, wantMovedProviders) {
+		t.Errorf("MovedProviders = %v; want = %v", result.MovedProviders, wantMovedProviders)
+	}
+	if result.ClaimsUpdated || result.SecondaryDeleted || len(*writes) != 0 {
+		t.Errorf("MergeUsers(DryRun) = %+v, %d writes; want = no changes", result, len(*writes))
+	}
+}
+
+func TestMergeUsersFailOnClaimsConflict(t *testing.T) {
+	s, writes := mergeServer(t, nil)
+	defer s.Close()
+
+	result, err := s.Client.MergeUsers(context.Background(), "primary", "secondary", &MergeUsersOptions{
+		ClaimsConflictPolicy:  FailOnClaimsConflict,
+		AllowSkippedProviders: true,
+	})
+	want := `custom claims [role] are set to different values on users "primary" and "secondary"`
+	if result != nil || err == nil || err.Error() != want {
+		t.Errorf("MergeUsers() = (%v, %v); want = (nil, %q)", result, err, want)
+	}
+	if len(*writes) != 0 {
+		t.Errorf("MergeUsers() writes = %d; want = 0", len(*writes))
+	}
+}
+
+func TestMergeUsersSkippedProviders(t *testing.T) {
+	s, writes := mergeServer(t, nil)
+	defer s.Close()
+
+	result, err := s.Client.MergeUsers(context.Background(), "primary", "secondary", nil)
+	want := `providers [google.com] of user "secondary" cannot be moved to user "primary"`
+	if result != nil || err == nil || err.Error() != want {
+		t.Errorf("MergeUsers() = (%v, %v); want = (nil, %q)", result, err, want)
+	}
+	if len(*writes) != 0 {
+		t.Errorf("MergeUsers() writes = %d; want = 0", len(*writes))
+	}
+}
+
+func TestMergeUsersLinkError(t *testing.T) {
+	s, writes := mergeServer(t, func(r mergeRequest) bool {
+		return r.body["localId"] == "primary" && r.body["phoneNumber"] != nil
+	})
+	defer s.Close()
+
+	result, err := s.Client.MergeUsers(context.Background(), "primary", "secondary", allowSkipped)
+	if err == nil {
+		t.Fatal("MergeUsers() = nil; want = error")
+	}
+	if !reflect.DeepEqual(result.MovedProviders, wantMovedProviders[:1]) ||
+		result.ClaimsUpdated || result.SecondaryDeleted || len(result.RelinkFailures) != 0 {
+		t.Errorf("MergeUsers() = %#v; want = facebook.com moved only", result)
+	}
+
+	last := (*writes)[len(*writes)-1]
+	wantLast := mergeRequest{"/accounts:update", map[string]interface{}{
+		"localId":     "secondary",
+		"phoneNumber": "+11234567890",
+	}}
+	if !reflect.DeepEqual(last, wantLast) {
+		t.Errorf("MergeUsers() last request = %v; want = %v", last, wantLast)
+	}
+}
+
+func TestMergeUsersRelinkError(t *testing.T) {
+	s, _ := mergeServer(t, func(r mergeRequest) bool {
+		return r.body["phoneNumber"] != nil
+	})
+	defer s.Close()
+
+	result, err := s.Client.MergeUsers(context.Background(), "primary", "secondary", allowSkipped)
+	if err == nil {
+		t.Fatal("MergeUsers() = nil; want = error")
+	}
+	if len(result.RelinkFailures) != 1 {
+		t.Fatalf("RelinkFailures = %v; want = 1 failure", result.RelinkFailures)
+	}
+	failure := result.RelinkFailures[0]
+	if !reflect.DeepEqual(failure.Provider, wantMovedProviders[1]) || failure.Reason == "" {
+		t.Errorf("RelinkFailures[0] = %#v; want = phone provider", failure)
+	}
+}
+
+func TestMergeUsersInvalidArgs(t *testing.T) {
+	cases := []struct {
+		primary, secondary string
+		want               string
+	}{
+		{"", "secondary", "uid must be a non-empty string"},
+		{"primary", "", "uid must be a non-empty string"},
+		{"same", "same", "primary and secondary uids must be different"},
+	}
+	client := &Client{
+		baseClient: &baseClient{},
+	}
+	for _, tc := range cases {
+		result, err := client.MergeUsers(context.Background(), tc.primary, tc.secondary, nil)
+		if result != nil || err == nil || err.Error() != tc.want {
+			t.Errorf("MergeUsers(%q, %q) = (%v, %v); want = (nil, %q)", tc.primary, tc.secondary, result, err, tc.want)
+		}
+	}
+}
+
+func TestLinkProvider(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	provider := &UserProvider{UID: "google-uid", ProviderID: "google.com"}
+	user, err := s.Client.LinkProvider(context.Background(), "testuser", provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UID != "testuser" {
+		t.Errorf("LinkProvider() = %q; want = %q", user.UID, "testuser")
+	}
+
+	if len(s.Req) != 2 || s.Req[0].URL.Path != "/projects/mock-project-id/accounts:update" {
+		t.Fatalf("LinkProvider() requests = %d; want = update and lookup", len(s.Req))
+	}
+
+	if _, err := s.Client.LinkProvider(context.Background(), "testuser", nil); err == nil {
+		t.Errorf("LinkProvider(nil) = nil; want = error")
+	}
+}
+
+func TestUnlinkProvider(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	if _, err := s.Client.UnlinkProvider(context.Background(), "testuser", "google.com", "phone"); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Req) != 2 || s.Req[0].URL.Path != "/projects/mock-project-id/accounts:update" {
+		t.Fatalf("UnlinkProvider() requests = %d; want = update and lookup", len(s.Req))
+	}
+
+	if _, err := s.Client.UnlinkProvider(context.Background(), "testuser"); err == nil {
+		t.Errorf("UnlinkProvider() = nil; want = error")
+	}
+}
@@ -18,6 +18,7 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -38,10 +39,11 @@ const (
 	oneHourInSeconds   = 3600
 
 	// SDK-generated error codes
-	idTokenRevoked       = "ID_TOKEN_REVOKED"
-	userDisabled         = "USER_DISABLED"
-	sessionCookieRevoked = "SESSION_COOKIE_REVOKED"
-	tenantIDMismatch     = "TENANT_ID_MISMATCH"
+	idTokenRevoked         = "ID_TOKEN_REVOKED"
+	userDisabled           = "USER_DISABLED"
+	sessionCookieRevoked   = "SESSION_COOKIE_REVOKED"
+	tenantIDMismatch       = "TENANT_ID_MISMATCH"
+	concurrentModification = "CONCURRENT_MODIFICATION"
 )
 
 var reservedClaims = []string{
@@ -80,33 +82,9 @@ func NewClient(ctx context.Context, conf *internal.AuthConfig) (*Client, error)
 	}
 
 	if signer == nil {
-		creds, _ := transport.Creds(ctx, conf.Opts...)
-
-		// Initialize a signer by following the go/firebase-admin-sign protocol.
-		if creds != nil && len(creds.JSON) > 0 {
-			// If the SDK was initialized with a service account, use it to sign bytes.
-			signer, err = signerFromCreds(creds.JSON)
-			if err != nil && err != errNotAServiceAcct {
-				return nil, err
-			}
-		}
-	}
-
-	if signer == nil {
-		if conf.ServiceAccountID != "" {
-			// If the SDK was initialized with a service account email, use it with the IAM service
-			// to sign bytes.
-			signer, err = newIAMSigner(ctx, conf)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			// Use GAE signing capabilities if available. Otherwise, obtain a service account email
-			// from the local Metadata service, and fallback to the IAM service.
-			signer, err = newCryptoSigner(ctx, conf)
-			if err != nil {
-				return nil, err
-			}
+		signer, err = internal.NewCryptoSigner(ctx, conf.Opts, conf.ServiceAccountID, conf.Version)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -196,7 +174,90 @@ func (c *baseClient) CustomToken(ctx context.Context, uid string) (string, error
 // CustomTokenWithClaims is similar to CustomToken, but in addition to the user ID, it also encodes
 // all the key-value pairs in the provided map as claims in the resulting JWT.
 func (c *baseClient) CustomTokenWithClaims(ctx context.Context, uid string, devClaims map[string]interface{}) (string, error) {
-	iss, err := c.signer.Email(ctx)
+	return c.customToken(ctx, uid, devClaims, c.signer, c.tenantID, oneHourInSeconds)
+}
+
+// CustomTokenOptions specifies additional options for creating custom tokens with
+// CustomTokenWithOptions.
+type CustomTokenOptions struct {
+	// Claims are the developer claims to be encoded in the token. The serialized claims must not
+	// exceed 1000 bytes, and must not contain any reserved JWT claim names.
+	Claims map[string]interface{}
+
+	// ExpiresIn is the lifetime of the token. Defaults to 1 hour, which is also the maximum. Must
+	// be at least 1 second when specified.
+	ExpiresIn time.Duration
+
+	// TenantID binds the token to the specified tenant. When invoked on a TenantClient, this must
+	// either be empty or match the tenant ID of the client.
+	TenantID string
+
+	// ClaimsValidator is an optional hook invoked with the developer claims before the token is
+	// signed. It can be used to enforce an application-specific schema on the claims. If it
+	// returns an error, no token is created.
+	ClaimsValidator func(claims map[string]interface{}) error
+
+	// Signer is an optional Signer used to sign the token, in place of the one the SDK was
+	// initialized with. This enables signing tokens with keys held in an HSM or a cloud KMS.
+	Signer Signer
+}
+
+// CustomTokenWithOptions is similar to CustomTokenWithClaims, but additionally supports a shorter
+// token lifetime, explicit tenant binding, validation of the developer claims, and signing the
+// token with a custom Signer.
+func (c *baseClient) CustomTokenWithOptions(ctx context.Context, uid string, opts *CustomTokenOptions) (string, error) {
+	if opts == nil {
+		opts = &CustomTokenOptions{}
+	}
+
+	expiresIn := int64(oneHourInSeconds)
+	if opts.ExpiresIn != 0 {
+		if opts.ExpiresIn < time.Second || opts.ExpiresIn > oneHourInSeconds*time.Second {
+			return "", errors.New("custom token expiry duration must be between 1 second and 1 hour")
+		}
+		expiresIn = int64(opts.ExpiresIn.Seconds())
+	}
+
+	tenantID := c.tenantID
+	if opts.TenantID != "" {
+		if tenantID != "" && tenantID != opts.TenantID {
+			return "", fmt.Errorf("tenant id %q does not match the tenant id of the client: %q", opts.TenantID, tenantID)
+		}
+		tenantID = opts.TenantID
+	}
+
+	if len(opts.Claims) > 0 {
+		b, err := json.Marshal(opts.Claims)
+		if err != nil {
+			return "", fmt.Errorf("developer claims marshaling error: %v", err)
+		}
+		if len(b) > maxLenPayloadCC {
+			return "", fmt.Errorf("serialized developer claims must not exceed %d bytes", maxLenPayloadCC)
+		}
+	}
+
+	if opts.ClaimsValidator != nil {
+		if err := opts.ClaimsValidator(opts.Claims); err != nil {
+			return "", fmt.Errorf("invalid developer claims: %v", err)
+		}
+	}
+
+	var signer cryptoSigner = c.signer
+	if opts.Signer != nil {
+		signer = opts.Signer
+	}
+	return c.customToken(ctx, uid, opts.Claims, signer, tenantID, expiresIn)
+}
+
+func (c *baseClient) customToken(
+	ctx context.Context,
+	uid string,
+	devClaims map[string]interface{},
+	signer cryptoSigner,
+	tenantID string,
+	expiresIn int64,
+) (string, error) {
+	iss, err := signer.Email(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -219,19 +280,19 @@ func (c *baseClient) CustomTokenWithClaims(ctx context.Context, uid string, devC
 
 	now := c.clock.Now().Unix()
 	info := &jwtInfo{
-		header: jwtHeader{Algorithm: c.signer.Algorithm(), Type: "JWT"},
+		header: jwtHeader{Algorithm: signer.Algorithm(), Type: "JWT"},
 		payload: &customToken{
 			Iss:      iss,
 			Sub:      iss,
 			Aud:      firebaseAudience,
 			UID:      uid,
 			Iat:      now,
-			Exp:      now + oneHourInSeconds,
-			TenantID: c.tenantID,
+			Exp:      now + expiresIn,
+			TenantID: tenantID,
 			Claims:   devClaims,
 		},
 	}
-	return info.Token(ctx, c.signer)
+	return info.Token(ctx, signer)
 }
 
 // SessionCookie creates a new Firebase session cookie from the given ID token and expiry
@@ -329,18 +390,19 @@ func (c *baseClient) verifyIDToken(ctx context.Context, idToken string, checkRev
 		return nil, err
 	}
 
+	return c.checkIDToken(ctx, decoded, checkRevokedOrDisabled)
+}
+
+// checkIDToken checks that an ID token, whose signature and payload have already been verified,
+// belongs to the tenant of the client. In emulator mode, or if checkRevokedOrDisabled is true, it
+// also checks that the token has not been revoked or disabled.
+func (c *baseClient) checkIDToken(ctx context.Context, decoded *Token, checkRevokedOrDisabled bool) (*Token, error) {
 	if c.tenantID != "" && c.tenantID != decoded.Firebase.Tenant {
-		return nil, &internal.FirebaseError{
-			ErrorCode: internal.InvalidArgument,
-			String:    fmt.Sprintf("invalid tenant id: %q", decoded.Firebase.Tenant),
-			Ext: map[string]interface{}{
-				authErrorCode: tenantIDMismatch,
-			},
-		}
+		return nil, newTenantIDMismatchError(fmt.Sprintf("invalid tenant id: %q", decoded.Firebase.Tenant))
 	}
 
 	if c.isEmulator || checkRevokedOrDisabled {
-		err = c.checkRevokedOrDisabled(ctx, decoded, idTokenRevoked, "ID token has been revoked")
+		err := c.checkRevokedOrDisabled(ctx, decoded, idTokenRevoked, "ID token has been revoked")
 		if err != nil {
 			return nil, err
 		}
@@ -349,6 +411,16 @@ func (c *baseClient) verifyIDToken(ctx context.Context, idToken string, checkRev
 	return decoded, nil
 }
 
+func newTenantIDMismatchError(msg string) error {
+	return &internal.FirebaseError{
+		ErrorCode: internal.InvalidArgument,
+		String:    msg,
+		Ext: map[string]interface{}{
+			authErrorCode: tenantIDMismatch,
+		},
+	}
+}
+
 // IsTenantIDMismatch checks if the given error was due to a mismatched tenant ID in a JWT.
 func IsTenantIDMismatch(err error) bool {
 	return hasAuthErrorCode(err, tenantIDMismatch)
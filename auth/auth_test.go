@@ -99,7 +99,7 @@ func TestNewClientWithServiceAccountCredentials(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if _, ok := client.signer.(*serviceAccountSigner); !ok {
+	if _, ok := client.signer.(*internal.ServiceAccountSigner); !ok {
 		t.Errorf("NewClient().signer = %#v; want = serviceAccountSigner", client.signer)
 	}
 	if err := checkIDTokenVerifier(client.idTokenVerifier, creds.ProjectID); err != nil {
@@ -126,7 +126,7 @@ func TestNewClientWithoutCredentials(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if _, ok := client.signer.(*iamSigner); !ok {
+	if _, ok := client.signer.(*internal.IAMSigner); !ok {
 		t.Errorf("NewClient().signer = %#v; want = iamSigner", client.signer)
 	}
 	if err := checkIDTokenVerifier(client.idTokenVerifier, ""); err != nil {
@@ -154,7 +154,7 @@ func TestNewClientWithServiceAccountID(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if _, ok := client.signer.(*iamSigner); !ok {
+	if _, ok := client.signer.(*internal.IAMSigner); !ok {
 		t.Errorf("NewClient().signer = %#v; want = iamSigner", client.signer)
 	}
 	if err := checkIDTokenVerifier(client.idTokenVerifier, ""); err != nil {
@@ -192,7 +192,7 @@ func TestNewClientWithUserCredentials(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if _, ok := client.signer.(*iamSigner); !ok {
+	if _, ok := client.signer.(*internal.IAMSigner); !ok {
 		t.Errorf("NewClient().signer = %#v; want = iamSigner", client.signer)
 	}
 	if err := checkIDTokenVerifier(client.idTokenVerifier, ""); err != nil {
@@ -416,6 +416,173 @@ func TestCustomTokenError(t *testing.T) {
 	}
 }
 
+func TestCustomTokenWithOptions(t *testing.T) {
+	client := &Client{
+		baseClient: &baseClient{
+			signer: testSigner,
+			clock:  testClock,
+		},
+	}
+	claims := map[string]interface{}{
+		"foo":     "bar",
+		"premium": true,
+	}
+	var validated map[string]interface{}
+	token, err := client.CustomTokenWithOptions(context.Background(), "user1", &CustomTokenOptions{
+		Claims:   claims,
+		TenantID: "tenantID",
+		ClaimsValidator: func(c map[string]interface{}) error {
+			validated = c
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyCustomToken(context.Background(), token, claims, "tenantID"); err != nil {
+		t.Fatal(err)
+	}
+	if validated["foo"] != "bar" {
+		t.Errorf("ClaimsValidator() claims = %v; want = %v", validated, claims)
+	}
+}
+
+func TestCustomTokenWithNilOptions(t *testing.T) {
+	client := &Client{
+		baseClient: &baseClient{
+			signer: testSigner,
+			clock:  testClock,
+		},
+	}
+	token, err := client.CustomTokenWithOptions(context.Background(), "user1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyCustomToken(context.Background(), token, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCustomTokenWithExpiresIn(t *testing.T) {
+	client := &Client{
+		baseClient: &baseClient{
+			signer: testSigner,
+			clock:  testClock,
+		},
+	}
+	token, err := client.CustomTokenWithOptions(context.Background(), "user1", &CustomTokenOptions{
+		ExpiresIn: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload customToken
+	if err := decode(strings.Split(token, ".")[1], &payload); err != nil {
+		t.Fatal(err)
+	}
+	now := testClock.Now().Unix()
+	if payload.Iat != now || payload.Exp != now+300 {
+		t.Errorf("(Iat, Exp) = (%d, %d); want = (%d, %d)", payload.Iat, payload.Exp, now, now+300)
+	}
+}
+
+func TestCustomTokenWithOptionsForTenant(t *testing.T) {
+	client := &Client{
+		baseClient: &baseClient{
+			tenantID: "tenantID",
+			signer:   testSigner,
+			clock:    testClock,
+		},
+	}
+	for _, tenantID := range []string{"", "tenantID"} {
+		token, err := client.CustomTokenWithOptions(context.Background(), "user1", &CustomTokenOptions{
+			TenantID: tenantID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := verifyCustomToken(context.Background(), token, nil, "tenantID"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	token, err := client.CustomTokenWithOptions(context.Background(), "user1", &CustomTokenOptions{
+		TenantID: "otherTenantID",
+	})
+	if token != "" || err == nil {
+		t.Errorf("CustomTokenWithOptions(otherTenantID) = (%q, %v); want = (\"\", error)", token, err)
+	}
+}
+
+func TestCustomTokenWithSigner(t *testing.T) {
+	client := &Client{
+		baseClient: &baseClient{
+			signer: emulatedSigner{},
+			clock:  testClock,
+		},
+	}
+	token, err := client.CustomTokenWithOptions(context.Background(), "user1", &CustomTokenOptions{
+		Signer: testSigner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyCustomToken(context.Background(), token, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := &mockSigner{err: errors.New("kms unavailable")}
+	token, err = client.CustomTokenWithOptions(context.Background(), "user1", &CustomTokenOptions{
+		Signer: signer,
+	})
+	if token != "" || err != signer.err {
+		t.Errorf("CustomTokenWithOptions() = (%q, %v); want = (\"\", %v)", token, err, signer.err)
+	}
+}
+
+func TestCustomTokenWithOptionsError(t *testing.T) {
+	cases := []struct {
+		name string
+		uid  string
+		opts *CustomTokenOptions
+	}{
+		{"EmptyName", "", nil},
+		{"LongUid", strings.Repeat("a", 129), nil},
+		{"ReservedClaim", "uid", &CustomTokenOptions{
+			Claims: map[string]interface{}{"sub": "1234"},
+		}},
+		{"LargeClaims", "uid", &CustomTokenOptions{
+			Claims: map[string]interface{}{"key": strings.Repeat("a", 1000)},
+		}},
+		{"UnmarshallableClaims", "uid", &CustomTokenOptions{
+			Claims: map[string]interface{}{"key": func() {}},
+		}},
+		{"NegativeExpiresIn", "uid", &CustomTokenOptions{ExpiresIn: -time.Minute}},
+		{"ShortExpiresIn", "uid", &CustomTokenOptions{ExpiresIn: time.Millisecond}},
+		{"LongExpiresIn", "uid", &CustomTokenOptions{ExpiresIn: time.Hour + time.Second}},
+		{"ValidatorError", "uid", &CustomTokenOptions{
+			Claims: map[string]interface{}{"role": "superuser"},
+			ClaimsValidator: func(c map[string]interface{}) error {
+				return fmt.Errorf("unknown role: %v", c["role"])
+			},
+		}},
+	}
+
+	client := &baseClient{
+		signer: testSigner,
+		clock:  testClock,
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := client.CustomTokenWithOptions(context.Background(), tc.uid, tc.opts)
+			if token != "" || err == nil {
+				t.Errorf("CustomTokenWithOptions(%q) = (%q, %v); want = (\"\", error)", tc.name, token, err)
+			}
+		})
+	}
+}
+
 func TestCustomTokenInvalidCredential(t *testing.T) {
 	ctx := context.Background()
 	conf := &internal.AuthConfig{
@@ -426,7 +593,7 @@ func TestCustomTokenInvalidCredential(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	s.signer.(*iamSigner).httpClient.RetryConfig = nil
+	s.signer.(*internal.IAMSigner).HTTPClient.RetryConfig = nil
 	token, err := s.CustomToken(ctx, "user1")
 	if token != "" || err == nil {
 		t.Errorf("CustomTokenWithClaims() = (%q, %v); want = (\"\", error)", token, err)
@@ -1252,7 +1419,7 @@ func signerForTests(ctx context.Context) (cryptoSigner, error) {
 		return nil, err
 	}
 
-	return signerFromCreds(creds.JSON)
+	return internal.SignerFromCreds(creds.JSON)
 }
 
 func idTokenVerifierForTests(ctx context.Context) (*tokenVerifier, error) {
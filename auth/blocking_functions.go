@@ -0,0 +1,638 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"firebase.google.com/go/v4/internal"
+)
+
+const (
+	blockingTokenInvalid = "BLOCKING_TOKEN_INVALID"
+
+	// BlockingEventBeforeCreate is the event type of blocking functions triggered before a new
+	// user is saved to the Firebase Auth database.
+	BlockingEventBeforeCreate = "beforeCreate"
+	// BlockingEventBeforeSignIn is the event type of blocking functions triggered before a user
+	// is signed in.
+	BlockingEventBeforeSignIn = "beforeSignIn"
+)
+
+// IsBlockingTokenInvalid checks if the given error was due to an invalid blocking function token.
+func IsBlockingTokenInvalid(err error) bool {
+	return hasAuthErrorCode(err, blockingTokenInvalid)
+}
+
+// AuthBlockingEvent is a decoded blocking function event, sent by Identity Platform to a blocking
+// function endpoint before a user is created or signed in.
+type AuthBlockingEvent struct {
+	EventID string
+	// EventType is one of BlockingEventBeforeCreate and BlockingEventBeforeSignIn.
+	EventType string
+	// Audience is the URL of the blocking function endpoint the event was sent to.
+	Audience string
+	// Timestamp is the time at which the event was issued, in seconds since epoch.
+	Timestamp          int64
+	IPAddress          string
+	UserAgent          string
+	Locale             string
+	TenantID           string
+	User               *UserRecord
+	AdditionalUserInfo *BlockingAdditionalUserInfo
+	// Credential is the credential used to sign in the user, or nil when the sign-in method does
+	// not produce a credential (for example email and password sign-in).
+	Credential *BlockingCredential
+}
+
+// BlockingAdditionalUserInfo contains additional information about the user that is being created
+// or signed in.
+type BlockingAdditionalUserInfo struct {
+	ProviderID string
+	// Profile is the raw user information returned by the identity provider.
+	Profile   map[string]interface{}
+	Username  string
+	IsNewUser bool
+}
+
+// BlockingCredential contains the credential issued by the identity provider used to sign in the
+// user.
+type BlockingCredential struct {
+	// Claims contains the attributes of the SAML assertion, or the claims of the OIDC ID token.
+	Claims       map[string]interface{}
+	IDToken      string
+	AccessToken  string
+	RefreshToken string
+	// ExpirationTime is the expiration time of the access token, in seconds since epoch.
+	ExpirationTime int64
+	Secret         string
+	ProviderID     string
+	SignInMethod   string
+}
+
+// BlockingEventOption is an option for the VerifyBlockingEventToken() function.
+type BlockingEventOption interface {
+	applyTo(opts *blockingEventOptions)
+}
+
+type blockingEventOptions struct {
+	audience   string
+	eventTypes []string
+}
+
+type blockingEventOptionFunc func(opts *blockingEventOptions)
+
+func (f blockingEventOptionFunc) applyTo(opts *blockingEventOptions) {
+	f(opts)
+}
+
+// WithAudience returns a BlockingEventOption that requires the token to be issued for the given
+// audience, which is the full URL of the blocking function endpoint (for example
+// "https://us-central1-my-project.cloudfunctions.net/beforeSignIn").
+//
+// The audience is required by VerifyBlockingEventToken. BlockingHandler derives it from the
+// incoming request by default.
+func WithAudience(audience string) BlockingEventOption {
+	return blockingEventOptionFunc(func(opts *blockingEventOptions) {
+		opts.audience = audience
+	})
+}
+
+// WithEventTypes returns a BlockingEventOption that requires the token to be issued for one of
+// the given event types.
+func WithEventTypes(eventTypes ...string) BlockingEventOption {
+	return blockingEventOptionFunc(func(opts *blockingEventOptions) {
+		opts.eventTypes = eventTypes
+	})
+}
+
+// VerifyBlockingEventToken verifies the signed JWT that Identity Platform sends to blocking
+// functions, and returns the decoded AuthBlockingEvent.
+//
+// The token must be signed by Identity Platform, issued for the project of this client and for
+// the blocking function endpoint specified with WithAudience, not expired, and issued for a known
+// event type. The audience is required, so that a token sent to one blocking function cannot be
+// replayed against another. Use WithEventTypes to restrict the accepted event types.
+func (c *Client) VerifyBlockingEventToken(
+	ctx context.Context, token string, opts ...BlockingEventOption) (*AuthBlockingEvent, error) {
+
+	tv := c.idTokenVerifier
+	if tv.projectID == "" {
+		return nil, errors.New("project id not available")
+	}
+	if token == "" {
+		return nil, blockingTokenError("blocking token must be a non-empty string")
+	}
+
+	var o blockingEventOptions
+	for _, opt := range opts {
+		opt.applyTo(&o)
+	}
+	if o.audience == "" {
+		return nil, errors.New("audience must be specified with WithAudience")
+	}
+
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, blockingTokenError("blocking token has incorrect number of segments")
+	}
+	var header jwtHeader
+	var payload blockingTokenPayload
+	if err := decode(segments[0], &header); err != nil {
+		return nil, blockingTokenError("failed to decode blocking token header: %v", err)
+	}
+	if err := decode(segments[1], &payload); err != nil {
+		return nil, blockingTokenError("failed to decode blocking token payload: %v", err)
+	}
+
+	if !c.isEmulator && header.KeyID == "" {
+		return nil, blockingTokenError("blocking token has no 'kid' header")
+	}
+	if !c.isEmulator && header.Algorithm != "RS256" {
+		return nil, blockingTokenError(
+			"blocking token has invalid algorithm; expected 'RS256' but got %q", header.Algorithm)
+	}
+	if issuer := idTokenIssuerPrefix + tv.projectID; payload.Issuer != issuer {
+		return nil, blockingTokenError(
+			"blocking token has invalid 'iss' (issuer) claim; expected %q but got %q", issuer, payload.Issuer)
+	}
+	if payload.Audience != o.audience {
+		return nil, blockingTokenError(
+			"blocking token has invalid 'aud' (audience) claim; expected %q but got %q", o.audience, payload.Audience)
+	}
+	if err := validateBlockingEventType(payload.EventType, o.eventTypes); err != nil {
+		return nil, err
+	}
+	if payload.UserRecord == nil || payload.UserRecord.UID == "" {
+		return nil, blockingTokenError("blocking token has no user record")
+	}
+
+	now := tv.clock.Now().Unix()
+	if payload.IssuedAt-clockSkewSeconds > now {
+		return nil, blockingTokenError("blocking token issued at future timestamp: %d", payload.IssuedAt)
+	}
+	if payload.Expires+clockSkewSeconds < now {
+		return nil, blockingTokenError("blocking token has expired at: %d", payload.Expires)
+	}
+
+	if !c.isEmulator {
+		keys, err := tv.keySource.Keys(ctx)
+		if err != nil {
+			return nil, &internal.FirebaseError{
+				ErrorCode: internal.Unknown,
+				String:    err.Error(),
+				Ext:       map[string]interface{}{authErrorCode: certificateFetchFailed},
+			}
+		}
+		if !tv.verifySignatureWithKeys(ctx, token, keys) {
+			return nil, blockingTokenError("failed to verify blocking token signature")
+		}
+	}
+
+	return payload.toEvent(now)
+}
+
+func validateBlockingEventType(eventType string, allowed []string) error {
+	if eventType != BlockingEventBeforeCreate && eventType != BlockingEventBeforeSignIn {
+		return blockingTokenError("blocking token has unsupported event type: %q", eventType)
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, t := range allowed {
+		if t == eventType {
+			return nil
+		}
+	}
+	return blockingTokenError("blocking token has unexpected event type: %q", eventType)
+}
+
+func blockingTokenError(format string, args ...interface{}) error {
+	return &internal.FirebaseError{
+		ErrorCode: internal.InvalidArgument,
+		String:    fmt.Sprintf(format, args...),
+		Ext:       map[string]interface{}{authErrorCode: blockingTokenInvalid},
+	}
+}
+
+type blockingTokenPayload struct {
+	Issuer            string                 `json:"iss"`
+	Audience          string                 `json:"aud"`
+	IssuedAt          int64                  `json:"iat"`
+	Expires           int64                  `json:"exp"`
+	EventID           string                 `json:"event_id"`
+	EventType         string                 `json:"event_type"`
+	IPAddress         string                 `json:"ip_address"`
+	UserAgent         string                 `json:"user_agent"`
+	Locale            string                 `json:"locale"`
+	SignInMethod      string                 `json:"sign_in_method"`
+	TenantID          string                 `json:"tenant_id"`
+	UserRecord        *blockingUserRecord    `json:"user_record"`
+	RawUserInfo       string                 `json:"raw_user_info"`
+	SignInAttributes  map[string]interface{} `json:"sign_in_attributes"`
+	OAuthIDToken      string                 `json:"oauth_id_token"`
+	OAuthAccessToken  string                 `json:"oauth_access_token"`
+	OAuthRefreshToken string                 `json:"oauth_refresh_token"`
+	OAuthTokenSecret  string                 `json:"oauth_token_secret"`
+	OAuthExpiresIn    int64                  `json:"oauth_expires_in"`
+}
+
+type blockingUserRecord struct {
+	UID           string `json:"uid"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	DisplayName   string `json:"display_name"`
+	PhotoURL      string `json:"photo_url"`
+	PhoneNumber   string `json:"phone_number"`
+	Disabled      bool   `json:"disabled"`
+	Metadata      struct {
+		CreationTime   int64 `json:"creation_time"`
+		LastSignInTime int64 `json:"last_sign_in_time"`
+	} `json:"metadata"`
+	ProviderData []struct {
+		UID         string `json:"uid"`
+		DisplayName string `json:"display_name"`
+		Email       string `json:"email"`
+		PhotoURL    string `json:"photo_url"`
+		ProviderID  string `json:"provider_id"`
+		PhoneNumber string `json:"phone_number"`
+	} `json:"provider_data"`
+	CustomClaims map[string]interface{} `json:"custom_claims"`
+	TenantID     string                 `json:"tenant_id"`
+	MultiFactor  *struct {
+		EnrolledFactors []struct {
+			UID            string `json:"uid"`
+			DisplayName    string `json:"display_name"`
+			EnrollmentTime string `json:"enrollment_time"`
+			FactorID       string `json:"factor_id"`
+			PhoneNumber    string `json:"phone_number"`
+		} `json:"enrolled_factors"`
+	} `json:"multi_factor"`
+}
+
+func (p *blockingTokenPayload) toEvent(now int64) (*AuthBlockingEvent, error) {
+	user, err := p.UserRecord.toUserRecord()
+	if err != nil {
+		return nil, blockingTokenError("blocking token has invalid user record: %v", err)
+	}
+
+	// The email link sign-in method is reported as part of the password provider.
+	providerID := p.SignInMethod
+	if providerID == "emailLink" {
+		providerID = "password"
+	}
+
+	info := &BlockingAdditionalUserInfo{
+		ProviderID: providerID,
+		IsNewUser:  p.EventType == BlockingEventBeforeCreate,
+	}
+	if p.RawUserInfo != "" {
+		if err := json.Unmarshal([]byte(p.RawUserInfo), &info.Profile); err != nil {
+			return nil, blockingTokenError("blocking token has invalid raw user info: %v", err)
+		}
+		switch p.SignInMethod {
+		case "github.com":
+			info.Username, _ = info.Profile["login"].(string)
+		case "twitter.com":
+			info.Username, _ = info.Profile["screen_name"].(string)
+		}
+	}
+
+	var credential *BlockingCredential
+	if p.SignInAttributes != nil || p.OAuthIDToken != "" || p.OAuthAccessToken != "" || p.OAuthRefreshToken != "" {
+		credential = &BlockingCredential{
+			Claims:       p.SignInAttributes,
+			IDToken:      p.OAuthIDToken,
+			AccessToken:  p.OAuthAccessToken,
+			RefreshToken: p.OAuthRefreshToken,
+			Secret:       p.OAuthTokenSecret,
+			ProviderID:   providerID,
+			SignInMethod: p.SignInMethod,
+		}
+		if p.OAuthExpiresIn != 0 {
+			credential.ExpirationTime = now + p.OAuthExpiresIn
+		}
+	}
+
+	tenantID := p.TenantID
+	if tenantID == "" {
+		tenantID = user.TenantID
+	}
+	return &AuthBlockingEvent{
+		EventID:            p.EventID,
+		EventType:          p.EventType,
+		Audience:           p.Audience,
+		Timestamp:          p.IssuedAt,
+		IPAddress:          p.IPAddress,
+		UserAgent:          p.UserAgent,
+		Locale:             p.Locale,
+		TenantID:           tenantID,
+		User:               user,
+		AdditionalUserInfo: info,
+		Credential:         credential,
+	}, nil
+}
+
+func (r *blockingUserRecord) toUserRecord() (*UserRecord, error) {
+	var providers []*UserInfo
+	for _, p := range r.ProviderData {
+		providers = append(providers, &UserInfo{
+			UID:         p.UID,
+			DisplayName: p.DisplayName,
+			Email:       p.Email,
+			PhotoURL:    p.PhotoURL,
+			ProviderID:  p.ProviderID,
+			PhoneNumber: p.PhoneNumber,
+		})
+	}
+
+	var enrolledFactors []*MultiFactorInfo
+	if r.MultiFactor != nil {
+		for _, f := range r.MultiFactor.EnrolledFactors {
+			factor := &MultiFactorInfo{
+				UID:         f.UID,
+				DisplayName: f.DisplayName,
+				FactorID:    f.FactorID,
+			}
+			if f.EnrollmentTime != "" {
+				t, err := time.Parse(time.RFC3339, f.EnrollmentTime)
+				if err != nil {
+					return nil, err
+				}
+				factor.EnrollmentTimestamp = t.Unix() * 1000
+			}
+			switch f.FactorID {
+			case phoneMultiFactorID:
+				factor.PhoneNumber = f.PhoneNumber
+				factor.Phone = &PhoneMultiFactorInfo{PhoneNumber: f.PhoneNumber}
+			case totpMultiFactorID:
+				factor.TOTP = &TOTPMultiFactorInfo{}
+			}
+			enrolledFactors = append(enrolledFactors, factor)
+		}
+	}
+
+	customClaims := r.CustomClaims
+	if len(customClaims) == 0 {
+		customClaims = nil
+	}
+	return &UserRecord{
+		UserInfo: &UserInfo{
+			UID:         r.UID,
+			Email:       r.Email,
+			DisplayName: r.DisplayName,
+			PhotoURL:    r.PhotoURL,
+			PhoneNumber: r.PhoneNumber,
+			ProviderID:  defaultProviderID,
+		},
+		CustomClaims:     customClaims,
+		Disabled:         r.Disabled,
+		EmailVerified:    r.EmailVerified,
+		ProviderUserInfo: providers,
+		UserMetadata: &UserMetadata{
+			CreationTimestamp:  r.Metadata.CreationTime,
+			LastLogInTimestamp: r.Metadata.LastSignInTime,
+		},
+		TenantID: r.TenantID,
+		MultiFactor: &MultiFactorSettings{
+			EnrolledFactors: enrolledFactors,
+		},
+	}, nil
+}
+
+// BlockingResponse represents the updates a blocking function makes to the user that is being
+// created or signed in.
+//
+// Only the properties that are set are updated. Session claims can only be set in response to
+// BlockingEventBeforeSignIn events.
+type BlockingResponse struct {
+	params map[string]interface{}
+}
+
+// DisplayName sets the display name of the user.
+func (r *BlockingResponse) DisplayName(name string) *BlockingResponse {
+	return r.set("displayName", name)
+}
+
+// PhotoURL sets the photo URL of the user.
+func (r *BlockingResponse) PhotoURL(url string) *BlockingResponse {
+	return r.set("photoUrl", url)
+}
+
+// Disabled sets the disabled status of the user. Disabled users cannot sign in.
+func (r *BlockingResponse) Disabled(disabled bool) *BlockingResponse {
+	return r.set("disabled", disabled)
+}
+
+// EmailVerified sets the email verification status of the user.
+func (r *BlockingResponse) EmailVerified(verified bool) *BlockingResponse {
+	return r.set("emailVerified", verified)
+}
+
+// CustomClaims sets the custom claims of the user, which are persisted and included in all the ID
+// tokens issued to the user.
+func (r *BlockingResponse) CustomClaims(claims map[string]interface{}) *BlockingResponse {
+	return r.set("customClaims", claims)
+}
+
+// SessionClaims sets claims that are only included in the ID token issued for the current sign-in
+// session. Session claims take precedence over custom claims with the same name.
+func (r *BlockingResponse) SessionClaims(claims map[string]interface{}) *BlockingResponse {
+	return r.set("sessionClaims", claims)
+}
+
+func (r *BlockingResponse) set(key string, value interface{}) *BlockingResponse {
+	if r.params == nil {
+		r.params = make(map[string]interface{})
+	}
+	r.params[key] = value
+	return r
+}
+
+// Build validates the response for the given event type, and returns the JSON payload expected
+// by Identity Platform.
+func (r *BlockingResponse) Build(eventType string) ([]byte, error) {
+	if r == nil || len(r.params) == 0 {
+		return []byte("{}"), nil
+	}
+
+	record := make(map[string]interface{})
+	var mask []string
+	claimsLength := 0
+	for k, v := range r.params {
+		switch k {
+		case "customClaims", "sessionClaims":
+			if k == "sessionClaims" && eventType != BlockingEventBeforeSignIn {
+				return nil, fmt.Errorf("session claims can only be set for %q events", BlockingEventBeforeSignIn)
+			}
+			// Claims are sent as JSON objects, and are only serialized here to be validated.
+			claims := v.(map[string]interface{})
+			serialized, err := marshalCustomClaims(claims)
+			if err != nil {
+				return nil, err
+			}
+			claimsLength += len(serialized)
+			if claims == nil {
+				v = map[string]interface{}{}
+			}
+		case "displayName":
+			if err := validateDisplayName(v.(string)); err != nil {
+				return nil, err
+			}
+		case "photoUrl":
+			if err := validatePhotoURL(v.(string)); err != nil {
+				return nil, err
+			}
+		}
+		record[k] = v
+		mask = append(mask, k)
+	}
+	if claimsLength > maxLenPayloadCC {
+		return nil, fmt.Errorf(
+			"serialized custom and session claims must not exceed %d characters combined", maxLenPayloadCC)
+	}
+
+	sort.Strings(mask)
+	record["updateMask"] = strings.Join(mask, ",")
+	return json.Marshal(map[string]interface{}{"userRecord": record})
+}
+
+// BlockingError rejects the operation that triggered a blocking function. The Message is returned
+// to the client app that attempted to create or sign in the user.
+type BlockingError struct {
+	// Status is a canonical error status such as "PERMISSION_DENIED" or "INVALID_ARGUMENT".
+	// Defaults to "PERMISSION_DENIED".
+	Status  string
+	Message string
+}
+
+func (e *BlockingError) Error() string {
+	return e.Message
+}
+
+var blockingErrorStatusCodes = map[string]int{
+	"INVALID_ARGUMENT":    http.StatusBadRequest,
+	"FAILED_PRECONDITION": http.StatusBadRequest,
+	"OUT_OF_RANGE":        http.StatusBadRequest,
+	"UNAUTHENTICATED":     http.StatusUnauthorized,
+	"PERMISSION_DENIED":   http.StatusForbidden,
+	"NOT_FOUND":           http.StatusNotFound,
+	"ALREADY_EXISTS":      http.StatusConflict,
+	"ABORTED":             http.StatusConflict,
+	"RESOURCE_EXHAUSTED":  http.StatusTooManyRequests,
+	"CANCELLED":           499,
+	"UNIMPLEMENTED":       http.StatusNotImplemented,
+	"UNAVAILABLE":         http.StatusServiceUnavailable,
+	"DEADLINE_EXCEEDED":   http.StatusGatewayTimeout,
+}
+
+// BlockingFunc handles a verified blocking function event. It returns the updates to make to the
+// user, or nil to allow the operation without changes. A *BlockingError rejects the operation with
+// the given message; any other error rejects it with an internal error.
+type BlockingFunc func(ctx context.Context, event *AuthBlockingEvent) (*BlockingResponse, error)
+
+// BlockingHandler returns an http.Handler that serves a blocking function endpoint.
+//
+// The handler verifies the token in each request with VerifyBlockingEventToken using the given
+// options, calls fn with the decoded event, and writes the response in the format expected by
+// Identity Platform.
+//
+// Unless WithAudience is specified, the expected audience is the URL of the incoming request,
+// made of the scheme (taken from the X-Forwarded-Proto header when present), the host and the
+// path. Specify WithAudience when the handler is served behind a proxy that rewrites the host
+// or the path of requests.
+func (c *Client) BlockingHandler(fn BlockingFunc, opts ...BlockingEventOption) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyOpts := append([]BlockingEventOption{WithAudience(blockingAudience(r))}, opts...)
+		if r.Method != http.MethodPost {
+			writeBlockingError(w, &BlockingError{Status: "INVALID_ARGUMENT", Message: "bad request"})
+			return
+		}
+
+		var req struct {
+			Data struct {
+				JWT string `json:"jwt"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBlockingError(w, &BlockingError{Status: "INVALID_ARGUMENT", Message: "bad request"})
+			return
+		}
+
+		ctx := r.Context()
+		event, err := c.VerifyBlockingEventToken(ctx, req.Data.JWT, verifyOpts...)
+		if err != nil {
+			writeBlockingError(w, &BlockingError{Status: "INVALID_ARGUMENT", Message: "bad request"})
+			return
+		}
+
+		resp, err := fn(ctx, event)
+		if err != nil {
+			be, ok := err.(*BlockingError)
+			if !ok {
+				be = &BlockingError{Status: "INTERNAL", Message: "internal error"}
+			}
+			writeBlockingError(w, be)
+			return
+		}
+
+		b, err := resp.Build(event.EventType)
+		if err != nil {
+			writeBlockingError(w, &BlockingError{Status: "INTERNAL", Message: "internal error"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	})
+}
+
+// blockingAudience returns the URL the given request was sent to.
+func blockingAudience(r *http.Request) string {
+	scheme := "https"
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	} else if r.TLS == nil {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.Path)
+}
+
+func writeBlockingError(w http.ResponseWriter, be *BlockingError) {
+	status := be.Status
+	if status == "" {
+		status = "PERMISSION_DENIED"
+	}
+	code, ok := blockingErrorStatusCodes[status]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+
+	b, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]string{
+			"status":  status,
+			"message": be.Message,
+		},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
@@ -0,0 +1,437 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testBlockingAudience = "https://us-central1-mock-project-id.cloudfunctions.net/beforeSignIn"
+
+func getBlockingToken(p mockIDTokenPayload) string {
+	pCopy := mockIDTokenPayload{
+		"aud":                testBlockingAudience,
+		"event_id":           "event-id",
+		"event_type":         BlockingEventBeforeSignIn,
+		"ip_address":         "1.2.3.4",
+		"user_agent":         "Mozilla/5.0",
+		"locale":             "en",
+		"sign_in_method":     "github.com",
+		"raw_user_info":      `{"login": "octocat", "id": 1}`,
+		"oauth_access_token": "access-token",
+		"oauth_expires_in":   3600,
+		"user_record": map[string]interface{}{
+			"uid":            "user1",
+			"email":          "user1@example.com",
+			"email_verified": true,
+			"display_name":   "User One",
+			"metadata": map[string]interface{}{
+				"creation_time":     1234567890000,
+				"last_sign_in_time": 1233211232000,
+			},
+			"provider_data": []interface{}{
+				map[string]interface{}{
+					"uid":         "github-uid",
+					"provider_id": "github.com",
+					"email":       "user1@example.com",
+				},
+			},
+			"custom_claims": map[string]interface{}{"admin": true},
+			"multi_factor": map[string]interface{}{
+				"enrolled_factors": []interface{}{
+					map[string]interface{}{
+						"uid":             "factor1",
+						"factor_id":       "phone",
+						"phone_number":    "+11234567890",
+						"enrollment_time": "2021-03-03T13:06:20Z",
+					},
+				},
+			},
+		},
+	}
+	for k, v := range p {
+		pCopy[k] = v
+	}
+	return getIDToken(pCopy)
+}
+
+func blockingClient() *Client {
+	return &Client{
+		baseClient: &baseClient{
+			idTokenVerifier: testIDTokenVerifier,
+		},
+	}
+}
+
+func TestVerifyBlockingEventToken(t *testing.T) {
+	client := blockingClient()
+	event, err := client.VerifyBlockingEventToken(
+		context.Background(), getBlockingToken(nil), WithAudience(testBlockingAudience))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := testClock.Now().Unix()
+	want := &AuthBlockingEvent{
+		EventID:   "event-id",
+		EventType: BlockingEventBeforeSignIn,
+		Audience:  testBlockingAudience,
+		Timestamp: now - 100,
+		IPAddress: "1.2.3.4",
+		UserAgent: "Mozilla/5.0",
+		Locale:    "en",
+		User: &UserRecord{
+			UserInfo: &UserInfo{
+				UID:         "user1",
+				Email:       "user1@example.com",
+				DisplayName: "User One",
+				ProviderID:  defaultProviderID,
+			},
+			CustomClaims:  map[string]interface{}{"admin": true},
+			EmailVerified: true,
+			ProviderUserInfo: []*UserInfo{
+				{UID: "github-uid", ProviderID: "github.com", Email: "user1@example.com"},
+			},
+			UserMetadata: &UserMetadata{
+				CreationTimestamp:  1234567890000,
+				LastLogInTimestamp: 1233211232000,
+			},
+			MultiFactor: &MultiFactorSettings{
+				EnrolledFactors: []*MultiFactorInfo{
+					{
+						UID:                 "factor1",
+						FactorID:            "phone",
+						PhoneNumber:         "+11234567890",
+						Phone:               &PhoneMultiFactorInfo{PhoneNumber: "+11234567890"},
+						EnrollmentTimestamp: 1614776780000,
+					},
+				},
+			},
+		},
+		AdditionalUserInfo: &BlockingAdditionalUserInfo{
+			ProviderID: "github.com",
+			Profile:    map[string]interface{}{"login": "octocat", "id": float64(1)},
+			Username:   "octocat",
+		},
+		Credential: &BlockingCredential{
+			AccessToken:    "access-token",
+			ExpirationTime: now + 3600,
+			ProviderID:     "github.com",
+			SignInMethod:   "github.com",
+		},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("VerifyBlockingEventToken() = %#v; want = %#v", event, want)
+	}
+}
+
+func TestVerifyBlockingEventTokenBeforeCreate(t *testing.T) {
+	token := getBlockingToken(mockIDTokenPayload{
+		"event_type":         BlockingEventBeforeCreate,
+		"sign_in_method":     "emailLink",
+		"raw_user_info":      "",
+		"oauth_access_token": "",
+		"tenant_id":          "tenant1",
+	})
+	event, err := blockingClient().VerifyBlockingEventToken(context.Background(), token,
+		WithAudience(testBlockingAudience), WithEventTypes(BlockingEventBeforeCreate))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantInfo := &BlockingAdditionalUserInfo{ProviderID: "password", IsNewUser: true}
+	if !reflect.DeepEqual(event.AdditionalUserInfo, wantInfo) {
+		t.Errorf("AdditionalUserInfo = %#v; want = %#v", event.AdditionalUserInfo, wantInfo)
+	}
+	if event.Credential != nil {
+		t.Errorf("Credential = %#v; want = nil", event.Credential)
+	}
+	if event.TenantID != "tenant1" {
+		t.Errorf("TenantID = %q; want = %q", event.TenantID, "tenant1")
+	}
+}
+
+func TestVerifyBlockingEventTokenError(t *testing.T) {
+	now := testClock.Now().Unix()
+	cases := []struct {
+		name  string
+		token string
+		opts  []BlockingEventOption
+	}{
+		{"Empty", "", nil},
+		{"Malformed", "not.a.token", nil},
+		{"NoKid", getIDTokenWithKid("", mockIDTokenPayload{"event_type": BlockingEventBeforeSignIn}), nil},
+		{"WrongIssuer", getBlockingToken(mockIDTokenPayload{"iss": "https://example.com"}), nil},
+		{"NoAudience", getBlockingToken(mockIDTokenPayload{"aud": ""}), nil},
+		{"WrongAudience", getBlockingToken(nil), []BlockingEventOption{WithAudience("https://example.com")}},
+		{
+			"OtherFunctionAudience",
+			getBlockingToken(mockIDTokenPayload{"aud": "https://us-central1-mock-project-id.cloudfunctions.net/other"}),
+			nil,
+		},
+		{"UnknownEventType", getBlockingToken(mockIDTokenPayload{"event_type": "beforeDelete"}), nil},
+		{
+			"UnexpectedEventType",
+			getBlockingToken(nil),
+			[]BlockingEventOption{WithEventTypes(BlockingEventBeforeCreate)},
+		},
+		{"NoUserRecord", getBlockingToken(mockIDTokenPayload{"user_record": nil}), nil},
+		{"Expired", getBlockingToken(mockIDTokenPayload{"exp": now - clockSkewSeconds - 1}), nil},
+		{"FutureIssuedAt", getBlockingToken(mockIDTokenPayload{"iat": now + clockSkewSeconds + 1}), nil},
+		{"InvalidSignature", getBlockingToken(nil) + "x", nil},
+		{"InvalidRawUserInfo", getBlockingToken(mockIDTokenPayload{"raw_user_info": "{"}), nil},
+	}
+	client := blockingClient()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]BlockingEventOption{WithAudience(testBlockingAudience)}, tc.opts...)
+			event, err := client.VerifyBlockingEventToken(context.Background(), tc.token, opts...)
+			if event != nil || !IsBlockingTokenInvalid(err) {
+				t.Errorf("VerifyBlockingEventToken() = (%v, %v); want = (nil, BlockingTokenInvalid)", event, err)
+			}
+		})
+	}
+}
+
+func TestVerifyBlockingEventTokenNoAudience(t *testing.T) {
+	client := blockingClient()
+	for _, opts := range [][]BlockingEventOption{nil, {WithAudience("")}} {
+		event, err := client.VerifyBlockingEventToken(context.Background(), getBlockingToken(nil), opts...)
+		want := "audience must be specified with WithAudience"
+		if event != nil || err == nil || err.Error() != want {
+			t.Errorf("VerifyBlockingEventToken() = (%v, %v); want = (nil, %q)", event, err, want)
+		}
+	}
+}
+
+func TestVerifyBlockingEventTokenEmulator(t *testing.T) {
+	client := blockingClient()
+	client.isEmulator = true
+	token := getEmulatedIDToken(mockIDTokenPayload{
+		"aud":         testBlockingAudience,
+		"event_type":  BlockingEventBeforeCreate,
+		"user_record": map[string]interface{}{"uid": "user1"},
+	})
+	event, err := client.VerifyBlockingEventToken(context.Background(), token, WithAudience(testBlockingAudience))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.User.UID != "user1" {
+		t.Errorf("User.UID = %q; want = %q", event.User.UID, "user1")
+	}
+}
+
+func TestBlockingHandlerAudience(t *testing.T) {
+	client := blockingClient()
+	fn := func(ctx context.Context, event *AuthBlockingEvent) (*BlockingResponse, error) {
+		return nil, nil
+	}
+	body := `{"data": {"jwt": "` + getBlockingToken(nil) + `"}}`
+	cases := []struct {
+		name   string
+		url    string
+		header string
+		opts   []BlockingEventOption
+		status int
+	}{
+		{"RequestURL", testBlockingAudience, "", nil, http.StatusOK},
+		{"OtherPath", "https://us-central1-mock-project-id.cloudfunctions.net/beforeCreate", "", nil, http.StatusBadRequest},
+		{"OtherHost", "https://example.com/beforeSignIn", "", nil, http.StatusBadRequest},
+		{"PlainHTTP", "http://us-central1-mock-project-id.cloudfunctions.net/beforeSignIn", "", nil, http.StatusBadRequest},
+		{"ForwardedProto", "http://us-central1-mock-project-id.cloudfunctions.net/beforeSignIn", "https", nil, http.StatusOK},
+		{
+			"WithAudience",
+			"http://localhost:8080/beforeSignIn",
+			"",
+			[]BlockingEventOption{WithAudience(testBlockingAudience)},
+			http.StatusOK,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(body))
+			if tc.header != "" {
+				r.Header.Set("X-Forwarded-Proto", tc.header)
+			}
+			w := httptest.NewRecorder()
+			client.BlockingHandler(fn, tc.opts...).ServeHTTP(w, r)
+			if w.Code != tc.status {
+				t.Errorf("ServeHTTP(%s) = %d; want = %d", tc.url, w.Code, tc.status)
+			}
+		})
+	}
+}
+
+func TestBlockingResponseBuild(t *testing.T) {
+	resp := (&BlockingResponse{}).
+		DisplayName("New Name").
+		Disabled(false).
+		CustomClaims(map[string]interface{}{"role": "admin"}).
+		SessionClaims(map[string]interface{}{"ip": "1.2.3.4"})
+	b, err := resp.Build(BlockingEventBeforeSignIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"userRecord": map[string]interface{}{
+			"updateMask":    "customClaims,disabled,displayName,sessionClaims",
+			"displayName":   "New Name",
+			"disabled":      false,
+			"customClaims":  map[string]interface{}{"role": "admin"},
+			"sessionClaims": map[string]interface{}{"ip": "1.2.3.4"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build() = %v; want = %v", got, want)
+	}
+
+	remove := (&BlockingResponse{}).CustomClaims(nil)
+	want2 := `{"userRecord":{"customClaims":{},"updateMask":"customClaims"}}`
+	if b, err := remove.Build(BlockingEventBeforeCreate); string(b) != want2 || err != nil {
+		t.Errorf("Build() = (%s, %v); want = (%s, nil)", string(b), err, want2)
+	}
+
+	var empty *BlockingResponse
+	if b, err := empty.Build(BlockingEventBeforeCreate); string(b) != "{}" || err != nil {
+		t.Errorf("Build() = (%s, %v); want = ({}, nil)", string(b), err)
+	}
+}
+
+func TestBlockingResponseBuildError(t *testing.T) {
+	cases := []struct {
+		name      string
+		resp      *BlockingResponse
+		eventType string
+		want      string
+	}{
+		{
+			"SessionClaimsBeforeCreate",
+			(&BlockingResponse{}).SessionClaims(map[string]interface{}{"a": "b"}),
+			BlockingEventBeforeCreate,
+			`session claims can only be set for "beforeSignIn" events`,
+		},
+		{
+			"ReservedClaim",
+			(&BlockingResponse{}).CustomClaims(map[string]interface{}{"sub": "b"}),
+			BlockingEventBeforeCreate,
+			`claim "sub" is reserved and must not be set`,
+		},
+		{
+			"ClaimsTooLarge",
+			(&BlockingResponse{}).
+				CustomClaims(map[string]interface{}{"a": strings.Repeat("a", 600)}).
+				SessionClaims(map[string]interface{}{"b": strings.Repeat("b", 600)}),
+			BlockingEventBeforeSignIn,
+			"serialized custom and session claims must not exceed 1000 characters combined",
+		},
+		{
+			"EmptyDisplayName",
+			(&BlockingResponse{}).DisplayName(""),
+			BlockingEventBeforeCreate,
+			"display name must be a non-empty string",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.resp.Build(tc.eventType); err == nil || err.Error() != tc.want {
+				t.Errorf("Build() = %v; want = %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestBlockingHandler(t *testing.T) {
+	handler := blockingClient().BlockingHandler(
+		func(ctx context.Context, event *AuthBlockingEvent) (*BlockingResponse, error) {
+			if event.User.Email == "blocked@example.com" {
+				return nil, &BlockingError{Message: "user is blocked"}
+			}
+			if event.User.Email == "error@example.com" {
+				return nil, errors.New("database unavailable")
+			}
+			return (&BlockingResponse{}).SessionClaims(map[string]interface{}{"ip": event.IPAddress}), nil
+		})
+
+	post := func(token string) *httptest.ResponseRecorder {
+		body := `{"data": {"jwt": "` + token + `"}}`
+		r := httptest.NewRequest(http.MethodPost, testBlockingAudience, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	userWithEmail := func(email string) mockIDTokenPayload {
+		return mockIDTokenPayload{
+			"user_record": map[string]interface{}{"uid": "user1", "email": email},
+		}
+	}
+
+	cases := []struct {
+		name   string
+		token  string
+		status int
+		body   string
+	}{
+		{
+			"Allowed",
+			getBlockingToken(nil),
+			http.StatusOK,
+			`{"userRecord":{"sessionClaims":{"ip":"1.2.3.4"},"updateMask":"sessionClaims"}}`,
+		},
+		{
+			"Blocked",
+			getBlockingToken(userWithEmail("blocked@example.com")),
+			http.StatusForbidden,
+			`{"error":{"message":"user is blocked","status":"PERMISSION_DENIED"}}`,
+		},
+		{
+			"InternalError",
+			getBlockingToken(userWithEmail("error@example.com")),
+			http.StatusInternalServerError,
+			`{"error":{"message":"internal error","status":"INTERNAL"}}`,
+		},
+		{
+			"InvalidToken",
+			"invalid",
+			http.StatusBadRequest,
+			`{"error":{"message":"bad request","status":"INVALID_ARGUMENT"}}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := post(tc.token)
+			if w.Code != tc.status || w.Body.String() != tc.body {
+				t.Errorf("ServeHTTP() = (%d, %s); want = (%d, %s)", w.Code, w.Body.String(), tc.status, tc.body)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/beforeSignIn", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("ServeHTTP(GET) = %d; want = %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,658 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"firebase.google.com/go/v4/internal"
+)
+
+const (
+	defaultBulkMaxRetries = 5
+	defaultBulkRetryDelay = time.Second
+	maxBulkRetryDelay     = time.Minute
+)
+
+// BulkRunnerOptions specifies how a BulkRunner executes batches.
+type BulkRunnerOptions struct {
+	// Concurrency is the maximum number of batches in flight at any given time. Defaults to 1.
+	Concurrency int
+
+	// BatchSize is the maximum number of entries sent in a single request. Defaults to, and is
+	// capped at, the limit of the underlying API (100 for GetUsers, and 1000 for DeleteUsers and
+	// ImportUsers). Operations that update users one at a time, such as DisableUsers, always use
+	// batches of one user.
+	BatchSize int
+
+	// RequestsPerSecond limits the rate at which batches are sent, using a token bucket that
+	// holds up to Burst tokens. Zero means no rate limit.
+	RequestsPerSecond float64
+
+	// Burst is the maximum number of batches that can be sent at once when RequestsPerSecond is
+	// set. Defaults to 1.
+	Burst int
+
+	// MaxRetries is the maximum number of times a batch is retried after failing with a quota
+	// error. Defaults to 5. Set to a negative value to disable retries.
+	MaxRetries int
+
+	// RetryDelay is the delay before the first retry of a batch. The delay is doubled on each
+	// subsequent retry, up to a maximum of 1 minute. Defaults to 1 second.
+	RetryDelay time.Duration
+
+	// Checkpoint is an optional store where the progress of the run is recorded. When set, the
+	// run resumes from the last saved checkpoint by skipping the entries that were already
+	// processed.
+	Checkpoint BulkCheckpointStore
+}
+
+// BulkCheckpoint records the progress of a BulkRunner operation.
+type BulkCheckpoint struct {
+	// Processed is the number of entries at the start of the input that have been processed,
+	// whether they succeeded or not. A resumed run skips these entries, including the failed ones.
+	// Batches may complete out of order when running concurrently, so entries past this point
+	// may also have been processed.
+	Processed int `json:"processed"`
+
+	// Failed is the positions in the input of the processed entries that failed, in ascending
+	// order. They are not retried when the run is resumed, and are carried over to the checkpoints
+	// saved by the resumed run.
+	Failed []int `json:"failed,omitempty"`
+}
+
+// BulkCheckpointStore persists BulkCheckpoints, so that an interrupted BulkRunner operation can
+// be resumed.
+type BulkCheckpointStore interface {
+	// Load returns the last saved checkpoint, or nil if no checkpoint has been saved.
+	Load(ctx context.Context) (*BulkCheckpoint, error)
+	// Save records the given checkpoint.
+	Save(ctx context.Context, cp *BulkCheckpoint) error
+}
+
+// FileCheckpointStore returns a BulkCheckpointStore that saves checkpoints as JSON to the file at
+// the given path. The file is replaced atomically on each save.
+func FileCheckpointStore(path string) BulkCheckpointStore {
+	return fileCheckpointStore(path)
+}
+
+type fileCheckpointStore string
+
+func (f fileCheckpointStore) Load(ctx context.Context) (*BulkCheckpoint, error) {
+	b, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var cp BulkCheckpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file %q: %v", string(f), err)
+	}
+	return &cp, nil
+}
+
+func (f fileCheckpointStore) Save(ctx context.Context, cp *BulkCheckpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(string(f)), filepath.Base(string(f))+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// BulkRunner executes user management operations over an unbounded stream of entries.
+//
+// The GetUsers, DeleteUsers and ImportUsers APIs limit the number of entries accepted in a
+// single call. BulkRunner reads entries from a channel, splits them into batches that satisfy
+// these limits, and sends the batches with bounded concurrency and an optional rate limit.
+// Batches that fail with a quota error are retried with exponential backoff. Any other error
+// stops the run; the entries already read from the channel are discarded, and no more entries
+// are read. When a run stops with an error, the result of the batches that completed before the
+// error is returned along with the error.
+type BulkRunner struct {
+	client *baseClient
+	opts   BulkRunnerOptions
+}
+
+// NewBulkRunner creates a new BulkRunner with the given options. Options may be nil.
+func (c *baseClient) NewBulkRunner(opts *BulkRunnerOptions) *BulkRunner {
+	r := &BulkRunner{client: c}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Concurrency <= 0 {
+		r.opts.Concurrency = 1
+	}
+	if r.opts.Burst <= 0 {
+		r.opts.Burst = 1
+	}
+	if r.opts.MaxRetries == 0 {
+		r.opts.MaxRetries = defaultBulkMaxRetries
+	}
+	if r.opts.RetryDelay <= 0 {
+		r.opts.RetryDelay = defaultBulkRetryDelay
+	}
+	return r
+}
+
+// GetUsers looks up the users corresponding to all the identifiers received from the given
+// channel, until the channel is closed.
+//
+// When resuming from a checkpoint, the returned result only contains the users looked up
+// during the current run.
+func (r *BulkRunner) GetUsers(ctx context.Context, identifiers <-chan UserIdentifier) (*GetUsersResult, error) {
+	var mutex sync.Mutex
+	result := &GetUsersResult{}
+	next := func(ctx context.Context) (interface{}, bool) {
+		select {
+		case v, ok := <-identifiers:
+			return v, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	run := func(ctx context.Context, offset int, batch []interface{}) ([]int, error) {
+		ids := make([]UserIdentifier, len(batch))
+		for i, b := range batch {
+			ids[i] = b.(UserIdentifier)
+		}
+		resp, err := r.client.GetUsers(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		result.Users = append(result.Users, resp.Users...)
+		result.NotFound = append(result.NotFound, resp.NotFound...)
+		return nil, nil
+	}
+
+	err := r.run(ctx, maxGetAccountsBatchSize, next, run)
+	return result, err
+}
+
+// DeleteUsers deletes the users corresponding to all the uids received from the given channel,
+// until the channel is closed.
+//
+// The Index of each DeleteUsersErrorInfo in the result is the position of the failed uid in the
+// input stream. When resuming from a checkpoint, the result only accounts for the users
+// deleted during the current run.
+func (r *BulkRunner) DeleteUsers(ctx context.Context, uids <-chan string) (*DeleteUsersResult, error) {
+	var mutex sync.Mutex
+	result := &DeleteUsersResult{}
+	next := func(ctx context.Context) (interface{}, bool) {
+		select {
+		case v, ok := <-uids:
+			return v, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	run := func(ctx context.Context, offset int, batch []interface{}) ([]int, error) {
+		ids := make([]string, len(batch))
+		for i, b := range batch {
+			ids[i] = b.(string)
+		}
+		resp, err := r.client.DeleteUsers(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		result.SuccessCount += resp.SuccessCount
+		result.FailureCount += resp.FailureCount
+		var failed []int
+		for _, e := range resp.Errors {
+			result.Errors = append(result.Errors, &DeleteUsersErrorInfo{
+				Index:  offset + e.Index,
+				Reason: e.Reason,
+			})
+			failed = append(failed, offset+e.Index)
+		}
+		return failed, nil
+	}
+
+	err := r.run(ctx, maxDeleteAccountsBatchSize, next, run)
+	return result, err
+}
+
+// ImportUsers imports all the users received from the given channel, until the channel is
+// closed. The given options are applied to every batch.
+//
+// The Index of each ErrorInfo in the result is the position of the failed user in the input
+// stream. When resuming from a checkpoint, the result only accounts for the users imported
+// during the current run.
+func (r *BulkRunner) ImportUsers(
+	ctx context.Context, users <-chan *UserToImport, opts ...UserImportOption) (*UserImportResult, error) {
+
+	var mutex sync.Mutex
+	result := &UserImportResult{}
+	next := func(ctx context.Context) (interface{}, bool) {
+		select {
+		case v, ok := <-users:
+			return v, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	run := func(ctx context.Context, offset int, batch []interface{}) ([]int, error) {
+		us := make([]*UserToImport, len(batch))
+		for i, b := range batch {
+			us[i] = b.(*UserToImport)
+		}
+		resp, err := r.client.ImportUsers(ctx, us, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		result.SuccessCount += resp.SuccessCount
+		result.FailureCount += resp.FailureCount
+		var failed []int
+		for _, e := range resp.Errors {
+			result.Errors = append(result.Errors, &ErrorInfo{
+				Index:  offset + e.Index,
+				Reason: e.Reason,
+			})
+			failed = append(failed, offset+e.Index)
+		}
+		return failed, nil
+	}
+
+	err := r.run(ctx, maxImportUsers, next, run)
+	return result, err
+}
+
+// UpdateUsersResult is the result of updating a stream of user accounts with BulkRunner.
+type UpdateUsersResult struct {
+	// The number of users that were updated successfully (possibly zero).
+	SuccessCount int
+
+	// The number of users that failed to be updated (possibly zero).
+	FailureCount int
+
+	// A list of UpdateUsersErrorInfo instances describing the errors that were encountered, in
+	// the order of the input stream. Length of this list is equal to the value of FailureCount.
+	Errors []*UpdateUsersErrorInfo
+}
+
+// UpdateUsersErrorInfo describes a user that failed to be updated by BulkRunner.
+type UpdateUsersErrorInfo struct {
+	// The position of the uid in the input stream.
+	Index  int
+	UID    string
+	Reason string
+}
+
+// RevokeRefreshTokens revokes all the refresh tokens of the users corresponding to all the uids
+// received from the given channel, until the channel is closed.
+//
+// Users are updated one at a time, so Concurrency is the number of users updated in parallel. A
+// failure to update a user, including a non-existing user, is recorded in the result and does not
+// stop the run. When resuming from a checkpoint, the result only accounts for the users updated
+// during the current run.
+func (r *BulkRunner) RevokeRefreshTokens(ctx context.Context, uids <-chan string) (*UpdateUsersResult, error) {
+	return r.updateUsers(ctx, uids, func() *UserToUpdate {
+		return (&UserToUpdate{}).revokeRefreshTokens()
+	})
+}
+
+// DisableUsers disables the users corresponding to all the uids received from the given channel,
+// until the channel is closed.
+//
+// Failures are handled in the same way as in RevokeRefreshTokens.
+func (r *BulkRunner) DisableUsers(ctx context.Context, uids <-chan string) (*UpdateUsersResult, error) {
+	return r.updateUsers(ctx, uids, func() *UserToUpdate {
+		return (&UserToUpdate{}).Disabled(true)
+	})
+}
+
+// EnableUsers enables the users corresponding to all the uids received from the given channel,
+// until the channel is closed.
+//
+// Failures are handled in the same way as in RevokeRefreshTokens.
+func (r *BulkRunner) EnableUsers(ctx context.Context, uids <-chan string) (*UpdateUsersResult, error) {
+	return r.updateUsers(ctx, uids, func() *UserToUpdate {
+		return (&UserToUpdate{}).Disabled(false)
+	})
+}
+
+func (r *BulkRunner) updateUsers(
+	ctx context.Context, uids <-chan string, update func() *UserToUpdate) (*UpdateUsersResult, error) {
+
+	var mutex sync.Mutex
+	result := &UpdateUsersResult{}
+	next := func(ctx context.Context) (interface{}, bool) {
+		select {
+		case v, ok := <-uids:
+			return v, ok
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+	run := func(ctx context.Context, offset int, batch []interface{}) ([]int, error) {
+		uid := batch[0].(string)
+		err := r.client.updateUser(ctx, uid, update())
+		if err != nil && (isRetryableBulkError(err) || ctx.Err() != nil) {
+			return nil, err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err != nil {
+			result.FailureCount++
+			result.Errors = append(result.Errors, &UpdateUsersErrorInfo{
+				Index:  offset,
+				UID:    uid,
+				Reason: err.Error(),
+			})
+			return []int{offset}, nil
+		}
+		result.SuccessCount++
+		return nil, nil
+	}
+
+	err := r.run(ctx, 1, next, run)
+	sort.Slice(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Index < result.Errors[j].Index
+	})
+	return result, err
+}
+
+type bulkBatch struct {
+	offset  int
+	entries []interface{}
+	// The positions in the input of the entries that failed, set once the batch has completed.
+	failed []int
+}
+
+func (r *BulkRunner) run(
+	ctx context.Context,
+	maxBatchSize int,
+	next func(context.Context) (interface{}, bool),
+	do func(context.Context, int, []interface{}) ([]int, error),
+) error {
+	batchSize := maxBatchSize
+	if r.opts.BatchSize > 0 && r.opts.BatchSize < maxBatchSize {
+		batchSize = r.opts.BatchSize
+	}
+
+	progress, err := r.loadProgress(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	limiter := newTokenBucket(r.opts.RequestsPerSecond, r.opts.Burst)
+	batches := make(chan *bulkBatch)
+	var wg sync.WaitGroup
+	for i := 0; i < r.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				failed, err := r.runBatch(ctx, limiter, b, do)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				b.failed = failed
+				if err := progress.done(ctx, b); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	offset := 0
+	current := &bulkBatch{}
+	for {
+		entry, ok := next(ctx)
+		if !ok {
+			break
+		}
+		if offset < progress.skip {
+			offset++
+			continue
+		}
+		if len(current.entries) == 0 {
+			current.offset = offset
+		}
+		current.entries = append(current.entries, entry)
+		offset++
+		if len(current.entries) == batchSize {
+			if !send(ctx, batches, current) {
+				break
+			}
+			current = &bulkBatch{}
+		}
+	}
+	if len(current.entries) > 0 {
+		send(ctx, batches, current)
+	}
+	close(batches)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func send(ctx context.Context, batches chan<- *bulkBatch, b *bulkBatch) bool {
+	select {
+	case batches <- b:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (r *BulkRunner) runBatch(
+	ctx context.Context,
+	limiter *tokenBucket,
+	b *bulkBatch,
+	do func(context.Context, int, []interface{}) ([]int, error),
+) ([]int, error) {
+	delay := r.opts.RetryDelay
+	for retries := 0; ; retries++ {
+		if err := limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		failed, err := do(ctx, b.offset, b.entries)
+		if err == nil || !isRetryableBulkError(err) {
+			return failed, err
+		}
+		if retries >= r.opts.MaxRetries {
+			return nil, err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
+		if delay > maxBulkRetryDelay {
+			delay = maxBulkRetryDelay
+		}
+	}
+}
+
+func isRetryableBulkError(err error) bool {
+	return IsQuotaExceeded(err) || internal.HasPlatformErrorCode(err, internal.ResourceExhausted)
+}
+
+func (r *BulkRunner) loadProgress(ctx context.Context) (*bulkProgress, error) {
+	p := &bulkProgress{
+		store:     r.opts.Checkpoint,
+		completed: make(map[int]*bulkBatch),
+	}
+	if p.store == nil {
+		return p, nil
+	}
+
+	cp, err := p.store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cp != nil {
+		if cp.Processed < 0 {
+			return nil, errors.New("checkpoint must not have a negative processed count")
+		}
+		p.skip = cp.Processed
+		p.watermark = cp.Processed
+		p.failed = cp.Failed
+	}
+	return p, nil
+}
+
+// bulkProgress tracks the batches that have completed, and saves the length of the longest
+// fully processed prefix of the input, along with the entries of that prefix that failed, as a
+// checkpoint.
+type bulkProgress struct {
+	store     BulkCheckpointStore
+	skip      int
+	mutex     sync.Mutex
+	completed map[int]*bulkBatch
+	watermark int
+	failed    []int
+}
+
+func (p *bulkProgress) done(ctx context.Context, b *bulkBatch) error {
+	if p.store == nil {
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.completed[b.offset] = b
+	advanced := false
+	for {
+		c, ok := p.completed[p.watermark]
+		if !ok {
+			break
+		}
+		delete(p.completed, p.watermark)
+		p.watermark += len(c.entries)
+		p.failed = append(p.failed, c.failed...)
+		advanced = true
+	}
+	if !advanced {
+		return nil
+	}
+	sort.Ints(p.failed)
+	return p.store.Save(ctx, &BulkCheckpoint{
+		Processed: p.watermark,
+		Failed:    append([]int(nil), p.failed...),
+	})
+}
+
+// tokenBucket is a simple token bucket rate limiter. A nil tokenBucket imposes no limit.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	mutex  sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (tb *tokenBucket) wait(ctx context.Context) error {
+	if tb == nil {
+		return ctx.Err()
+	}
+	for {
+		delay := tb.reserve()
+		if delay == 0 {
+			return nil
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token from the bucket if one is available, and otherwise returns how long to
+// wait until the next token becomes available.
+func (tb *tokenBucket) reserve() time.Duration {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	now := time.Now()
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+	if tb.tokens >= 1 {
+		tb.tokens--
+		return 0
+	}
+	return time.Duration((1 - tb.tokens) / tb.rate * float64(time.Second))
+}
@@ -0,0 +1,494 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkRunnerDeleteUsers(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][]string
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		var ids []string
+		for _, id := range body["localIds"].([]interface{}) {
+			ids = append(ids, id.(string))
+		}
+		mutex.Lock()
+		batches = append(batches, ids)
+		mutex.Unlock()
+		w.Write([]byte(`{"errors": [{"index": 1, "message": "NOT_DISABLED"}]}`))
+	})
+	defer s.Close()
+
+	runner := s.Client.NewBulkRunner(&BulkRunnerOptions{
+		Concurrency: 3,
+		BatchSize:   10,
+	})
+	result, err := runner.DeleteUsers(context.Background(), uidStream(25))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batches) != 3 {
+		t.Fatalf("Requests = %d; want = 3", len(batches))
+	}
+	var sizes []int
+	for _, b := range batches {
+		sizes = append(sizes, len(b))
+	}
+	sort.Ints(sizes)
+	if sizes[0] != 5 || sizes[1] != 10 || sizes[2] != 10 {
+		t.Errorf("Batch sizes = %v; want = [5 10 10]", sizes)
+	}
+
+	if result.SuccessCount != 22 || result.FailureCount != 3 || len(result.Errors) != 3 {
+		t.Errorf("DeleteUsers() = %+v; want = {22, 3, 3 errors}", result)
+	}
+	var indices []int
+	for _, e := range result.Errors {
+		indices = append(indices, e.Index)
+		if e.Reason != "NOT_DISABLED" {
+			t.Errorf("Reason = %q; want = %q", e.Reason, "NOT_DISABLED")
+		}
+	}
+	sort.Ints(indices)
+	if indices[0] != 1 || indices[1] != 11 || indices[2] != 21 {
+		t.Errorf("Error indices = %v; want = [1 11 21]", indices)
+	}
+}
+
+func TestBulkRunnerImportUsers(t *testing.T) {
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		w.Write([]byte(`{"error": [{"index": 0, "message": "Invalid email"}]}`))
+	})
+	defer s.Close()
+
+	users := make(chan *UserToImport)
+	go func() {
+		for i := 0; i < 4; i++ {
+			users <- (&UserToImport{}).UID(fmt.Sprintf("user%d", i))
+		}
+		close(users)
+	}()
+
+	runner := s.Client.NewBulkRunner(&BulkRunnerOptions{BatchSize: 2})
+	result, err := runner.ImportUsers(context.Background(), users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SuccessCount != 2 || result.FailureCount != 2 || len(result.Errors) != 2 {
+		t.Fatalf("ImportUsers() = %+v; want = {2, 2, 2 errors}", result)
+	}
+	if result.Errors[0].Index != 0 || result.Errors[1].Index != 2 {
+		t.Errorf("Error indices = (%d, %d); want = (0, 2)",
+			result.Errors[0].Index, result.Errors[1].Index)
+	}
+}
+
+func TestBulkRunnerGetUsers(t *testing.T) {
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		ids := body["localId"].([]interface{})
+		w.Write([]byte(fmt.Sprintf(`{"users": [{"localId": %q}]}`, ids[0])))
+	})
+	defer s.Close()
+
+	identifiers := make(chan UserIdentifier)
+	go func() {
+		for i := 0; i < 150; i++ {
+			identifiers <- UIDIdentifier{UID: fmt.Sprintf("user%d", i)}
+		}
+		close(identifiers)
+	}()
+
+	runner := s.Client.NewBulkRunner(nil)
+	result, err := runner.GetUsers(context.Background(), identifiers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Users) != 2 || len(result.NotFound) != 148 {
+		t.Errorf("GetUsers() = (%d users, %d not found); want = (2, 148)",
+			len(result.Users), len(result.NotFound))
+	}
+}
+
+func TestBulkRunnerUpdateUsers(t *testing.T) {
+	var mutex sync.Mutex
+	updates := make(map[string]map[string]interface{})
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		uid := body["localId"].(string)
+		mutex.Lock()
+		updates[uid] = body
+		mutex.Unlock()
+		if uid == "user3" || uid == "user7" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"message": "USER_NOT_FOUND"}}`))
+			return
+		}
+		w.Write([]byte(fmt.Sprintf(`{"localId": %q}`, uid)))
+	})
+	defer s.Close()
+
+	cases := []struct {
+		name  string
+		run   func(*BulkRunner) (*UpdateUsersResult, error)
+		check func(body map[string]interface{}) bool
+	}{
+		{
+			"RevokeRefreshTokens",
+			func(r *BulkRunner) (*UpdateUsersResult, error) {
+				return r.RevokeRefreshTokens(context.Background(), uidStream(10))
+			},
+			func(body map[string]interface{}) bool {
+				_, ok := body["validSince"].(string)
+				return ok
+			},
+		},
+		{
+			"DisableUsers",
+			func(r *BulkRunner) (*UpdateUsersResult, error) {
+				return r.DisableUsers(context.Background(), uidStream(10))
+			},
+			func(body map[string]interface{}) bool {
+				return body["disableUser"] == true
+			},
+		},
+		{
+			"EnableUsers",
+			func(r *BulkRunner) (*UpdateUsersResult, error) {
+				return r.EnableUsers(context.Background(), uidStream(10))
+			},
+			func(body map[string]interface{}) bool {
+				return body["disableUser"] == false
+			},
+		},
+	}
+	for _, tc := range cases {
+		updates = make(map[string]map[string]interface{})
+		result, err := tc.run(s.Client.NewBulkRunner(&BulkRunnerOptions{Concurrency: 4}))
+		if err != nil {
+			t.Fatalf("%s() = %v", tc.name, err)
+		}
+
+		if len(updates) != 10 {
+			t.Errorf("%s() requests = %d; want = 10", tc.name, len(updates))
+		}
+		for uid, body := range updates {
+			if !tc.check(body) {
+				t.Errorf("%s() request for %q = %v", tc.name, uid, body)
+			}
+		}
+		if result.SuccessCount != 8 || result.FailureCount != 2 || len(result.Errors) != 2 {
+			t.Fatalf("%s() = %+v; want = {8, 2, 2 errors}", tc.name, result)
+		}
+		for i, want := range []int{3, 7} {
+			e := result.Errors[i]
+			if e.Index != want || e.UID != fmt.Sprintf("user%d", want) || e.Reason == "" {
+				t.Errorf("%s() Errors[%d] = %+v; want = {%d, user%d}", tc.name, i, e, want, want)
+			}
+		}
+	}
+}
+
+func TestBulkRunnerUpdateUsersRetriesQuotaErrors(t *testing.T) {
+	var mutex sync.Mutex
+	requests := 0
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		mutex.Lock()
+		requests++
+		n := requests
+		mutex.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"message": "QUOTA_EXCEEDED"}}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	defer s.Close()
+
+	runner := s.Client.NewBulkRunner(&BulkRunnerOptions{RetryDelay: time.Millisecond})
+	result, err := runner.DisableUsers(context.Background(), uidStream(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SuccessCount != 3 || result.FailureCount != 0 || requests != 4 {
+		t.Errorf("DisableUsers() = (%+v, %d requests); want = ({3, 0}, 4 requests)", result, requests)
+	}
+}
+
+func TestBulkRunnerRetriesQuotaErrors(t *testing.T) {
+	var mutex sync.Mutex
+	requests := 0
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		mutex.Lock()
+		requests++
+		n := requests
+		mutex.Unlock()
+		if n <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"message": "QUOTA_EXCEEDED : Exceeded quota for deleting accounts."}}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	defer s.Close()
+
+	runner := s.Client.NewBulkRunner(&BulkRunnerOptions{RetryDelay: time.Millisecond})
+	result, err := runner.DeleteUsers(context.Background(), uidStream(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SuccessCount != 5 || requests != 3 {
+		t.Errorf("DeleteUsers() = (%d, %d requests); want = (5, 3 requests)", result.SuccessCount, requests)
+	}
+
+	requests = 0
+	runner = s.Client.NewBulkRunner(&BulkRunnerOptions{
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	})
+	result, err = runner.DeleteUsers(context.Background(), uidStream(5))
+	if result == nil || result.SuccessCount != 0 || !IsQuotaExceeded(err) {
+		t.Errorf("DeleteUsers() = (%v, %v); want = ({0}, QuotaExceeded)", result, err)
+	}
+	if requests != 2 {
+		t.Errorf("Requests = %d; want = 2", requests)
+	}
+}
+
+func TestBulkRunnerError(t *testing.T) {
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "INVALID_ARGUMENT"}}`))
+	})
+	defer s.Close()
+
+	runner := s.Client.NewBulkRunner(&BulkRunnerOptions{
+		Concurrency: 2,
+		BatchSize:   1,
+	})
+	result, err := runner.DeleteUsers(context.Background(), uidStream(10))
+	if result == nil || result.SuccessCount != 0 || err == nil {
+		t.Errorf("DeleteUsers() = (%v, %v); want = ({0}, error)", result, err)
+	}
+}
+
+func TestBulkRunnerErrorReturnsPartialResult(t *testing.T) {
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		if body["localIds"].([]interface{})[0] == "user2" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"message": "INVALID_ARGUMENT"}}`))
+			return
+		}
+		w.Write([]byte(`{"errors": [{"index": 1, "message": "NOT_DISABLED"}]}`))
+	})
+	defer s.Close()
+
+	runner := s.Client.NewBulkRunner(&BulkRunnerOptions{BatchSize: 2})
+	result, err := runner.DeleteUsers(context.Background(), uidStream(6))
+	if err == nil {
+		t.Fatal("DeleteUsers() = nil; want = error")
+	}
+	if result == nil || result.SuccessCount != 1 || result.FailureCount != 1 ||
+		len(result.Errors) != 1 || result.Errors[0].Index != 1 {
+		t.Errorf("DeleteUsers() = %+v; want = {1, 1, [{Index: 1}]}", result)
+	}
+}
+
+func TestBulkRunnerCheckpoint(t *testing.T) {
+	failAt := "user20"
+	var mutex sync.Mutex
+	var deleted []string
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		ids := body["localIds"].([]interface{})
+		if ids[0] == failAt {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": {"message": "INTERNAL_ERROR"}}`))
+			return
+		}
+		mutex.Lock()
+		for _, id := range ids {
+			deleted = append(deleted, id.(string))
+		}
+		mutex.Unlock()
+		w.Write([]byte(`{}`))
+	})
+	defer s.Close()
+	s.Client.httpClient.RetryConfig = nil
+
+	store := FileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	opts := &BulkRunnerOptions{
+		BatchSize:  10,
+		Checkpoint: store,
+	}
+	result, err := s.Client.NewBulkRunner(opts).DeleteUsers(context.Background(), uidStream(25))
+	if err == nil {
+		t.Fatalf("DeleteUsers() = nil; want = error")
+	}
+	if result == nil || result.SuccessCount != 20 {
+		t.Errorf("DeleteUsers() = %v; want = {SuccessCount: 20}", result)
+	}
+	cp, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp == nil || cp.Processed != 20 {
+		t.Fatalf("Checkpoint = %v; want = {Processed: 20}", cp)
+	}
+
+	failAt = ""
+	deleted = nil
+	result, err = s.Client.NewBulkRunner(opts).DeleteUsers(context.Background(), uidStream(25))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SuccessCount != 5 || len(deleted) != 5 || deleted[0] != "user20" {
+		t.Errorf("DeleteUsers() = (%d, %v); want = (5, [user20...user24])", result.SuccessCount, deleted)
+	}
+	cp, err = store.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp == nil || cp.Processed != 25 {
+		t.Errorf("Checkpoint = %v; want = {Processed: 25}", cp)
+	}
+}
+
+func TestBulkRunnerCheckpointFailedEntries(t *testing.T) {
+	failAt := "user20"
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		if body["localIds"].([]interface{})[0] == failAt {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": {"message": "INTERNAL_ERROR"}}`))
+			return
+		}
+		w.Write([]byte(`{"errors": [{"index": 1, "message": "NOT_DISABLED"}]}`))
+	})
+	defer s.Close()
+	s.Client.httpClient.RetryConfig = nil
+
+	store := FileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	opts := &BulkRunnerOptions{
+		BatchSize:  10,
+		Checkpoint: store,
+	}
+	if _, err := s.Client.NewBulkRunner(opts).DeleteUsers(context.Background(), uidStream(25)); err == nil {
+		t.Fatalf("DeleteUsers() = nil; want = error")
+	}
+	cp, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BulkCheckpoint{Processed: 20, Failed: []int{1, 11}}
+	if !reflect.DeepEqual(cp, want) {
+		t.Fatalf("Checkpoint = %+v; want = %+v", cp, want)
+	}
+
+	failAt = ""
+	result, err := s.Client.NewBulkRunner(opts).DeleteUsers(context.Background(), uidStream(25))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FailureCount != 1 || result.Errors[0].Index != 21 {
+		t.Errorf("DeleteUsers() = %+v; want = {FailureCount: 1, Errors: [{Index: 21}]}", result)
+	}
+	cp, err = store.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &BulkCheckpoint{Processed: 25, Failed: []int{1, 11, 21}}
+	if !reflect.DeepEqual(cp, want) {
+		t.Errorf("Checkpoint = %+v; want = %+v", cp, want)
+	}
+}
+
+func TestBulkRunnerContextCanceled(t *testing.T) {
+	s := bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		w.Write([]byte(`{}`))
+	})
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	uids := make(chan string)
+	result, err := s.Client.NewBulkRunner(nil).DeleteUsers(ctx, uids)
+	if result == nil || result.SuccessCount != 0 || err != context.Canceled {
+		t.Errorf("DeleteUsers() = (%v, %v); want = ({0}, %v)", result, err, context.Canceled)
+	}
+}
+
+func TestFileCheckpointStoreEmpty(t *testing.T) {
+	store := FileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	cp, err := store.Load(context.Background())
+	if cp != nil || err != nil {
+		t.Errorf("Load() = (%v, %v); want = (nil, nil)", cp, err)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	tb := newTokenBucket(1, 2)
+	if d := tb.reserve(); d != 0 {
+		t.Errorf("reserve() = %v; want = 0", d)
+	}
+	if d := tb.reserve(); d != 0 {
+		t.Errorf("reserve() = %v; want = 0", d)
+	}
+	if d := tb.reserve(); d <= 0 || d > time.Second {
+		t.Errorf("reserve() = %v; want = (0, 1s]", d)
+	}
+
+	if tb := newTokenBucket(0, 1); tb != nil {
+		t.Errorf("newTokenBucket(0) = %v; want = nil", tb)
+	}
+	var unlimited *tokenBucket
+	if err := unlimited.wait(context.Background()); err != nil {
+		t.Errorf("wait() = %v; want = nil", err)
+	}
+}
+
+func bulkServer(t *testing.T, handler func(w http.ResponseWriter, body map[string]interface{})) *mockAuthServer {
+	s := echoServer(nil, t)
+	s.Srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, body)
+	})
+	return s
+}
+
+func uidStream(n int) <-chan string {
+	uids := make(chan string)
+	go func() {
+		for i := 0; i < n; i++ {
+			uids <- fmt.Sprintf("user%d", i)
+		}
+		close(uids)
+	}()
+	return uids
+}
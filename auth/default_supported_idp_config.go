@@ -0,0 +1,403 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"firebase.google.com/go/v4/internal"
+	"google.golang.org/api/iterator"
+)
+
+// IDs of some of the built-in identity providers that can be configured with a
+// DefaultSupportedIdpConfig.
+const (
+	GoogleProviderID    = "google.com"
+	AppleProviderID     = "apple.com"
+	FacebookProviderID  = "facebook.com"
+	GitHubProviderID    = "github.com"
+	MicrosoftProviderID = "microsoft.com"
+	TwitterProviderID   = "twitter.com"
+	YahooProviderID     = "yahoo.com"
+)
+
+const (
+	appleSignInConfigKey = "appleSignInConfig"
+	appleBundleIDsKey    = "appleSignInConfig.bundleIds"
+	appleKeyIDKey        = "appleSignInConfig.codeFlowConfig.keyId"
+	appleTeamIDKey       = "appleSignInConfig.codeFlowConfig.teamId"
+	applePrivateKeyKey   = "appleSignInConfig.codeFlowConfig.privateKey"
+)
+
+// DefaultSupportedIdpConfig is the configuration of a built-in identity provider, such as Google,
+// Apple, Facebook, GitHub or Microsoft.
+type DefaultSupportedIdpConfig struct {
+	// The ID of the identity provider, such as "google.com".
+	ID                string
+	Enabled           bool
+	ClientID          string
+	ClientSecret      string
+	AppleSignInConfig *AppleSignInConfig
+}
+
+// AppleSignInConfig is the additional configuration of the Apple identity provider.
+type AppleSignInConfig struct {
+	// The bundle IDs of the iOS apps allowed to sign in with Apple.
+	BundleIDs []string `json:"bundleIds,omitempty"`
+	// The configuration used to exchange authorization codes with Apple. Required for web and
+	// Android sign in.
+	CodeFlowConfig *AppleCodeFlowConfig `json:"codeFlowConfig,omitempty"`
+}
+
+// AppleCodeFlowConfig is the configuration used to exchange authorization codes with Apple.
+type AppleCodeFlowConfig struct {
+	KeyID  string `json:"keyId,omitempty"`
+	TeamID string `json:"teamId,omitempty"`
+	// The private key used to sign the client secret JWT. This is input only, and is not returned
+	// by the backend.
+	PrivateKey string `json:"privateKey,omitempty"`
+}
+
+// DefaultSupportedIdpConfigToCreate represents the options used to create a new
+// DefaultSupportedIdpConfig.
+type DefaultSupportedIdpConfigToCreate struct {
+	id     string
+	params nestedMap
+}
+
+// ID sets the ID of the identity provider to configure, such as "google.com".
+func (config *DefaultSupportedIdpConfigToCreate) ID(id string) *DefaultSupportedIdpConfigToCreate {
+	config.id = id
+	return config
+}
+
+// ClientID sets the client ID of the new config.
+func (config *DefaultSupportedIdpConfigToCreate) ClientID(clientID string) *DefaultSupportedIdpConfigToCreate {
+	return config.set(clientIDKey, clientID)
+}
+
+// ClientSecret sets the client secret of the new config.
+func (config *DefaultSupportedIdpConfigToCreate) ClientSecret(secret string) *DefaultSupportedIdpConfigToCreate {
+	return config.set(clientSecretKey, secret)
+}
+
+// Enabled enables or disables the new config.
+func (config *DefaultSupportedIdpConfigToCreate) Enabled(enabled bool) *DefaultSupportedIdpConfigToCreate {
+	return config.set(enabledKey, enabled)
+}
+
+// AppleSignInConfig sets the additional configuration of the Apple identity provider.
+func (config *DefaultSupportedIdpConfigToCreate) AppleSignInConfig(
+	apple AppleSignInConfig) *DefaultSupportedIdpConfigToCreate {
+	return config.set(appleSignInConfigKey, apple)
+}
+
+func (config *DefaultSupportedIdpConfigToCreate) set(key string, value interface{}) *DefaultSupportedIdpConfigToCreate {
+	if config.params == nil {
+		config.params = make(nestedMap)
+	}
+
+	config.params.Set(key, value)
+	return config
+}
+
+func (config *DefaultSupportedIdpConfigToCreate) buildRequest() (nestedMap, string, error) {
+	if err := validateDefaultSupportedIdpID(config.id); err != nil {
+		return nil, "", err
+	}
+
+	if len(config.params) == 0 {
+		return nil, "", errors.New("no parameters specified in the create request")
+	}
+
+	if val, ok := config.params.GetString(clientIDKey); !ok || val == "" {
+		return nil, "", errors.New("ClientID must not be empty")
+	}
+
+	return config.params, config.id, nil
+}
+
+// DefaultSupportedIdpConfigToUpdate represents the options used to update an existing
+// DefaultSupportedIdpConfig.
+type DefaultSupportedIdpConfigToUpdate struct {
+	params nestedMap
+}
+
+// ClientID updates the client ID of the config.
+func (config *DefaultSupportedIdpConfigToUpdate) ClientID(clientID string) *DefaultSupportedIdpConfigToUpdate {
+	return config.set(clientIDKey, clientID)
+}
+
+// ClientSecret updates the client secret of the config.
+func (config *DefaultSupportedIdpConfigToUpdate) ClientSecret(secret string) *DefaultSupportedIdpConfigToUpdate {
+	return config.set(clientSecretKey, secret)
+}
+
+// Enabled enables or disables the config.
+func (config *DefaultSupportedIdpConfigToUpdate) Enabled(enabled bool) *DefaultSupportedIdpConfigToUpdate {
+	return config.set(enabledKey, enabled)
+}
+
+// AppleSignInConfig updates the additional configuration of the Apple identity provider.
+//
+// Only the fields that are set are updated. A non-nil empty BundleIDs slice removes all the bundle
+// IDs, while empty code flow fields are left unchanged.
+func (config *DefaultSupportedIdpConfigToUpdate) AppleSignInConfig(
+	apple AppleSignInConfig) *DefaultSupportedIdpConfigToUpdate {
+	if apple.BundleIDs != nil {
+		config.set(appleBundleIDsKey, apple.BundleIDs)
+	}
+	if flow := apple.CodeFlowConfig; flow != nil {
+		if flow.KeyID != "" {
+			config.set(appleKeyIDKey, flow.KeyID)
+		}
+		if flow.TeamID != "" {
+			config.set(appleTeamIDKey, flow.TeamID)
+		}
+		if flow.PrivateKey != "" {
+			config.set(applePrivateKeyKey, flow.PrivateKey)
+		}
+	}
+	return config
+}
+
+func (config *DefaultSupportedIdpConfigToUpdate) set(key string, value interface{}) *DefaultSupportedIdpConfigToUpdate {
+	if config.params == nil {
+		config.params = make(nestedMap)
+	}
+
+	config.params.Set(key, value)
+	return config
+}
+
+func (config *DefaultSupportedIdpConfigToUpdate) buildRequest() (nestedMap, error) {
+	if len(config.params) == 0 {
+		return nil, errors.New("no parameters specified in the update request")
+	}
+
+	if val, ok := config.params.GetString(clientIDKey); ok && val == "" {
+		return nil, errors.New("ClientID must not be empty")
+	}
+
+	return config.params, nil
+}
+
+// DefaultSupportedIdpConfigIterator is an iterator over the configurations of built-in identity
+// providers.
+type DefaultSupportedIdpConfigIterator struct {
+	client   *baseClient
+	ctx      context.Context
+	nextFunc func() error
+	pageInfo *iterator.PageInfo
+	configs  []*DefaultSupportedIdpConfig
+}
+
+// PageInfo supports pagination.
+func (it *DefaultSupportedIdpConfigIterator) PageInfo() *iterator.PageInfo {
+	return it.pageInfo
+}
+
+// Next returns the next DefaultSupportedIdpConfig. The error value of [iterator.Done] is
+// returned if there are no more results. Once Next returns [iterator.Done], all
+// subsequent calls will return [iterator.Done].
+func (it *DefaultSupportedIdpConfigIterator) Next() (*DefaultSupportedIdpConfig, error) {
+	if err := it.nextFunc(); err != nil {
+		return nil, err
+	}
+
+	config := it.configs[0]
+	it.configs = it.configs[1:]
+	return config, nil
+}
+
+func (it *DefaultSupportedIdpConfigIterator) fetch(pageSize int, pageToken string) (string, error) {
+	params := map[string]string{
+		"pageSize": strconv.Itoa(pageSize),
+	}
+	if pageToken != "" {
+		params["pageToken"] = pageToken
+	}
+
+	req := &internal.Request{
+		Method: http.MethodGet,
+		URL:    "/defaultSupportedIdpConfigs",
+		Opts: []internal.HTTPOption{
+			internal.WithQueryParams(params),
+		},
+	}
+
+	var result struct {
+		Configs       []defaultSupportedIdpConfigDAO `json:"defaultSupportedIdpConfigs"`
+		NextPageToken string                         `json:"nextPageToken"`
+	}
+	if _, err := it.client.makeRequest(it.ctx, req, &result); err != nil {
+		return "", err
+	}
+
+	for _, config := range result.Configs {
+		it.configs = append(it.configs, config.toDefaultSupportedIdpConfig())
+	}
+
+	it.pageInfo.Token = result.NextPageToken
+	return result.NextPageToken, nil
+}
+
+// DefaultSupportedIdpConfig returns the configuration of the built-in identity provider with the
+// given ID.
+func (c *baseClient) DefaultSupportedIdpConfig(ctx context.Context, id string) (*DefaultSupportedIdpConfig, error) {
+	if err := validateDefaultSupportedIdpID(id); err != nil {
+		return nil, err
+	}
+
+	req := &internal.Request{
+		Method: http.MethodGet,
+		URL:    fmt.Sprintf("/defaultSupportedIdpConfigs/%s", id),
+	}
+	var result defaultSupportedIdpConfigDAO
+	if _, err := c.makeRequest(ctx, req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.toDefaultSupportedIdpConfig(), nil
+}
+
+// CreateDefaultSupportedIdpConfig configures a built-in identity provider from the given
+// parameters.
+func (c *baseClient) CreateDefaultSupportedIdpConfig(
+	ctx context.Context, config *DefaultSupportedIdpConfigToCreate) (*DefaultSupportedIdpConfig, error) {
+	if config == nil {
+		return nil, errors.New("config must not be nil")
+	}
+
+	body, id, err := config.buildRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	req := &internal.Request{
+		Method: http.MethodPost,
+		URL:    "/defaultSupportedIdpConfigs",
+		Body:   internal.NewJSONEntity(body),
+		Opts: []internal.HTTPOption{
+			internal.WithQueryParam("idpId", id),
+		},
+	}
+	var result defaultSupportedIdpConfigDAO
+	if _, err := c.makeRequest(ctx, req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.toDefaultSupportedIdpConfig(), nil
+}
+
+// UpdateDefaultSupportedIdpConfig updates the configuration of a built-in identity provider with
+// the given parameters. This can be used to rotate the client ID and secret of the provider.
+func (c *baseClient) UpdateDefaultSupportedIdpConfig(
+	ctx context.Context, id string, config *DefaultSupportedIdpConfigToUpdate) (*DefaultSupportedIdpConfig, error) {
+	if err := validateDefaultSupportedIdpID(id); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.New("config must not be nil")
+	}
+
+	body, err := config.buildRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	mask := body.UpdateMask()
+	req := &internal.Request{
+		Method: http.MethodPatch,
+		URL:    fmt.Sprintf("/defaultSupportedIdpConfigs/%s", id),
+		Body:   internal.NewJSONEntity(body),
+		Opts: []internal.HTTPOption{
+			internal.WithQueryParam("updateMask", strings.Join(mask, ",")),
+		},
+	}
+	var result defaultSupportedIdpConfigDAO
+	if _, err := c.makeRequest(ctx, req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.toDefaultSupportedIdpConfig(), nil
+}
+
+// DeleteDefaultSupportedIdpConfig deletes the configuration of the built-in identity provider with
+// the given ID.
+func (c *baseClient) DeleteDefaultSupportedIdpConfig(ctx context.Context, id string) error {
+	if err := validateDefaultSupportedIdpID(id); err != nil {
+		return err
+	}
+
+	req := &internal.Request{
+		Method: http.MethodDelete,
+		URL:    fmt.Sprintf("/defaultSupportedIdpConfigs/%s", id),
+	}
+	_, err := c.makeRequest(ctx, req, nil)
+	return err
+}
+
+// DefaultSupportedIdpConfigs returns an iterator over the configurations of built-in identity
+// providers.
+//
+// If nextPageToken is empty, the iterator will start at the beginning. Otherwise,
+// iterator starts after the token.
+func (c *baseClient) DefaultSupportedIdpConfigs(
+	ctx context.Context, nextPageToken string) *DefaultSupportedIdpConfigIterator {
+	it := &DefaultSupportedIdpConfigIterator{
+		ctx:    ctx,
+		client: c,
+	}
+	it.pageInfo, it.nextFunc = iterator.NewPageInfo(
+		it.fetch,
+		func() int { return len(it.configs) },
+		func() interface{} { b := it.configs; it.configs = nil; return b })
+	it.pageInfo.MaxSize = maxConfigs
+	it.pageInfo.Token = nextPageToken
+	return it
+}
+
+type defaultSupportedIdpConfigDAO struct {
+	Name              string             `json:"name"`
+	Enabled           bool               `json:"enabled"`
+	ClientID          string             `json:"clientId"`
+	ClientSecret      string             `json:"clientSecret"`
+	AppleSignInConfig *AppleSignInConfig `json:"appleSignInConfig"`
+}
+
+func (dao *defaultSupportedIdpConfigDAO) toDefaultSupportedIdpConfig() *DefaultSupportedIdpConfig {
+	return &DefaultSupportedIdpConfig{
+		ID:                extractResourceID(dao.Name),
+		Enabled:           dao.Enabled,
+		ClientID:          dao.ClientID,
+		ClientSecret:      dao.ClientSecret,
+		AppleSignInConfig: dao.AppleSignInConfig,
+	}
+}
+
+func validateDefaultSupportedIdpID(id string) error {
+	if id == "" || strings.Contains(id, "/") ||
+		strings.HasPrefix(id, "oidc.") || strings.HasPrefix(id, "saml.") {
+		return fmt.Errorf("invalid default supported IdP id: %q", id)
+	}
+
+	return nil
+}
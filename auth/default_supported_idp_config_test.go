@@ -0,0 +1,414 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"firebase.google.com/go/v4/errorutils"
+	"google.golang.org/api/iterator"
+)
+
+const defaultIdpConfigResponse = `{
+	"name": "projects/mock-project-id/defaultSupportedIdpConfigs/google.com",
+	"enabled": true,
+	"clientId": "CLIENT_ID",
+	"clientSecret": "CLIENT_SECRET"
+}`
+
+const appleIdpConfigResponse = `{
+	"name": "projects/mock-project-id/defaultSupportedIdpConfigs/apple.com",
+	"enabled": true,
+	"clientId": "com.example.service",
+	"appleSignInConfig": {
+		"bundleIds": ["com.example.app"],
+		"codeFlowConfig": {"keyId": "KEY_ID", "teamId": "TEAM_ID"}
+	}
+}`
+
+var defaultIdpConfig = &DefaultSupportedIdpConfig{
+	ID:           GoogleProviderID,
+	Enabled:      true,
+	ClientID:     "CLIENT_ID",
+	ClientSecret: "CLIENT_SECRET",
+}
+
+var appleIdpConfig = &DefaultSupportedIdpConfig{
+	ID:       AppleProviderID,
+	Enabled:  true,
+	ClientID: "com.example.service",
+	AppleSignInConfig: &AppleSignInConfig{
+		BundleIDs:      []string{"com.example.app"},
+		CodeFlowConfig: &AppleCodeFlowConfig{KeyID: "KEY_ID", TeamID: "TEAM_ID"},
+	},
+}
+
+var invalidDefaultIdpIDs = []string{
+	"",
+	"oidc.provider",
+	"saml.provider",
+	"google.com/other",
+}
+
+func TestDefaultSupportedIdpConfig(t *testing.T) {
+	s := echoServer([]byte(defaultIdpConfigResponse), t)
+	defer s.Close()
+
+	config, err := s.Client.DefaultSupportedIdpConfig(context.Background(), GoogleProviderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, defaultIdpConfig) {
+		t.Errorf("DefaultSupportedIdpConfig() = %#v; want = %#v", config, defaultIdpConfig)
+	}
+
+	req := s.Req[0]
+	if req.Method != http.MethodGet {
+		t.Errorf("DefaultSupportedIdpConfig() Method = %q; want = %q", req.Method, http.MethodGet)
+	}
+	wantURL := "/projects/mock-project-id/defaultSupportedIdpConfigs/google.com"
+	if req.URL.Path != wantURL {
+		t.Errorf("DefaultSupportedIdpConfig() URL = %q; want = %q", req.URL.Path, wantURL)
+	}
+}
+
+func TestDefaultSupportedIdpConfigInvalidID(t *testing.T) {
+	client := &baseClient{}
+	wantErr := "invalid default supported IdP id: "
+
+	for _, id := range invalidDefaultIdpIDs {
+		config, err := client.DefaultSupportedIdpConfig(context.Background(), id)
+		if config != nil || err == nil || !strings.HasPrefix(err.Error(), wantErr) {
+			t.Errorf("DefaultSupportedIdpConfig(%q) = (%v, %v); want = (nil, %q)", id, config, err, wantErr)
+		}
+		if err := client.DeleteDefaultSupportedIdpConfig(context.Background(), id); err == nil ||
+			!strings.HasPrefix(err.Error(), wantErr) {
+			t.Errorf("DeleteDefaultSupportedIdpConfig(%q) = %v; want = %q", id, err, wantErr)
+		}
+	}
+}
+
+func TestDefaultSupportedIdpConfigError(t *testing.T) {
+	s := echoServer([]byte(notFoundResponse), t)
+	defer s.Close()
+	s.Status = http.StatusNotFound
+
+	config, err := s.Client.DefaultSupportedIdpConfig(context.Background(), GoogleProviderID)
+	if config != nil || err == nil || !IsConfigurationNotFound(err) {
+		t.Errorf("DefaultSupportedIdpConfig() = (%v, %v); want = (nil, ConfigurationNotFound)", config, err)
+	}
+}
+
+func TestCreateDefaultSupportedIdpConfig(t *testing.T) {
+	s := echoServer([]byte(appleIdpConfigResponse), t)
+	defer s.Close()
+
+	options := (&DefaultSupportedIdpConfigToCreate{}).
+		ID(AppleProviderID).
+		Enabled(true).
+		ClientID("com.example.service").
+		AppleSignInConfig(AppleSignInConfig{
+			BundleIDs: []string{"com.example.app"},
+			CodeFlowConfig: &AppleCodeFlowConfig{
+				KeyID:      "KEY_ID",
+				TeamID:     "TEAM_ID",
+				PrivateKey: "PRIVATE_KEY",
+			},
+		})
+	config, err := s.Client.CreateDefaultSupportedIdpConfig(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, appleIdpConfig) {
+		t.Errorf("CreateDefaultSupportedIdpConfig() = %#v; want = %#v", config, appleIdpConfig)
+	}
+
+	req := s.Req[0]
+	wantURL := "/projects/mock-project-id/defaultSupportedIdpConfigs"
+	if req.Method != http.MethodPost || req.URL.Path != wantURL {
+		t.Errorf("CreateDefaultSupportedIdpConfig() = %s %q; want = POST %q", req.Method, req.URL.Path, wantURL)
+	}
+	if wantQuery := "idpId=apple.com"; req.URL.RawQuery != wantQuery {
+		t.Errorf("CreateDefaultSupportedIdpConfig() Query = %q; want = %q", req.URL.RawQuery, wantQuery)
+	}
+
+	wantBody := map[string]interface{}{
+		"enabled":  true,
+		"clientId": "com.example.service",
+		"appleSignInConfig": map[string]interface{}{
+			"bundleIds": []interface{}{"com.example.app"},
+			"codeFlowConfig": map[string]interface{}{
+				"keyId":      "KEY_ID",
+				"teamId":     "TEAM_ID",
+				"privateKey": "PRIVATE_KEY",
+			},
+		},
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(s.Rbody, &body); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(body, wantBody) {
+		t.Errorf("CreateDefaultSupportedIdpConfig() Body = %#v; want = %#v", body, wantBody)
+	}
+}
+
+func TestCreateDefaultSupportedIdpConfigInvalidInput(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+		conf *DefaultSupportedIdpConfigToCreate
+	}{
+		{
+			name: "NilConfig",
+			want: "config must not be nil",
+			conf: nil,
+		},
+		{
+			name: "InvalidID",
+			want: "invalid default supported IdP id: ",
+			conf: (&DefaultSupportedIdpConfigToCreate{}).ID("oidc.provider").ClientID("CLIENT_ID"),
+		},
+		{
+			name: "EmptyOptions",
+			want: "no parameters specified in the create request",
+			conf: (&DefaultSupportedIdpConfigToCreate{}).ID(GoogleProviderID),
+		},
+		{
+			name: "EmptyClientID",
+			want: "ClientID must not be empty",
+			conf: (&DefaultSupportedIdpConfigToCreate{}).ID(GoogleProviderID).ClientSecret("SECRET"),
+		},
+	}
+
+	client := &baseClient{}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := client.CreateDefaultSupportedIdpConfig(context.Background(), tc.conf)
+			if config != nil || err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("CreateDefaultSupportedIdpConfig() = (%v, %v); want = (nil, %q)", config, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateDefaultSupportedIdpConfig(t *testing.T) {
+	s := echoServer([]byte(defaultIdpConfigResponse), t)
+	defer s.Close()
+
+	tc, err := s.Client.TenantManager.AuthForTenant("tenantID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := (&DefaultSupportedIdpConfigToUpdate{}).
+		ClientID("CLIENT_ID").
+		ClientSecret("CLIENT_SECRET").
+		Enabled(true)
+	config, err := tc.UpdateDefaultSupportedIdpConfig(context.Background(), GoogleProviderID, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, defaultIdpConfig) {
+		t.Errorf("UpdateDefaultSupportedIdpConfig() = %#v; want = %#v", config, defaultIdpConfig)
+	}
+
+	req := s.Req[0]
+	wantURL := "/projects/mock-project-id/tenants/tenantID/defaultSupportedIdpConfigs/google.com"
+	if req.Method != http.MethodPatch || req.URL.Path != wantURL {
+		t.Errorf("UpdateDefaultSupportedIdpConfig() = %s %q; want = PATCH %q", req.Method, req.URL.Path, wantURL)
+	}
+	mask := strings.Split(req.URL.Query().Get("updateMask"), ",")
+	sort.Strings(mask)
+	if wantMask := []string{"clientId", "clientSecret", "enabled"}; !reflect.DeepEqual(mask, wantMask) {
+		t.Errorf("UpdateDefaultSupportedIdpConfig() Mask = %v; want = %v", mask, wantMask)
+	}
+
+	wantBody := map[string]interface{}{
+		"clientId":     "CLIENT_ID",
+		"clientSecret": "CLIENT_SECRET",
+		"enabled":      true,
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(s.Rbody, &body); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(body, wantBody) {
+		t.Errorf("UpdateDefaultSupportedIdpConfig() Body = %#v; want = %#v", body, wantBody)
+	}
+}
+
+func TestUpdateDefaultSupportedIdpConfigAppleSignIn(t *testing.T) {
+	s := echoServer([]byte(defaultIdpConfigResponse), t)
+	defer s.Close()
+
+	options := (&DefaultSupportedIdpConfigToUpdate{}).
+		AppleSignInConfig(AppleSignInConfig{
+			BundleIDs: []string{"com.example.app"},
+			CodeFlowConfig: &AppleCodeFlowConfig{
+				KeyID:      "KEY_ID",
+				PrivateKey: "PRIVATE_KEY",
+			},
+		})
+	if _, err := s.Client.UpdateDefaultSupportedIdpConfig(context.Background(), AppleProviderID, options); err != nil {
+		t.Fatal(err)
+	}
+
+	req := s.Req[0]
+	mask := strings.Split(req.URL.Query().Get("updateMask"), ",")
+	sort.Strings(mask)
+	wantMask := []string{
+		"appleSignInConfig.bundleIds",
+		"appleSignInConfig.codeFlowConfig.keyId",
+		"appleSignInConfig.codeFlowConfig.privateKey",
+	}
+	if !reflect.DeepEqual(mask, wantMask) {
+		t.Errorf("UpdateDefaultSupportedIdpConfig() Mask = %v; want = %v", mask, wantMask)
+	}
+
+	wantBody := map[string]interface{}{
+		"appleSignInConfig": map[string]interface{}{
+			"bundleIds": []interface{}{"com.example.app"},
+			"codeFlowConfig": map[string]interface{}{
+				"keyId":      "KEY_ID",
+				"privateKey": "PRIVATE_KEY",
+			},
+		},
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(s.Rbody, &body); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(body, wantBody) {
+		t.Errorf("UpdateDefaultSupportedIdpConfig() Body = %#v; want = %#v", body, wantBody)
+	}
+}
+
+func TestUpdateDefaultSupportedIdpConfigInvalidInput(t *testing.T) {
+	cases := []struct {
+		name string
+		id   string
+		want string
+		conf *DefaultSupportedIdpConfigToUpdate
+	}{
+		{
+			name: "InvalidID",
+			id:   "saml.provider",
+			want: "invalid default supported IdP id: ",
+			conf: (&DefaultSupportedIdpConfigToUpdate{}).Enabled(true),
+		},
+		{
+			name: "NilConfig",
+			id:   GoogleProviderID,
+			want: "config must not be nil",
+		},
+		{
+			name: "EmptyOptions",
+			id:   GoogleProviderID,
+			want: "no parameters specified in the update request",
+			conf: &DefaultSupportedIdpConfigToUpdate{},
+		},
+		{
+			name: "EmptyClientID",
+			id:   GoogleProviderID,
+			want: "ClientID must not be empty",
+			conf: (&DefaultSupportedIdpConfigToUpdate{}).ClientID(""),
+		},
+	}
+
+	client := &baseClient{}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := client.UpdateDefaultSupportedIdpConfig(context.Background(), tc.id, tc.conf)
+			if config != nil || err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("UpdateDefaultSupportedIdpConfig() = (%v, %v); want = (nil, %q)", config, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeleteDefaultSupportedIdpConfig(t *testing.T) {
+	s := echoServer([]byte("{}"), t)
+	defer s.Close()
+
+	if err := s.Client.DeleteDefaultSupportedIdpConfig(context.Background(), GitHubProviderID); err != nil {
+		t.Fatal(err)
+	}
+
+	req := s.Req[0]
+	wantURL := "/projects/mock-project-id/defaultSupportedIdpConfigs/github.com"
+	if req.Method != http.MethodDelete || req.URL.Path != wantURL {
+		t.Errorf("DeleteDefaultSupportedIdpConfig() = %s %q; want = DELETE %q", req.Method, req.URL.Path, wantURL)
+	}
+}
+
+func TestDefaultSupportedIdpConfigs(t *testing.T) {
+	template := `{
+		"defaultSupportedIdpConfigs": [%s, %s],
+		"nextPageToken": ""
+	}`
+	s := echoServer([]byte(fmt.Sprintf(template, defaultIdpConfigResponse, appleIdpConfigResponse)), t)
+	defer s.Close()
+
+	want := []*DefaultSupportedIdpConfig{defaultIdpConfig, appleIdpConfig}
+	for _, token := range []string{"", "pageToken"} {
+		it := s.Client.DefaultSupportedIdpConfigs(context.Background(), token)
+		var got []*DefaultSupportedIdpConfig
+		for {
+			config, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, config)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DefaultSupportedIdpConfigs(%q) = %#v; want = %#v", token, got, want)
+		}
+
+		url := s.Req[len(s.Req)-1].URL
+		if wantPath := "/projects/mock-project-id/defaultSupportedIdpConfigs"; url.Path != wantPath {
+			t.Errorf("DefaultSupportedIdpConfigs(%q) = %q; want = %q", token, url.Path, wantPath)
+		}
+		wantQuery := "pageSize=100"
+		if token != "" {
+			wantQuery += "&pageToken=" + token
+		}
+		if gotQuery := url.Query().Encode(); gotQuery != wantQuery {
+			t.Errorf("DefaultSupportedIdpConfigs(%q) = %q; want = %q", token, gotQuery, wantQuery)
+		}
+	}
+}
+
+func TestDefaultSupportedIdpConfigsError(t *testing.T) {
+	s := echoServer([]byte("{}"), t)
+	defer s.Close()
+	s.Status = http.StatusInternalServerError
+	s.Client.baseClient.httpClient.RetryConfig = nil
+
+	it := s.Client.DefaultSupportedIdpConfigs(context.Background(), "")
+	config, err := it.Next()
+	if config != nil || err == nil || !errorutils.IsInternal(err) {
+		t.Errorf("DefaultSupportedIdpConfigs() = (%v, %v); want = (nil, %q)", config, err, "internal-error")
+	}
+}
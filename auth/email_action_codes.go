@@ -0,0 +1,236 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"firebase.google.com/go/v4/internal"
+)
+
+// ActionCodeOperation identifies the email action that an out-of-band code was issued for.
+type ActionCodeOperation string
+
+// These constants represent the possible values for the ActionCodeOperation type.
+const (
+	ActionCodeOperationEmailSignIn                ActionCodeOperation = "EMAIL_SIGNIN"
+	ActionCodeOperationPasswordReset              ActionCodeOperation = "PASSWORD_RESET"
+	ActionCodeOperationRecoverEmail               ActionCodeOperation = "RECOVER_EMAIL"
+	ActionCodeOperationRevertSecondFactorAddition ActionCodeOperation = "REVERT_SECOND_FACTOR_ADDITION"
+	ActionCodeOperationVerifyAndChangeEmail       ActionCodeOperation = "VERIFY_AND_CHANGE_EMAIL"
+	ActionCodeOperationVerifyEmail                ActionCodeOperation = "VERIFY_EMAIL"
+)
+
+// ActionCode is an out-of-band email action code, along with the parts of the email action link
+// that carries it.
+//
+// ActionCode can be used to build custom email action links, or to render custom emails that are
+// sent through an email provider other than Firebase Auth.
+type ActionCode struct {
+	// The full email action link, as generated by Firebase Auth.
+	Link string
+	// The out-of-band code that identifies the action.
+	OOBCode string
+	// The mode of the action handler, such as "verifyEmail", "resetPassword" or "signIn".
+	Mode         string
+	APIKey       string
+	ContinueURL  string
+	LanguageCode string
+	TenantID     string
+}
+
+// ParseActionLink extracts the out-of-band code and the other parameters from an email action
+// link.
+//
+// Links that wrap the action link in a "link" query parameter, such as Firebase Dynamic Links, are
+// unwrapped before parsing.
+func ParseActionLink(link string) (*ActionCode, error) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("malformed email action link: %q", link)
+	}
+
+	q := u.Query()
+	if q.Get("oobCode") == "" && q.Get("link") != "" {
+		inner, err := ParseActionLink(q.Get("link"))
+		if err != nil {
+			return nil, err
+		}
+		inner.Link = link
+		return inner, nil
+	}
+	if q.Get("oobCode") == "" {
+		return nil, fmt.Errorf("email action link does not contain an action code: %q", link)
+	}
+
+	return &ActionCode{
+		Link:         link,
+		OOBCode:      q.Get("oobCode"),
+		Mode:         q.Get("mode"),
+		APIKey:       q.Get("apiKey"),
+		ContinueURL:  q.Get("continueUrl"),
+		LanguageCode: q.Get("lang"),
+		TenantID:     q.Get("tenantId"),
+	}, nil
+}
+
+// EmailVerificationCode generates the out-of-band action code for email verification flows for the
+// specified email address, using the action code settings provided.
+//
+// Unlike EmailVerificationLinkWithSettings, the returned ActionCode exposes the raw code and the
+// parts of the link, so that the caller can send the email through its own provider. settings may
+// be nil.
+func (c *baseClient) EmailVerificationCode(
+	ctx context.Context, email string, settings *ActionCodeSettings) (*ActionCode, error) {
+	return c.generateActionCode(ctx, emailVerification, email, settings)
+}
+
+// PasswordResetCode generates the out-of-band action code for password reset flows for the
+// specified email address, using the action code settings provided. settings may be nil.
+func (c *baseClient) PasswordResetCode(
+	ctx context.Context, email string, settings *ActionCodeSettings) (*ActionCode, error) {
+	return c.generateActionCode(ctx, passwordReset, email, settings)
+}
+
+// EmailSignInCode generates the out-of-band action code for email link sign-in flows, using the
+// action code settings provided.
+func (c *baseClient) EmailSignInCode(
+	ctx context.Context, email string, settings *ActionCodeSettings) (*ActionCode, error) {
+	return c.generateActionCode(ctx, emailLinkSignIn, email, settings)
+}
+
+// VerifyAndChangeEmailCode generates the out-of-band action code for email verification and change
+// flows for the specified current email address and new email address, using the action code
+// settings provided. settings may be nil.
+func (c *baseClient) VerifyAndChangeEmailCode(
+	ctx context.Context, email string, newEmail string, settings *ActionCodeSettings) (*ActionCode, error) {
+	if newEmail == "" {
+		return nil, errors.New("newEmail must not be empty")
+	}
+	return c.generateActionCode(ctx, verifyAndChangeEmail, email, settings, withNewEmail(newEmail))
+}
+
+func (c *baseClient) generateActionCode(
+	ctx context.Context, linkType linkType, email string, settings *ActionCodeSettings,
+	opts ...emailActionLinkOption) (*ActionCode, error) {
+
+	link, err := c.generateEmailActionLink(ctx, linkType, email, settings, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseActionLink(link)
+}
+
+// ActionCodeInfo describes the email action that an out-of-band code was issued for.
+type ActionCodeInfo struct {
+	Operation ActionCodeOperation
+	// The email address the code was sent to. For RecoverEmail operations this is the email
+	// address that will be restored.
+	Email string
+	// The new email address of VerifyAndChangeEmail operations, or the email address being
+	// replaced in RecoverEmail operations.
+	NewEmail string
+}
+
+// CheckActionCode checks the validity of an out-of-band action code, and returns information about
+// the email action it was issued for. The code is not consumed.
+//
+// IsInvalidActionCode and IsExpiredActionCode can be used to tell why a code is rejected.
+func (c *baseClient) CheckActionCode(ctx context.Context, oobCode string) (*ActionCodeInfo, error) {
+	if oobCode == "" {
+		return nil, errors.New("oobCode must not be empty")
+	}
+
+	payload := map[string]interface{}{
+		"oobCode": oobCode,
+	}
+	var result struct {
+		Email       string              `json:"email"`
+		NewEmail    string              `json:"newEmail"`
+		RequestType ActionCodeOperation `json:"requestType"`
+	}
+	if err := c.resetPassword(ctx, payload, &result); err != nil {
+		return nil, err
+	}
+
+	return &ActionCodeInfo{
+		Operation: result.RequestType,
+		Email:     result.Email,
+		NewEmail:  result.NewEmail,
+	}, nil
+}
+
+// ApplyActionCode applies an out-of-band action code, such as one issued for email verification,
+// email change or email recovery flows. The code is consumed.
+//
+// Password reset codes must be applied with ConfirmPasswordReset instead.
+func (c *baseClient) ApplyActionCode(ctx context.Context, oobCode string) error {
+	if oobCode == "" {
+		return errors.New("oobCode must not be empty")
+	}
+
+	payload := map[string]interface{}{
+		"oobCode": oobCode,
+	}
+	_, err := c.post(ctx, "/accounts:update", payload, nil)
+	return err
+}
+
+// ConfirmPasswordReset sets a new password for the user identified by a password reset action
+// code. The code is consumed.
+func (c *baseClient) ConfirmPasswordReset(ctx context.Context, oobCode string, newPassword string) error {
+	if oobCode == "" {
+		return errors.New("oobCode must not be empty")
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"oobCode":     oobCode,
+		"newPassword": newPassword,
+	}
+	return c.resetPassword(ctx, payload, nil)
+}
+
+// resetPassword calls the accounts:resetPassword endpoint. Unlike the other user management
+// endpoints, it is not scoped to a project, and the tenant is specified in the request body.
+func (c *baseClient) resetPassword(ctx context.Context, payload map[string]interface{}, resp interface{}) error {
+	if c.tenantID != "" {
+		payload["tenantId"] = c.tenantID
+	}
+	req := &internal.Request{
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("%s/accounts:resetPassword", c.userManagementEndpoint),
+		Body:   internal.NewJSONEntity(payload),
+	}
+	_, err := c.httpClient.DoAndUnmarshal(ctx, req, resp)
+	return err
+}
+
+// IsExpiredActionCode checks if the given error was due to an expired out-of-band action code.
+func IsExpiredActionCode(err error) bool {
+	return hasAuthErrorCode(err, expiredActionCode)
+}
+
+// IsInvalidActionCode checks if the given error was due to a malformed or already used out-of-band
+// action code.
+func IsInvalidActionCode(err error) bool {
+	return hasAuthErrorCode(err, invalidActionCode)
+}
@@ -0,0 +1,336 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const testActionCodeLink = "https://mock-project-id.firebaseapp.com/__/auth/action?mode=verifyEmail" +
+	"&oobCode=test-oob-code&apiKey=test-api-key&continueUrl=https%3A%2F%2Fexample.com&lang=fr&tenantId=tenant1"
+
+var testActionCode = &ActionCode{
+	Link:         testActionCodeLink,
+	OOBCode:      "test-oob-code",
+	Mode:         "verifyEmail",
+	APIKey:       "test-api-key",
+	ContinueURL:  "https://example.com",
+	LanguageCode: "fr",
+	TenantID:     "tenant1",
+}
+
+func TestParseActionLink(t *testing.T) {
+	code, err := ParseActionLink(testActionCodeLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(code, testActionCode) {
+		t.Errorf("ParseActionLink() = %#v; want = %#v", code, testActionCode)
+	}
+}
+
+func TestParseActionLinkWrapped(t *testing.T) {
+	link := "https://example.page.link/?link=" + url.QueryEscape(testActionCodeLink) + "&apn=com.example.android"
+	code, err := ParseActionLink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := *testActionCode
+	want.Link = link
+	if !reflect.DeepEqual(code, &want) {
+		t.Errorf("ParseActionLink() = %#v; want = %#v", code, &want)
+	}
+}
+
+func TestParseActionLinkError(t *testing.T) {
+	cases := []struct {
+		link string
+		want string
+	}{
+		{"", `malformed email action link: ""`},
+		{"not a link", `malformed email action link: "not a link"`},
+		{
+			"https://example.com/action?mode=signIn",
+			`email action link does not contain an action code: "https://example.com/action?mode=signIn"`,
+		},
+		{
+			"https://example.page.link/?link=invalid",
+			`malformed email action link: "invalid"`,
+		},
+	}
+	for _, tc := range cases {
+		code, err := ParseActionLink(tc.link)
+		if code != nil || err == nil || err.Error() != tc.want {
+			t.Errorf("ParseActionLink(%q) = (%v, %v); want = (nil, %q)", tc.link, code, err, tc.want)
+		}
+	}
+}
+
+func TestEmailActionCodes(t *testing.T) {
+	resp := []byte(fmt.Sprintf(testActionLinkFormat, testActionCodeLink))
+	s := echoServer(resp, t)
+	defer s.Close()
+
+	cases := []struct {
+		name        string
+		requestType string
+		generate    func() (*ActionCode, error)
+	}{
+		{
+			"EmailVerificationCode",
+			"VERIFY_EMAIL",
+			func() (*ActionCode, error) {
+				return s.Client.EmailVerificationCode(context.Background(), testEmail, testActionCodeSettings)
+			},
+		},
+		{
+			"PasswordResetCode",
+			"PASSWORD_RESET",
+			func() (*ActionCode, error) {
+				return s.Client.PasswordResetCode(context.Background(), testEmail, testActionCodeSettings)
+			},
+		},
+		{
+			"EmailSignInCode",
+			"EMAIL_SIGNIN",
+			func() (*ActionCode, error) {
+				return s.Client.EmailSignInCode(context.Background(), testEmail, testActionCodeSettings)
+			},
+		},
+	}
+	for _, tc := range cases {
+		s.Req = nil
+		code, err := tc.generate()
+		if err != nil {
+			t.Fatalf("%s() = %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(code, testActionCode) {
+			t.Errorf("%s() = %#v; want = %#v", tc.name, code, testActionCode)
+		}
+
+		want := map[string]interface{}{
+			"requestType":   tc.requestType,
+			"email":         testEmail,
+			"returnOobLink": true,
+		}
+		for k, v := range testActionCodeSettingsMap {
+			want[k] = v
+		}
+		if err := checkActionLinkRequest(want, s); err != nil {
+			t.Errorf("%s() %v", tc.name, err)
+		}
+	}
+}
+
+func TestVerifyAndChangeEmailCode(t *testing.T) {
+	resp := []byte(fmt.Sprintf(testActionLinkFormat, testActionCodeLink))
+	s := echoServer(resp, t)
+	defer s.Close()
+
+	code, err := s.Client.VerifyAndChangeEmailCode(context.Background(), testEmail, testNewEmail, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(code, testActionCode) {
+		t.Errorf("VerifyAndChangeEmailCode() = %#v; want = %#v", code, testActionCode)
+	}
+
+	want := map[string]interface{}{
+		"requestType":   "VERIFY_AND_CHANGE_EMAIL",
+		"email":         testEmail,
+		"returnOobLink": true,
+		"newEmail":      testNewEmail,
+	}
+	if err := checkActionLinkRequest(want, s); err != nil {
+		t.Errorf("VerifyAndChangeEmailCode() %v", err)
+	}
+
+	if _, err := s.Client.VerifyAndChangeEmailCode(context.Background(), testEmail, "", nil); err == nil {
+		t.Errorf("VerifyAndChangeEmailCode('') = nil; want = error")
+	}
+}
+
+func TestEmailActionCodeInvalidSettings(t *testing.T) {
+	client := &Client{
+		baseClient: &baseClient{},
+	}
+	for _, tc := range invalidActionCodeSettings {
+		code, err := client.EmailVerificationCode(context.Background(), testEmail, tc.settings)
+		if code != nil || err == nil || err.Error() != tc.want {
+			t.Errorf("EmailVerificationCode(%q) = (%v, %v); want = (nil, %q)", tc.name, code, err, tc.want)
+		}
+	}
+}
+
+func TestCheckActionCode(t *testing.T) {
+	resp := `{
+		"email": "user@domain.com",
+		"newEmail": "user-new@domain.com",
+		"requestType": "VERIFY_AND_CHANGE_EMAIL"
+	}`
+	s := echoServer([]byte(resp), t)
+	defer s.Close()
+
+	info, err := s.Client.CheckActionCode(context.Background(), "test-oob-code")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &ActionCodeInfo{
+		Operation: ActionCodeOperationVerifyAndChangeEmail,
+		Email:     testEmail,
+		NewEmail:  testNewEmail,
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("CheckActionCode() = %#v; want = %#v", info, want)
+	}
+	checkActionCodeRequest(t, s, "/accounts:resetPassword", map[string]interface{}{
+		"oobCode": "test-oob-code",
+	})
+}
+
+func TestApplyActionCode(t *testing.T) {
+	s := echoServer([]byte(`{"localId": "testuser"}`), t)
+	defer s.Close()
+
+	if err := s.Client.ApplyActionCode(context.Background(), "test-oob-code"); err != nil {
+		t.Fatal(err)
+	}
+	checkActionCodeRequest(t, s, "/projects/mock-project-id/accounts:update", map[string]interface{}{
+		"oobCode": "test-oob-code",
+	})
+}
+
+func TestConfirmPasswordReset(t *testing.T) {
+	s := echoServer([]byte(`{"email": "user@domain.com"}`), t)
+	defer s.Close()
+
+	if err := s.Client.ConfirmPasswordReset(context.Background(), "test-oob-code", "newpassword"); err != nil {
+		t.Fatal(err)
+	}
+	checkActionCodeRequest(t, s, "/accounts:resetPassword", map[string]interface{}{
+		"oobCode":     "test-oob-code",
+		"newPassword": "newpassword",
+	})
+}
+
+func TestTenantApplyActionCode(t *testing.T) {
+	s := echoServer([]byte(`{}`), t)
+	defer s.Close()
+
+	client, err := s.Client.TenantManager.AuthForTenant("tenantID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ApplyActionCode(context.Background(), "test-oob-code"); err != nil {
+		t.Fatal(err)
+	}
+	checkActionCodeRequest(t, s, "/projects/mock-project-id/tenants/tenantID/accounts:update", map[string]interface{}{
+		"oobCode": "test-oob-code",
+	})
+}
+
+func TestTenantResetPasswordOperations(t *testing.T) {
+	s := echoServer([]byte(`{"email": "user@domain.com", "requestType": "PASSWORD_RESET"}`), t)
+	defer s.Close()
+
+	client, err := s.Client.TenantManager.AuthForTenant("tenantID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := client.CheckActionCode(context.Background(), "test-oob-code")
+	if err != nil || info.Operation != ActionCodeOperationPasswordReset {
+		t.Errorf("CheckActionCode() = (%v, %v); want = PASSWORD_RESET", info, err)
+	}
+	checkActionCodeRequest(t, s, "/accounts:resetPassword", map[string]interface{}{
+		"oobCode":  "test-oob-code",
+		"tenantId": "tenantID",
+	})
+
+	s.Req = nil
+	if err := client.ConfirmPasswordReset(context.Background(), "test-oob-code", "newpassword"); err != nil {
+		t.Fatal(err)
+	}
+	checkActionCodeRequest(t, s, "/accounts:resetPassword", map[string]interface{}{
+		"oobCode":     "test-oob-code",
+		"newPassword": "newpassword",
+		"tenantId":    "tenantID",
+	})
+}
+
+func TestActionCodeOperationsInvalidArgs(t *testing.T) {
+	client := &Client{
+		baseClient: &baseClient{},
+	}
+	ctx := context.Background()
+	if _, err := client.CheckActionCode(ctx, ""); err == nil {
+		t.Errorf("CheckActionCode('') = nil; want = error")
+	}
+	if err := client.ApplyActionCode(ctx, ""); err == nil {
+		t.Errorf("ApplyActionCode('') = nil; want = error")
+	}
+	if err := client.ConfirmPasswordReset(ctx, "", "newpassword"); err == nil {
+		t.Errorf("ConfirmPasswordReset('', password) = nil; want = error")
+	}
+	if err := client.ConfirmPasswordReset(ctx, "test-oob-code", "short"); err == nil {
+		t.Errorf("ConfirmPasswordReset(code, 'short') = nil; want = error")
+	}
+}
+
+func TestActionCodeOperationsError(t *testing.T) {
+	cases := map[string]func(error) bool{
+		"EXPIRED_OOB_CODE": IsExpiredActionCode,
+		"INVALID_OOB_CODE": IsInvalidActionCode,
+	}
+	s := echoServer(nil, t)
+	defer s.Close()
+	s.Client.baseClient.httpClient.RetryConfig = nil
+	s.Status = http.StatusBadRequest
+
+	for code, check := range cases {
+		s.Resp = []byte(fmt.Sprintf(`{"error": {"message": %q}}`, code))
+		if _, err := s.Client.CheckActionCode(context.Background(), "test-oob-code"); !check(err) {
+			t.Errorf("CheckActionCode(%q) = %v; want = %q", code, err, serverError[code].message)
+		}
+		if err := s.Client.ApplyActionCode(context.Background(), "test-oob-code"); !check(err) {
+			t.Errorf("ApplyActionCode(%q) = %v; want = %q", code, err, serverError[code].message)
+		}
+		if err := s.Client.ConfirmPasswordReset(context.Background(), "test-oob-code", "newpassword"); !check(err) {
+			t.Errorf("ConfirmPasswordReset(%q) = %v; want = %q", code, err, serverError[code].message)
+		}
+	}
+}
+
+func checkActionCodeRequest(t *testing.T, s *mockAuthServer, path string, want map[string]interface{}) {
+	req := s.Req[0]
+	if req.Method != http.MethodPost || req.URL.Path != path {
+		t.Errorf("Request = %s %s; want = POST %s", req.Method, req.URL.Path, path)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(s.Rbody, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Body = %#v; want = %#v", got, want)
+	}
+}
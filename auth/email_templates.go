@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// EmailTemplate is the template of an email action message in a single language.
+//
+// Subject is rendered as a text/template, and Body as an html/template, so that the values
+// substituted into the body are escaped. Both are executed with an EmailTemplateData.
+type EmailTemplate struct {
+	Subject string
+	Body    string
+}
+
+// EmailTemplateData is the data that email templates are rendered with.
+type EmailTemplateData struct {
+	// The action code to be sent. Templates typically reference {{.ActionCode.Link}}.
+	ActionCode  *ActionCode
+	Email       string
+	NewEmail    string
+	DisplayName string
+	AppName     string
+}
+
+// EmailTemplates holds the localized templates of an email action message.
+//
+// Templates are keyed by language code, such as "en" or "pt-BR".
+type EmailTemplates struct {
+	// The language to fall back to when there is no template for the requested language.
+	DefaultLanguage string
+	Templates       map[string]*EmailTemplate
+}
+
+// Render renders the template that best matches the given language code, and returns the subject
+// and the body of the message.
+//
+// If lang is empty, the language code of the action code is used instead. The template for the
+// exact language code is preferred, followed by the template for its base language (e.g. "pt" for
+// "pt-BR"), and finally the template for DefaultLanguage. An error is returned if the rendered
+// subject contains a line break, which could be used to inject headers into the message.
+func (t *EmailTemplates) Render(lang string, data *EmailTemplateData) (string, string, error) {
+	if data == nil || data.ActionCode == nil {
+		return "", "", fmt.Errorf("email template data must include an action code")
+	}
+	if lang == "" {
+		lang = data.ActionCode.LanguageCode
+	}
+
+	tmpl, lang := t.lookup(lang)
+	if tmpl == nil {
+		return "", "", fmt.Errorf("no email template found for language %q", lang)
+	}
+
+	subject, err := texttemplate.New("subject").Parse(tmpl.Subject)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid subject template for language %q: %v", lang, err)
+	}
+	body, err := htmltemplate.New("body").Parse(tmpl.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid body template for language %q: %v", lang, err)
+	}
+
+	var subjectBuf, bodyBuf bytes.Buffer
+	if err := subject.Execute(&subjectBuf, data); err != nil {
+		return "", "", fmt.Errorf("failed to render subject for language %q: %v", lang, err)
+	}
+	if strings.ContainsAny(subjectBuf.String(), "\r\n") {
+		return "", "", fmt.Errorf("rendered subject for language %q must not contain line breaks", lang)
+	}
+	if err := body.Execute(&bodyBuf, data); err != nil {
+		return "", "", fmt.Errorf("failed to render body for language %q: %v", lang, err)
+	}
+	return subjectBuf.String(), bodyBuf.String(), nil
+}
+
+func (t *EmailTemplates) lookup(lang string) (*EmailTemplate, string) {
+	candidates := []string{lang}
+	if idx := strings.IndexAny(lang, "-_"); idx != -1 {
+		candidates = append(candidates, lang[:idx])
+	}
+	candidates = append(candidates, t.DefaultLanguage)
+
+	for _, c := range candidates {
+		if tmpl, ok := t.Templates[c]; ok && tmpl != nil {
+			return tmpl, c
+		}
+	}
+	return nil, lang
+}
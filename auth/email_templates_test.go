@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+)
+
+var testEmailTemplates = &EmailTemplates{
+	DefaultLanguage: "en",
+	Templates: map[string]*EmailTemplate{
+		"en": {
+			Subject: "Verify your email for {{.AppName}}",
+			Body:    `<p>Hello {{.DisplayName}},</p><a href="{{.ActionCode.Link}}">Verify {{.Email}}</a>`,
+		},
+		"fr": {
+			Subject: "Vérifiez votre adresse e-mail pour {{.AppName}}",
+			Body:    `<p>Bonjour {{.DisplayName}},</p><a href="{{.ActionCode.Link}}">Vérifier {{.Email}}</a>`,
+		},
+		"pt-BR": {
+			Subject: "Verifique seu e-mail para {{.AppName}}",
+			Body:    `<p>Olá {{.DisplayName}}</p>`,
+		},
+	},
+}
+
+func TestEmailTemplatesRender(t *testing.T) {
+	data := &EmailTemplateData{
+		ActionCode:  &ActionCode{Link: "https://example.com/action?oobCode=abc&mode=verifyEmail", LanguageCode: "fr"},
+		Email:       testEmail,
+		DisplayName: "<Jane>",
+		AppName:     "Example",
+	}
+	cases := []struct {
+		name    string
+		lang    string
+		subject string
+		body    string
+	}{
+		{
+			"ActionCodeLanguage",
+			"",
+			"Vérifiez votre adresse e-mail pour Example",
+			`<p>Bonjour &lt;Jane&gt;,</p><a href="https://example.com/action?oobCode=abc&amp;mode=verifyEmail">` +
+				`Vérifier user@domain.com</a>`,
+		},
+		{
+			"BaseLanguage",
+			"fr-CA",
+			"Vérifiez votre adresse e-mail pour Example",
+			`<p>Bonjour &lt;Jane&gt;,</p><a href="https://example.com/action?oobCode=abc&amp;mode=verifyEmail">` +
+				`Vérifier user@domain.com</a>`,
+		},
+		{
+			"ExactLanguage",
+			"pt-BR",
+			"Verifique seu e-mail para Example",
+			`<p>Olá &lt;Jane&gt;</p>`,
+		},
+		{
+			"DefaultLanguage",
+			"de",
+			"Verify your email for Example",
+			`<p>Hello &lt;Jane&gt;,</p><a href="https://example.com/action?oobCode=abc&amp;mode=verifyEmail">` +
+				`Verify user@domain.com</a>`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			subject, body, err := testEmailTemplates.Render(tc.lang, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if subject != tc.subject || body != tc.body {
+				t.Errorf("Render(%q) = (%q, %q); want = (%q, %q)", tc.lang, subject, body, tc.subject, tc.body)
+			}
+		})
+	}
+}
+
+func TestEmailTemplatesRenderError(t *testing.T) {
+	data := &EmailTemplateData{ActionCode: &ActionCode{}}
+	cases := []struct {
+		name      string
+		templates *EmailTemplates
+		data      *EmailTemplateData
+		want      string
+	}{
+		{
+			"NoData",
+			testEmailTemplates,
+			nil,
+			"email template data must include an action code",
+		},
+		{
+			"NoActionCode",
+			testEmailTemplates,
+			&EmailTemplateData{},
+			"email template data must include an action code",
+		},
+		{
+			"NoTemplate",
+			&EmailTemplates{},
+			data,
+			`no email template found for language "en"`,
+		},
+		{
+			"InvalidSubject",
+			&EmailTemplates{Templates: map[string]*EmailTemplate{"en": {Subject: "{{"}}},
+			data,
+			`invalid subject template for language "en": template: subject:1: unclosed action`,
+		},
+		{
+			"SubjectHeaderInjection",
+			&EmailTemplates{Templates: map[string]*EmailTemplate{"en": {Subject: "Hello {{.DisplayName}}"}}},
+			&EmailTemplateData{ActionCode: &ActionCode{}, DisplayName: "Bob\r\nBcc: attacker@example.com"},
+			`rendered subject for language "en" must not contain line breaks`,
+		},
+		{
+			"SubjectNewline",
+			&EmailTemplates{Templates: map[string]*EmailTemplate{"en": {Subject: "Hello\n{{.DisplayName}}"}}},
+			data,
+			`rendered subject for language "en" must not contain line breaks`,
+		},
+		{
+			"InvalidBody",
+			&EmailTemplates{Templates: map[string]*EmailTemplate{"en": {Body: "{{.Unknown}}"}}},
+			data,
+			`failed to render body for language "en": template: body:1:2: executing "body" at <.Unknown>: ` +
+				`can't evaluate field Unknown in type *auth.EmailTemplateData`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := tc.templates.Render("en", tc.data)
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("Render() = %v; want = %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -15,6 +15,9 @@
 // Package hash contains a collection of password hash algorithms that can be used with the
 // auth.ImportUsers() API. Refer to https://firebase.google.com/docs/auth/admin/import-users for
 // more details about supported hash algorithms.
+//
+// Each hash algorithm also implements the Verifier interface, which can be used to check a password
+// against an existing password hash locally.
 package hash
 
 import (
@@ -162,6 +165,8 @@ func (h HMACSHA512) Config() (internal.HashConfig, error) {
 
 // MD5 represents the MD5 hash algorithm.
 //
+// Salted MD5 hashes are supported by setting InputOrder to specify whether the salt is prepended
+// or appended to the password. Keyed MD5 hashes are supported by HMACMD5.
 // Rounds must be between 0 and 8192.
 // Refer to https://firebase.google.com/docs/auth/admin/import-users#import_users_with_md5_sha_and_pbkdf_hashed_passwords
 // for more details.
@@ -189,6 +194,20 @@ func (h PBKDF2SHA256) Config() (internal.HashConfig, error) {
 	return basicConfig("PBKDF2_SHA256", h.Rounds, InputOrderUnspecified)
 }
 
+// PBKDF2SHA512 represents the PBKDF2SHA512 hash algorithm.
+//
+// Rounds must be between 0 and 120000.
+// Refer to https://firebase.google.com/docs/auth/admin/import-users#import_users_with_md5_sha_and_pbkdf_hashed_passwords
+// for more details.
+type PBKDF2SHA512 struct {
+	Rounds int
+}
+
+// Config returns the validated hash configuration.
+func (h PBKDF2SHA512) Config() (internal.HashConfig, error) {
+	return basicConfig("PBKDF2_SHA512", h.Rounds, InputOrderUnspecified)
+}
+
 // PBKDFSHA1 represents the PBKDFSHA1 hash algorithm.
 //
 // Rounds must be between 0 and 120000.
@@ -248,6 +267,91 @@ func (h SHA512) Config() (internal.HashConfig, error) {
 	return basicConfig("SHA512", h.Rounds, h.InputOrder)
 }
 
+// Argon2HashType specifies the variant of the Argon2 hash algorithm.
+type Argon2HashType int
+
+// Available Argon2HashType values
+const (
+	Argon2HashTypeUnspecified Argon2HashType = iota
+	Argon2d
+	Argon2i
+	Argon2id
+)
+
+// Argon2Version specifies the version of the Argon2 hash algorithm.
+type Argon2Version int
+
+// Available Argon2Version values
+const (
+	Argon2VersionUnspecified Argon2Version = iota
+	Argon2Version10
+	Argon2Version13
+)
+
+// Argon2 represents the Argon2 hash algorithm.
+//
+// HashType is required. HashLengthBytes must be between 4 and 1024, Iterations and Parallelism
+// must be between 1 and 16, and MemoryCostKiB must be between 1 and 32768. Version defaults to
+// Argon2Version13 when not specified.
+type Argon2 struct {
+	HashType        Argon2HashType
+	HashLengthBytes int
+	Iterations      int
+	MemoryCostKiB   int
+	Parallelism     int
+	Version         Argon2Version
+	AssociatedData  []byte
+}
+
+// Config returns the validated hash configuration.
+func (a Argon2) Config() (internal.HashConfig, error) {
+	params := make(map[string]interface{})
+	switch a.HashType {
+	case Argon2d:
+		params["hashType"] = "ARGON2_D"
+	case Argon2i:
+		params["hashType"] = "ARGON2_I"
+	case Argon2id:
+		params["hashType"] = "ARGON2_ID"
+	default:
+		return nil, errors.New("argon2 hash type not specified")
+	}
+	if a.HashLengthBytes < 4 || a.HashLengthBytes > 1024 {
+		return nil, errors.New("hash length must be between 4 and 1024 bytes")
+	}
+	if a.Iterations < 1 || a.Iterations > 16 {
+		return nil, errors.New("iterations must be between 1 and 16")
+	}
+	if a.MemoryCostKiB < 1 || a.MemoryCostKiB > 32768 {
+		return nil, errors.New("memory cost must be between 1 and 32768 KiB")
+	}
+	if a.Parallelism < 1 || a.Parallelism > 16 {
+		return nil, errors.New("parallelism must be between 1 and 16")
+	}
+	params["hashLengthBytes"] = a.HashLengthBytes
+	params["iterations"] = a.Iterations
+	params["memoryCostKib"] = a.MemoryCostKiB
+	params["parallelism"] = a.Parallelism
+
+	switch a.Version {
+	case Argon2VersionUnspecified:
+	case Argon2Version10:
+		params["version"] = "VERSION_10"
+	case Argon2Version13:
+		params["version"] = "VERSION_13"
+	default:
+		return nil, fmt.Errorf("unknown argon2 version: %d", a.Version)
+	}
+	if len(a.AssociatedData) > 0 {
+		params["associatedData"] = base64.RawURLEncoding.EncodeToString(a.AssociatedData)
+	}
+
+	return internal.HashConfig{
+		"hashAlgorithm":    "ARGON2",
+		"argon2Parameters": params,
+	}, nil
+}
+
 func hmacConfig(name string, key []byte, order InputOrderType) (internal.HashConfig, error) {
 	if len(key) == 0 {
 		return nil, errors.New("signer key not specified")
@@ -178,6 +178,83 @@ var validHashes = []struct {
 			"rounds":        120000,
 		},
 	},
+	{
+		alg: PBKDF2SHA512{Rounds: 0},
+		want: internal.HashConfig{
+			"hashAlgorithm": "PBKDF2_SHA512",
+			"rounds":        0,
+		},
+	},
+	{
+		alg: PBKDF2SHA512{Rounds: 120000},
+		want: internal.HashConfig{
+			"hashAlgorithm": "PBKDF2_SHA512",
+			"rounds":        120000,
+		},
+	},
+	{
+		alg: Argon2{
+			HashType:        Argon2id,
+			HashLengthBytes: 32,
+			Iterations:      3,
+			MemoryCostKiB:   4096,
+			Parallelism:     1,
+		},
+		want: internal.HashConfig{
+			"hashAlgorithm": "ARGON2",
+			"argon2Parameters": map[string]interface{}{
+				"hashType":        "ARGON2_ID",
+				"hashLengthBytes": 32,
+				"iterations":      3,
+				"memoryCostKib":   4096,
+				"parallelism":     1,
+			},
+		},
+	},
+	{
+		alg: Argon2{
+			HashType:        Argon2d,
+			HashLengthBytes: 1024,
+			Iterations:      16,
+			MemoryCostKiB:   32768,
+			Parallelism:     16,
+			Version:         Argon2Version10,
+			AssociatedData:  []byte("data"),
+		},
+		want: internal.HashConfig{
+			"hashAlgorithm": "ARGON2",
+			"argon2Parameters": map[string]interface{}{
+				"hashType":        "ARGON2_D",
+				"hashLengthBytes": 1024,
+				"iterations":      16,
+				"memoryCostKib":   32768,
+				"parallelism":     16,
+				"version":         "VERSION_10",
+				"associatedData":  base64.RawURLEncoding.EncodeToString([]byte("data")),
+			},
+		},
+	},
+	{
+		alg: Argon2{
+			HashType:        Argon2i,
+			HashLengthBytes: 4,
+			Iterations:      1,
+			MemoryCostKiB:   1,
+			Parallelism:     1,
+			Version:         Argon2Version13,
+		},
+		want: internal.HashConfig{
+			"hashAlgorithm": "ARGON2",
+			"argon2Parameters": map[string]interface{}{
+				"hashType":        "ARGON2_I",
+				"hashLengthBytes": 4,
+				"iterations":      1,
+				"memoryCostKib":   1,
+				"parallelism":     1,
+				"version":         "VERSION_13",
+			},
+		},
+	},
 }
 
 var invalidHashes = []struct {
@@ -290,6 +367,57 @@ var invalidHashes = []struct {
 		name: "PBKDF2SHA256: rounds too high",
 		alg:  PBKDF2SHA256{Rounds: 120001},
 	},
+	{
+		name: "PBKDF2SHA512: rounds too low",
+		alg:  PBKDF2SHA512{Rounds: -1},
+	},
+	{
+		name: "PBKDF2SHA512: rounds too high",
+		alg:  PBKDF2SHA512{Rounds: 120001},
+	},
+	{
+		name: "ARGON2: no hash type",
+		alg:  Argon2{HashLengthBytes: 32, Iterations: 3, MemoryCostKiB: 4096, Parallelism: 1},
+	},
+	{
+		name: "ARGON2: hash length too low",
+		alg:  Argon2{HashType: Argon2id, HashLengthBytes: 3, Iterations: 3, MemoryCostKiB: 4096, Parallelism: 1},
+	},
+	{
+		name: "ARGON2: hash length too high",
+		alg:  Argon2{HashType: Argon2id, HashLengthBytes: 1025, Iterations: 3, MemoryCostKiB: 4096, Parallelism: 1},
+	},
+	{
+		name: "ARGON2: iterations too low",
+		alg:  Argon2{HashType: Argon2id, HashLengthBytes: 32, MemoryCostKiB: 4096, Parallelism: 1},
+	},
+	{
+		name: "ARGON2: iterations too high",
+		alg:  Argon2{HashType: Argon2id, HashLengthBytes: 32, Iterations: 17, MemoryCostKiB: 4096, Parallelism: 1},
+	},
+	{
+		name: "ARGON2: memory cost too low",
+		alg:  Argon2{HashType: Argon2id, HashLengthBytes: 32, Iterations: 3, Parallelism: 1},
+	},
+	{
+		name: "ARGON2: memory cost too high",
+		alg:  Argon2{HashType: Argon2id, HashLengthBytes: 32, Iterations: 3, MemoryCostKiB: 32769, Parallelism: 1},
+	},
+	{
+		name: "ARGON2: parallelism too low",
+		alg:  Argon2{HashType: Argon2id, HashLengthBytes: 32, Iterations: 3, MemoryCostKiB: 4096},
+	},
+	{
+		name: "ARGON2: parallelism too high",
+		alg:  Argon2{HashType: Argon2id, HashLengthBytes: 32, Iterations: 3, MemoryCostKiB: 4096, Parallelism: 17},
+	},
+	{
+		name: "ARGON2: unknown version",
+		alg: Argon2{
+			HashType: Argon2id, HashLengthBytes: 32, Iterations: 3, MemoryCostKiB: 4096, Parallelism: 1,
+			Version: Argon2Version(99),
+		},
+	},
 }
 
 func TestValidHash(t *testing.T) {
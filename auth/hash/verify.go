@@ -0,0 +1,250 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hash
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"hash"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Verifier verifies passwords against hashes computed with a password hash algorithm.
+//
+// All the hash algorithms in this package implement Verifier. This makes it possible to check a
+// candidate password against a legacy password hash locally, before the user is imported.
+type Verifier interface {
+	// Verify checks if the given password matches the given hash and salt. It returns an error
+	// if the hash configuration is invalid, or if the hash cannot be verified.
+	Verify(password, hash, salt []byte) (bool, error)
+}
+
+// Verify checks if the given password matches the given bcrypt hash. The salt is encoded in the
+// bcrypt hash, and therefore the salt argument is ignored.
+func (b Bcrypt) Verify(password, hash, salt []byte) (bool, error) {
+	err := bcrypt.CompareHashAndPassword(hash, password)
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Verify checks if the given password matches the given standard scrypt hash and salt.
+func (s StandardScrypt) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := s.Config(); err != nil {
+		return false, err
+	}
+	key, err := scrypt.Key(password, salt, s.MemoryCost, s.BlockSize, s.Parallelization, s.DerivedKeyLength)
+	if err != nil {
+		return false, err
+	}
+	return equal(key, hash), nil
+}
+
+// Verify checks if the given password matches the given Firebase scrypt hash and salt.
+//
+// The password is first hashed with scrypt using the salt followed by the salt separator, and
+// the resulting key is then used to encrypt the signer key with AES-256 in CTR mode.
+func (s Scrypt) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := s.Config(); err != nil {
+		return false, err
+	}
+
+	saltAndSep := make([]byte, 0, len(salt)+len(s.SaltSeparator))
+	saltAndSep = append(saltAndSep, salt...)
+	saltAndSep = append(saltAndSep, s.SaltSeparator...)
+	key, err := scrypt.Key(password, saltAndSep, 1<<uint(s.MemoryCost), s.Rounds, 1, 32)
+	if err != nil {
+		return false, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return false, err
+	}
+	derived := make([]byte, len(s.Key))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(derived, s.Key)
+	return equal(derived, hash), nil
+}
+
+// Verify checks if the given password matches the given HMAC MD5 hash and salt.
+func (h HMACMD5) Verify(password, hash, salt []byte) (bool, error) {
+	return verifyHMAC(md5.New, h.Key, h.InputOrder, password, hash, salt)
+}
+
+// Verify checks if the given password matches the given HMAC SHA1 hash and salt.
+func (h HMACSHA1) Verify(password, hash, salt []byte) (bool, error) {
+	return verifyHMAC(sha1.New, h.Key, h.InputOrder, password, hash, salt)
+}
+
+// Verify checks if the given password matches the given HMAC SHA256 hash and salt.
+func (h HMACSHA256) Verify(password, hash, salt []byte) (bool, error) {
+	return verifyHMAC(sha256.New, h.Key, h.InputOrder, password, hash, salt)
+}
+
+// Verify checks if the given password matches the given HMAC SHA512 hash and salt.
+func (h HMACSHA512) Verify(password, hash, salt []byte) (bool, error) {
+	return verifyHMAC(sha512.New, h.Key, h.InputOrder, password, hash, salt)
+}
+
+// Verify checks if the given password matches the given MD5 hash and salt. Zero rounds are
+// treated the same as a single round.
+func (h MD5) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := h.Config(); err != nil {
+		return false, err
+	}
+	return verifyDigest(md5.New, h.Rounds, h.InputOrder, password, hash, salt), nil
+}
+
+// Verify checks if the given password matches the given SHA1 hash and salt.
+func (h SHA1) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := h.Config(); err != nil {
+		return false, err
+	}
+	return verifyDigest(sha1.New, h.Rounds, h.InputOrder, password, hash, salt), nil
+}
+
+// Verify checks if the given password matches the given SHA256 hash and salt.
+func (h SHA256) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := h.Config(); err != nil {
+		return false, err
+	}
+	return verifyDigest(sha256.New, h.Rounds, h.InputOrder, password, hash, salt), nil
+}
+
+// Verify checks if the given password matches the given SHA512 hash and salt.
+func (h SHA512) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := h.Config(); err != nil {
+		return false, err
+	}
+	return verifyDigest(sha512.New, h.Rounds, h.InputOrder, password, hash, salt), nil
+}
+
+// Verify checks if the given password matches the given PBKDF2 SHA256 hash and salt. The length
+// of the derived key is inferred from the length of the hash.
+func (h PBKDF2SHA256) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := h.Config(); err != nil {
+		return false, err
+	}
+	return verifyPBKDF2(sha256.New, h.Rounds, password, hash, salt)
+}
+
+// Verify checks if the given password matches the given PBKDF SHA1 hash and salt. The length of
+// the derived key is inferred from the length of the hash.
+func (h PBKDFSHA1) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := h.Config(); err != nil {
+		return false, err
+	}
+	return verifyPBKDF2(sha1.New, h.Rounds, password, hash, salt)
+}
+
+// Verify checks if the given password matches the given PBKDF2 SHA512 hash and salt. The length
+// of the derived key is inferred from the length of the hash.
+func (h PBKDF2SHA512) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := h.Config(); err != nil {
+		return false, err
+	}
+	return verifyPBKDF2(sha512.New, h.Rounds, password, hash, salt)
+}
+
+// Verify checks if the given password matches the given Argon2 hash and salt.
+//
+// Only the Argon2i and Argon2id variants of Argon2 version 1.3 without associated data can be
+// verified locally. An error is returned for all other configurations.
+func (a Argon2) Verify(password, hash, salt []byte) (bool, error) {
+	if _, err := a.Config(); err != nil {
+		return false, err
+	}
+	if a.Version == Argon2Version10 || len(a.AssociatedData) > 0 {
+		return false, errors.New("argon2 version 1.0 and associated data are not supported for verification")
+	}
+
+	var key []byte
+	switch a.HashType {
+	case Argon2i:
+		key = argon2.Key(password, salt, uint32(a.Iterations), uint32(a.MemoryCostKiB),
+			uint8(a.Parallelism), uint32(a.HashLengthBytes))
+	case Argon2id:
+		key = argon2.IDKey(password, salt, uint32(a.Iterations), uint32(a.MemoryCostKiB),
+			uint8(a.Parallelism), uint32(a.HashLengthBytes))
+	default:
+		return false, errors.New("argon2d is not supported for verification")
+	}
+	return equal(key, hash), nil
+}
+
+// saltedInput concatenates the password and the salt in the given order. When the order is not
+// specified, the salt comes first.
+func saltedInput(order InputOrderType, password, salt []byte) []byte {
+	input := make([]byte, 0, len(password)+len(salt))
+	if order == InputOrderPasswordFirst {
+		input = append(input, password...)
+		return append(input, salt...)
+	}
+	input = append(input, salt...)
+	return append(input, password...)
+}
+
+func verifyHMAC(
+	fn func() hash.Hash, key []byte, order InputOrderType, password, want, salt []byte) (bool, error) {
+
+	if len(key) == 0 {
+		return false, errors.New("signer key not specified")
+	}
+	mac := hmac.New(fn, key)
+	mac.Write(saltedInput(order, password, salt))
+	return equal(mac.Sum(nil), want), nil
+}
+
+func verifyDigest(
+	fn func() hash.Hash, rounds int, order InputOrderType, password, want, salt []byte) bool {
+
+	h := fn()
+	h.Write(saltedInput(order, password, salt))
+	sum := h.Sum(nil)
+	for i := 1; i < rounds; i++ {
+		h.Reset()
+		h.Write(sum)
+		sum = h.Sum(sum[:0])
+	}
+	return equal(sum, want)
+}
+
+func verifyPBKDF2(fn func() hash.Hash, rounds int, password, want, salt []byte) (bool, error) {
+	if rounds < 1 {
+		return false, errors.New("rounds must be at least 1 to verify a pbkdf2 hash")
+	}
+	if len(want) == 0 {
+		return false, nil
+	}
+	return equal(pbkdf2.Key(password, salt, rounds, len(want), fn), want), nil
+}
+
+func equal(got, want []byte) bool {
+	return len(want) > 0 && subtle.ConstantTimeCompare(got, want) == 1
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hash
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+var verifyVectors = []struct {
+	name     string
+	alg      Verifier
+	password string
+	hash     []byte
+	salt     []byte
+}{
+	{
+		// Test vector from https://www.openwall.com/john/
+		name:     "Bcrypt",
+		alg:      Bcrypt{},
+		password: "U*U",
+		hash:     []byte("$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"),
+	},
+	{
+		// Test vector from RFC 7914, section 12
+		name:     "StandardScrypt",
+		alg:      StandardScrypt{MemoryCost: 1024, BlockSize: 8, Parallelization: 16, DerivedKeyLength: 64},
+		password: "password",
+		hash: fromHex("fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+			"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"),
+		salt: []byte("NaCl"),
+	},
+	{
+		// Test vector from https://github.com/firebase/scrypt
+		name: "Scrypt",
+		alg: Scrypt{
+			Key:           fromBase64("jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA=="),
+			SaltSeparator: fromBase64("Bw=="),
+			Rounds:        8,
+			MemoryCost:    14,
+		},
+		password: "user1password",
+		hash:     fromBase64("lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="),
+		salt:     fromBase64("42xEC+ixf3L2lw=="),
+	},
+	{
+		name:     "HMACMD5",
+		alg:      HMACMD5{Key: []byte("key")},
+		password: "password",
+		hash:     fromHex("87fa9b9bd6ab84154a37e3e73d5c5de3"),
+		salt:     []byte("salt"),
+	},
+	{
+		name:     "HMACSHA1",
+		alg:      HMACSHA1{Key: []byte("key"), InputOrder: InputOrderPasswordFirst},
+		password: "password",
+		hash:     fromHex("dffcfe9227882ad4950786e58ff0e589a2f8d777"),
+		salt:     []byte("salt"),
+	},
+	{
+		name:     "HMACSHA256",
+		alg:      HMACSHA256{Key: []byte("key"), InputOrder: InputOrderSaltFirst},
+		password: "password",
+		hash:     fromHex("0ff6c926034541615482f3ec89ca759d75c5a65ad6b41bc2f45d92b448ee4c1c"),
+		salt:     []byte("salt"),
+	},
+	{
+		name:     "HMACSHA512",
+		alg:      HMACSHA512{Key: []byte("key"), InputOrder: InputOrderPasswordFirst},
+		password: "password",
+		hash: fromHex("2a2b9e8b02a617c5daf6cc89c5c7b7e25988b4e5b076b0663aa30b326f29cbac" +
+			"fc32d1df5e8a40bf7ea37a96d671a53ed69f6cfede98d783f7ffbf1abd05ae47"),
+		salt: []byte("salt"),
+	},
+	{
+		name:     "MD5",
+		alg:      MD5{},
+		password: "password",
+		hash:     fromHex("67a1e09bb1f83f5007dc119c14d663aa"),
+		salt:     []byte("salt"),
+	},
+	{
+		name:     "MD5Rounds",
+		alg:      MD5{Rounds: 3},
+		password: "password",
+		hash:     fromHex("dda5359be921db4f73a69223ec264c11"),
+		salt:     []byte("salt"),
+	},
+	{
+		name:     "SHA1",
+		alg:      SHA1{Rounds: 1, InputOrder: InputOrderPasswordFirst},
+		password: "password",
+		hash:     fromHex("c88e9c67041a74e0357befdff93f87dde0904214"),
+		salt:     []byte("salt"),
+	},
+	{
+		name:     "SHA256",
+		alg:      SHA256{Rounds: 3, InputOrder: InputOrderSaltFirst},
+		password: "password",
+		hash:     fromHex("36eb350705eb3263d8a43f78984cafd735d0cc6ee259798bffe10b4ec86bea4f"),
+		salt:     []byte("salt"),
+	},
+	{
+		name:     "SHA512",
+		alg:      SHA512{Rounds: 1},
+		password: "password",
+		hash: fromHex("2908d2c28dfc047741fc590a026ffade237ab2ba7e1266f010fe49bde548b598" +
+			"7a534a86655a0d17f336588e540cd66f67234b152bbb645b4bb85758a1325d64"),
+		salt: []byte("salt"),
+	},
+	{
+		// Test vector from RFC 6070
+		name:     "PBKDFSHA1",
+		alg:      PBKDFSHA1{Rounds: 4096},
+		password: "password",
+		hash:     fromHex("4b007901b765489abead49d926f721d065a429c1"),
+		salt:     []byte("salt"),
+	},
+	{
+		// Test vector from RFC 7914, section 11
+		name:     "PBKDF2SHA256",
+		alg:      PBKDF2SHA256{Rounds: 1},
+		password: "passwd",
+		hash: fromHex("55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+			"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"),
+		salt: []byte("salt"),
+	},
+	{
+		name:     "PBKDF2SHA512",
+		alg:      PBKDF2SHA512{Rounds: 1},
+		password: "password",
+		hash: fromHex("867f70cf1ade02cff3752599a3a53dc4af34c7a669815ae5d513554e1c8cf252" +
+			"c02d470a285a0501bad999bfe943c08f050235d7d68b1da55e63f73b60a57fce"),
+		salt: []byte("salt"),
+	},
+	{
+		// Test vector generated with the reference implementation (https://github.com/P-H-C/phc-winner-argon2)
+		name: "Argon2i",
+		alg: Argon2{
+			HashType:        Argon2i,
+			HashLengthBytes: 24,
+			Iterations:      2,
+			MemoryCostKiB:   64,
+			Parallelism:     2,
+		},
+		password: "password",
+		hash:     fromHex("2089f3e78a799720f80af806553128f29b132cafe40d059f"),
+		salt:     []byte("somesalt"),
+	},
+	{
+		// Test vector generated with the reference implementation (https://github.com/P-H-C/phc-winner-argon2)
+		name: "Argon2id",
+		alg: Argon2{
+			HashType:        Argon2id,
+			HashLengthBytes: 24,
+			Iterations:      2,
+			MemoryCostKiB:   64,
+			Parallelism:     1,
+			Version:         Argon2Version13,
+		},
+		password: "password",
+		hash:     fromHex("068d62b26455936aa6ebe60060b0a65870dbfa3ddf8d41f7"),
+		salt:     []byte("somesalt"),
+	},
+}
+
+func TestVerify(t *testing.T) {
+	for _, tc := range verifyVectors {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, err := tc.alg.Verify([]byte(tc.password), tc.hash, tc.salt)
+			if !ok || err != nil {
+				t.Errorf("Verify(%q) = (%v, %v); want = (true, nil)", tc.password, ok, err)
+			}
+
+			ok, err = tc.alg.Verify([]byte(tc.password+"x"), tc.hash, tc.salt)
+			if ok || err != nil {
+				t.Errorf("Verify(wrong password) = (%v, %v); want = (false, nil)", ok, err)
+			}
+		})
+	}
+}
+
+func TestVerifyWrongSalt(t *testing.T) {
+	for _, tc := range verifyVectors {
+		if tc.salt == nil {
+			continue
+		}
+		t.Run(tc.name, func(t *testing.T) {
+			ok, err := tc.alg.Verify([]byte(tc.password), tc.hash, append(tc.salt, 'x'))
+			if ok || err != nil {
+				t.Errorf("Verify(wrong salt) = (%v, %v); want = (false, nil)", ok, err)
+			}
+		})
+	}
+}
+
+func TestVerifyInputOrder(t *testing.T) {
+	hash := fromHex("67a1e09bb1f83f5007dc119c14d663aa")
+	cases := []struct {
+		order InputOrderType
+		want  bool
+	}{
+		{InputOrderUnspecified, true},
+		{InputOrderSaltFirst, true},
+		{InputOrderPasswordFirst, false},
+	}
+	for _, tc := range cases {
+		ok, err := MD5{InputOrder: tc.order}.Verify([]byte("password"), hash, []byte("salt"))
+		if ok != tc.want || err != nil {
+			t.Errorf("Verify(order = %d) = (%v, %v); want = (%v, nil)", tc.order, ok, err, tc.want)
+		}
+	}
+}
+
+func TestVerifyEmptyHash(t *testing.T) {
+	for _, tc := range verifyVectors {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, _ := tc.alg.Verify([]byte(tc.password), nil, tc.salt)
+			if ok {
+				t.Errorf("Verify(empty hash) = true; want = false")
+			}
+		})
+	}
+}
+
+func TestVerifyInvalidConfig(t *testing.T) {
+	cases := []struct {
+		name string
+		alg  Verifier
+	}{
+		{"BcryptMalformedHash", Bcrypt{}},
+		{"StandardScrypt", StandardScrypt{MemoryCost: 3, BlockSize: 1, Parallelization: 1, DerivedKeyLength: 32}},
+		{"ScryptNoKey", Scrypt{Rounds: 8, MemoryCost: 14}},
+		{"ScryptRounds", Scrypt{Key: []byte("key"), Rounds: 9, MemoryCost: 14}},
+		{"HMACNoKey", HMACSHA256{}},
+		{"MD5Rounds", MD5{Rounds: 8193}},
+		{"SHA256Rounds", SHA256{Rounds: 0}},
+		{"PBKDF2ZeroRounds", PBKDF2SHA256{Rounds: 0}},
+		{"PBKDFRounds", PBKDFSHA1{Rounds: 120001}},
+		{"PBKDF2SHA512Rounds", PBKDF2SHA512{Rounds: 120001}},
+		{"PBKDF2SHA512ZeroRounds", PBKDF2SHA512{}},
+		{"Argon2NoHashType", Argon2{HashLengthBytes: 32, Iterations: 1, MemoryCostKiB: 64, Parallelism: 1}},
+		{"Argon2d", Argon2{HashType: Argon2d, HashLengthBytes: 32, Iterations: 1, MemoryCostKiB: 64, Parallelism: 1}},
+		{"Argon2Version10", Argon2{
+			HashType: Argon2id, HashLengthBytes: 32, Iterations: 1, MemoryCostKiB: 64, Parallelism: 1,
+			Version: Argon2Version10,
+		}},
+		{"Argon2AssociatedData", Argon2{
+			HashType: Argon2id, HashLengthBytes: 32, Iterations: 1, MemoryCostKiB: 64, Parallelism: 1,
+			AssociatedData: []byte("data"),
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, err := tc.alg.Verify([]byte("password"), []byte("hash"), []byte("salt"))
+			if ok || err == nil {
+				t.Errorf("Verify() = (%v, %v); want = (false, error)", ok, err)
+			}
+		})
+	}
+}
+
+func fromHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func fromBase64(s string) []byte {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
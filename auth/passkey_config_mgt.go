@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"firebase.google.com/go/v4/internal"
+)
+
+const (
+	passkeyRPIDKey            = "rpId"
+	passkeyExpectedOriginsKey = "expectedOrigins"
+)
+
+// PasskeyConfig represents the passkey (WebAuthn) configuration of a project or a tenant.
+type PasskeyConfig struct {
+	// Name is the resource name of the passkey configuration.
+	Name string `json:"name,omitempty"`
+	// RPID is the relying party ID of the passkeys, usually the domain of the website.
+	RPID string `json:"rpId,omitempty"`
+	// ExpectedOrigins is the list of website or app origins associated with the passkeys.
+	ExpectedOrigins []string `json:"expectedOrigins,omitempty"`
+}
+
+// PasskeyConfigToUpdate represents the options used to update the passkey configuration.
+type PasskeyConfigToUpdate struct {
+	params nestedMap
+}
+
+// RPID sets the relying party ID of the passkeys.
+func (pc *PasskeyConfigToUpdate) RPID(rpID string) *PasskeyConfigToUpdate {
+	return pc.set(passkeyRPIDKey, rpID)
+}
+
+// ExpectedOrigins sets the website or app origins associated with the passkeys.
+func (pc *PasskeyConfigToUpdate) ExpectedOrigins(origins []string) *PasskeyConfigToUpdate {
+	return pc.set(passkeyExpectedOriginsKey, origins)
+}
+
+func (pc *PasskeyConfigToUpdate) set(key string, value interface{}) *PasskeyConfigToUpdate {
+	if pc.params == nil {
+		pc.params = make(nestedMap)
+	}
+	pc.params.Set(key, value)
+	return pc
+}
+
+func (pc *PasskeyConfigToUpdate) validate() error {
+	if val, ok := pc.params[passkeyRPIDKey]; ok && val.(string) == "" {
+		return errors.New("rpID must not be empty")
+	}
+	if val, ok := pc.params[passkeyExpectedOriginsKey]; ok {
+		origins := val.([]string)
+		if len(origins) == 0 {
+			return errors.New("expectedOrigins must not be empty")
+		}
+		for _, origin := range origins {
+			u, err := url.Parse(origin)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("expectedOrigins must only contain valid origins: %q", origin)
+			}
+		}
+	}
+	return nil
+}
+
+// GetPasskeyConfig returns the passkey configuration of the current project or tenant.
+func (c *baseClient) GetPasskeyConfig(ctx context.Context) (*PasskeyConfig, error) {
+	req := &internal.Request{
+		Method: http.MethodGet,
+		URL:    "/passkeyConfig",
+	}
+	var result PasskeyConfig
+	if _, err := c.makeRequest(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdatePasskeyConfig updates the passkey configuration of the current project or tenant.
+//
+// The relying party ID and the expected origins must be set before users can enroll passkeys.
+func (c *baseClient) UpdatePasskeyConfig(
+	ctx context.Context, config *PasskeyConfigToUpdate) (*PasskeyConfig, error) {
+	if config == nil {
+		return nil, errors.New("passkey config must not be nil")
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	mask := config.params.UpdateMask()
+	if len(mask) == 0 {
+		return nil, errors.New("no parameters specified in the update request")
+	}
+	req := &internal.Request{
+		Method: http.MethodPatch,
+		URL:    "/passkeyConfig",
+		Body:   internal.NewJSONEntity(config.params),
+		Opts: []internal.HTTPOption{
+			internal.WithQueryParam("updateMask", strings.Join(mask, ",")),
+		},
+	}
+	var result PasskeyConfig
+	if _, err := c.makeRequest(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
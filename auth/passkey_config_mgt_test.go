@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const passkeyConfigResponse = `{
+	"name": "projects/mock-project-id/passkeyConfig",
+	"rpId": "example.com",
+	"expectedOrigins": ["https://example.com", "https://app.example.com"]
+}`
+
+var testPasskeyConfig = &PasskeyConfig{
+	Name:            "projects/mock-project-id/passkeyConfig",
+	RPID:            "example.com",
+	ExpectedOrigins: []string{"https://example.com", "https://app.example.com"},
+}
+
+func TestGetPasskeyConfig(t *testing.T) {
+	s := echoServer([]byte(passkeyConfigResponse), t)
+	defer s.Close()
+
+	config, err := s.Client.GetPasskeyConfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, testPasskeyConfig) {
+		t.Errorf("GetPasskeyConfig() = %#v; want = %#v", config, testPasskeyConfig)
+	}
+
+	req := s.Req[0]
+	if req.Method != http.MethodGet {
+		t.Errorf("GetPasskeyConfig() Method = %q; want = %q", req.Method, http.MethodGet)
+	}
+	wantURL := "/projects/mock-project-id/passkeyConfig"
+	if req.URL.Path != wantURL {
+		t.Errorf("GetPasskeyConfig() URL = %q; want = %q", req.URL.Path, wantURL)
+	}
+}
+
+func TestGetPasskeyConfigForTenant(t *testing.T) {
+	s := echoServer([]byte(passkeyConfigResponse), t)
+	defer s.Close()
+
+	client, err := s.Client.TenantManager.AuthForTenant("tenantID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetPasskeyConfig(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	wantURL := "/projects/mock-project-id/tenants/tenantID/passkeyConfig"
+	if s.Req[0].URL.Path != wantURL {
+		t.Errorf("GetPasskeyConfig() URL = %q; want = %q", s.Req[0].URL.Path, wantURL)
+	}
+}
+
+func TestUpdatePasskeyConfig(t *testing.T) {
+	s := echoServer([]byte(passkeyConfigResponse), t)
+	defer s.Close()
+
+	options := (&PasskeyConfigToUpdate{}).
+		RPID("example.com").
+		ExpectedOrigins([]string{"https://example.com", "https://app.example.com"})
+	config, err := s.Client.UpdatePasskeyConfig(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, testPasskeyConfig) {
+		t.Errorf("UpdatePasskeyConfig() = %#v; want = %#v", config, testPasskeyConfig)
+	}
+
+	req := s.Req[0]
+	if req.Method != http.MethodPatch {
+		t.Errorf("UpdatePasskeyConfig() Method = %q; want = %q", req.Method, http.MethodPatch)
+	}
+	wantURL := "/projects/mock-project-id/passkeyConfig"
+	if req.URL.Path != wantURL {
+		t.Errorf("UpdatePasskeyConfig() URL = %q; want = %q", req.URL.Path, wantURL)
+	}
+	mask := strings.Split(req.URL.Query().Get("updateMask"), ",")
+	sort.Strings(mask)
+	wantMask := []string{"expectedOrigins", "rpId"}
+	if !reflect.DeepEqual(mask, wantMask) {
+		t.Errorf("UpdatePasskeyConfig() Mask = %v; want = %v", mask, wantMask)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(s.Rbody, &body); err != nil {
+		t.Fatal(err)
+	}
+	wantBody := map[string]interface{}{
+		"rpId":            "example.com",
+		"expectedOrigins": []interface{}{"https://example.com", "https://app.example.com"},
+	}
+	if !reflect.DeepEqual(body, wantBody) {
+		t.Errorf("UpdatePasskeyConfig() Body = %#v; want = %#v", body, wantBody)
+	}
+}
+
+func TestUpdatePasskeyConfigError(t *testing.T) {
+	cases := []struct {
+		config *PasskeyConfigToUpdate
+		want   string
+	}{
+		{nil, "passkey config must not be nil"},
+		{&PasskeyConfigToUpdate{}, "no parameters specified in the update request"},
+		{(&PasskeyConfigToUpdate{}).RPID(""), "rpID must not be empty"},
+		{(&PasskeyConfigToUpdate{}).ExpectedOrigins(nil), "expectedOrigins must not be empty"},
+		{
+			(&PasskeyConfigToUpdate{}).ExpectedOrigins([]string{"example.com"}),
+			`expectedOrigins must only contain valid origins: "example.com"`,
+		},
+	}
+	client := &baseClient{}
+	for _, tc := range cases {
+		_, err := client.UpdatePasskeyConfig(context.Background(), tc.config)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("UpdatePasskeyConfig() = %v; want = %q", err, tc.want)
+		}
+	}
+}
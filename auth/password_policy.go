@@ -0,0 +1,228 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"firebase.google.com/go/v4/internal"
+)
+
+const passwordRequirementsKey = "passwordRequirements"
+
+// PasswordRequirement identifies a single requirement of a password policy.
+type PasswordRequirement string
+
+// These constants represent the possible values for the PasswordRequirement type.
+const (
+	PasswordRequirementMinLength       PasswordRequirement = "MIN_LENGTH"
+	PasswordRequirementMaxLength       PasswordRequirement = "MAX_LENGTH"
+	PasswordRequirementUppercase       PasswordRequirement = "UPPERCASE_CHARACTER"
+	PasswordRequirementLowercase       PasswordRequirement = "LOWERCASE_CHARACTER"
+	PasswordRequirementNumeric         PasswordRequirement = "NUMERIC_CHARACTER"
+	PasswordRequirementNonAlphanumeric PasswordRequirement = "NON_ALPHANUMERIC_CHARACTER"
+)
+
+// PasswordPolicyViolation describes a password policy requirement that a password does not meet.
+type PasswordPolicyViolation struct {
+	Requirement PasswordRequirement
+	// A human readable description of the requirement.
+	Message string
+}
+
+// PasswordPolicy is the password policy in effect for a project or a tenant.
+//
+// PasswordPolicy can be used to check passwords locally, before they are sent to the backend in
+// CreateUser or UpdateUser requests.
+type PasswordPolicy struct {
+	EnforcementState     PasswordPolicyEnforcementState
+	ForceUpgradeOnSignIn bool
+	Constraints          CustomStrengthOptionsConfig
+}
+
+// Validate checks the given password against the password policy, and returns the requirements
+// that the password does not meet. It returns nil if the password meets all the requirements, or
+// if the password policy is not enforced.
+//
+// Lengths are measured in characters (Unicode code points). As in the backend, only the ASCII
+// letters and digits count as upper case, lower case and numeric characters. Any other character,
+// including non-ASCII letters and digits, counts as a non-alphanumeric character.
+func (p *PasswordPolicy) Validate(password string) []PasswordPolicyViolation {
+	if p.EnforcementState != PasswordPolicyEnforcementEnforce {
+		return nil
+	}
+
+	var violations []PasswordPolicyViolation
+	add := func(req PasswordRequirement, format string, args ...interface{}) {
+		violations = append(violations, PasswordPolicyViolation{
+			Requirement: req,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+
+	c := p.Constraints
+	minLength := c.MinLength
+	if minLength == 0 {
+		minLength = minPasswordLengthLowerBound
+	}
+	length := utf8.RuneCountInString(password)
+	if length < minLength {
+		add(PasswordRequirementMinLength, "Password must contain at least %d characters", minLength)
+	}
+	if c.MaxLength != 0 && length > c.MaxLength {
+		add(PasswordRequirementMaxLength, "Password may contain at most %d characters", c.MaxLength)
+	}
+
+	var upper, lower, numeric, other bool
+	for _, r := range password {
+		switch {
+		case 'A' <= r && r <= 'Z':
+			upper = true
+		case 'a' <= r && r <= 'z':
+			lower = true
+		case '0' <= r && r <= '9':
+			numeric = true
+		default:
+			other = true
+		}
+	}
+	if c.RequireUppercase && !upper {
+		add(PasswordRequirementUppercase, "Password must contain an upper case character")
+	}
+	if c.RequireLowercase && !lower {
+		add(PasswordRequirementLowercase, "Password must contain a lower case character")
+	}
+	if c.RequireNumeric && !numeric {
+		add(PasswordRequirementNumeric, "Password must contain a numeric character")
+	}
+	if c.RequireNonAlphanumeric && !other {
+		add(PasswordRequirementNonAlphanumeric, "Password must contain a non-alphanumeric character")
+	}
+	return violations
+}
+
+// GetPasswordPolicy returns the password policy of the current project or tenant.
+//
+// A policy that is not enforced is returned when the project or tenant has no password policy.
+func (c *baseClient) GetPasswordPolicy(ctx context.Context) (*PasswordPolicy, error) {
+	// The tenant resource carries the tenant config, while the project config has its own resource.
+	url := "/config"
+	if c.tenantID != "" {
+		url = ""
+	}
+	req := &internal.Request{
+		Method: http.MethodGet,
+		URL:    url,
+	}
+	var result struct {
+		PasswordPolicyConfig *PasswordPolicyConfig `json:"passwordPolicyConfig"`
+	}
+	if _, err := c.makeRequest(ctx, req, &result); err != nil {
+		return nil, err
+	}
+
+	policy := &PasswordPolicy{EnforcementState: PasswordPolicyEnforcementOff}
+	if config := result.PasswordPolicyConfig; config != nil {
+		if config.EnforcementState != "" {
+			policy.EnforcementState = config.EnforcementState
+		}
+		policy.ForceUpgradeOnSignIn = config.ForceUpgradeOnSignIn
+		if config.Constraints != nil {
+			policy.Constraints = *config.Constraints
+		}
+	}
+	return policy, nil
+}
+
+// IsPasswordDoesNotMeetRequirements checks if the given error was due to a password that does not
+// meet the password policy of the project or tenant.
+//
+// The unmet requirements are available from UnmetPasswordRequirements.
+func IsPasswordDoesNotMeetRequirements(err error) bool {
+	return hasAuthErrorCode(err, passwordDoesNotMeetRequirements)
+}
+
+// UnmetPasswordRequirements returns the password policy requirements that caused the given error,
+// in the same form as PasswordPolicy.Validate. The Message of each violation is the description
+// reported by the backend, and its Requirement is empty if the description is not recognized. It
+// returns nil if the error was not due to a password that does not meet the password policy, or if
+// the backend did not report the unmet requirements.
+func UnmetPasswordRequirements(err error) []PasswordPolicyViolation {
+	if !IsPasswordDoesNotMeetRequirements(err) {
+		return nil
+	}
+	reqs, _ := err.(*internal.FirebaseError).Ext[passwordRequirementsKey].([]PasswordPolicyViolation)
+	return reqs
+}
+
+// passwordRequirementPhrases maps phrases in the requirement descriptions reported by the backend
+// to the corresponding requirements. Non-alphanumeric must be matched before numeric.
+var passwordRequirementPhrases = []struct {
+	phrase      string
+	requirement PasswordRequirement
+}{
+	{"at least", PasswordRequirementMinLength},
+	{"at most", PasswordRequirementMaxLength},
+	{"upper case", PasswordRequirementUppercase},
+	{"uppercase", PasswordRequirementUppercase},
+	{"lower case", PasswordRequirementLowercase},
+	{"lowercase", PasswordRequirementLowercase},
+	{"non-alphanumeric", PasswordRequirementNonAlphanumeric},
+	{"non alphanumeric", PasswordRequirementNonAlphanumeric},
+	{"numeric", PasswordRequirementNumeric},
+}
+
+// parsePasswordRequirements extracts the unmet requirements from the error details reported by
+// the backend, which are of the form "Missing password requirements: [req1, req2]".
+func parsePasswordRequirements(detail string) []PasswordPolicyViolation {
+	start := strings.Index(detail, "[")
+	end := strings.LastIndex(detail, "]")
+	if start == -1 || end <= start {
+		return nil
+	}
+
+	var reqs []PasswordPolicyViolation
+	for _, req := range strings.Split(detail[start+1:end], ",") {
+		if req = strings.TrimSpace(req); req != "" {
+			reqs = append(reqs, PasswordPolicyViolation{
+				Requirement: toPasswordRequirement(req),
+				Message:     req,
+			})
+		}
+	}
+	return reqs
+}
+
+// toPasswordRequirement returns the requirement described by the given backend description, which
+// may also be the name of the requirement itself.
+func toPasswordRequirement(desc string) PasswordRequirement {
+	switch req := PasswordRequirement(desc); req {
+	case PasswordRequirementMinLength, PasswordRequirementMaxLength, PasswordRequirementUppercase,
+		PasswordRequirementLowercase, PasswordRequirementNumeric, PasswordRequirementNonAlphanumeric:
+		return req
+	}
+
+	desc = strings.ToLower(desc)
+	for _, p := range passwordRequirementPhrases {
+		if strings.Contains(desc, p.phrase) {
+			return p.requirement
+		}
+	}
+	return ""
+}
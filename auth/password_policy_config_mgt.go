@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	minPasswordLengthLowerBound = 6
+	minPasswordLengthUpperBound = 30
+	maxPasswordLengthUpperBound = 4096
+)
+
+// PasswordPolicyEnforcementState represents whether the password policy is enforced.
+type PasswordPolicyEnforcementState string
+
+// These constants represent the possible values for the PasswordPolicyEnforcementState type.
+const (
+	PasswordPolicyEnforcementOff     PasswordPolicyEnforcementState = "OFF"
+	PasswordPolicyEnforcementEnforce PasswordPolicyEnforcementState = "ENFORCE"
+)
+
+// PasswordPolicyConfig represents the password policy of a project or a tenant.
+type PasswordPolicyConfig struct {
+	// The enforcement state of the password policy.
+	EnforcementState PasswordPolicyEnforcementState `json:"passwordPolicyEnforcementState,omitempty"`
+	// Whether existing passwords that do not meet the policy must be updated on sign in.
+	ForceUpgradeOnSignIn bool `json:"forceUpgradeOnSignin,omitempty"`
+	// The constraints that make up the password policy. Required when the policy is enforced.
+	Constraints *CustomStrengthOptionsConfig `json:"-"`
+}
+
+// CustomStrengthOptionsConfig represents the constraints that make up a password policy.
+type CustomStrengthOptionsConfig struct {
+	RequireUppercase       bool `json:"containsUppercaseCharacter,omitempty"`
+	RequireLowercase       bool `json:"containsLowercaseCharacter,omitempty"`
+	RequireNonAlphanumeric bool `json:"containsNonAlphanumericCharacter,omitempty"`
+	RequireNumeric         bool `json:"containsNumericCharacter,omitempty"`
+	// The minimum length of the password, between 6 and 30. Defaults to 6 when not set.
+	MinLength int `json:"minPasswordLength,omitempty"`
+	// The maximum length of the password, up to 4096. No maximum is enforced when not set.
+	MaxLength int `json:"maxPasswordLength,omitempty"`
+}
+
+type passwordPolicyVersion struct {
+	CustomStrengthOptions *CustomStrengthOptionsConfig `json:"customStrengthOptions,omitempty"`
+}
+
+// MarshalJSON marshals a PasswordPolicyConfig into JSON (for internal use only).
+func (p PasswordPolicyConfig) MarshalJSON() ([]byte, error) {
+	type passwordPolicyInternal PasswordPolicyConfig
+	temp := struct {
+		passwordPolicyInternal
+		Versions []*passwordPolicyVersion `json:"passwordPolicyVersions,omitempty"`
+	}{
+		passwordPolicyInternal: passwordPolicyInternal(p),
+	}
+	if p.Constraints != nil {
+		temp.Versions = []*passwordPolicyVersion{{CustomStrengthOptions: p.Constraints}}
+	}
+	return json.Marshal(temp)
+}
+
+// UnmarshalJSON unmarshals a JSON string into a PasswordPolicyConfig (for internal use only).
+func (p *PasswordPolicyConfig) UnmarshalJSON(b []byte) error {
+	type passwordPolicyInternal PasswordPolicyConfig
+	temp := struct {
+		*passwordPolicyInternal
+		Versions []*passwordPolicyVersion `json:"passwordPolicyVersions,omitempty"`
+	}{
+		passwordPolicyInternal: (*passwordPolicyInternal)(p),
+	}
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+	// The first version is the one currently in effect.
+	if len(temp.Versions) > 0 {
+		p.Constraints = temp.Versions[0].CustomStrengthOptions
+	}
+	return nil
+}
+
+func (p PasswordPolicyConfig) validate() error {
+	if p.EnforcementState != PasswordPolicyEnforcementOff && p.EnforcementState != PasswordPolicyEnforcementEnforce {
+		return fmt.Errorf("\"PasswordPolicyConfig.EnforcementState\" must be 'ENFORCE' or 'OFF'")
+	}
+	if p.Constraints == nil {
+		if p.EnforcementState == PasswordPolicyEnforcementEnforce {
+			return fmt.Errorf("\"PasswordPolicyConfig.Constraints\" must be defined when the policy is enforced")
+		}
+		return nil
+	}
+	return p.Constraints.validate()
+}
+
+func (c *CustomStrengthOptionsConfig) validate() error {
+	minLength := c.MinLength
+	if minLength == 0 {
+		minLength = minPasswordLengthLowerBound
+	}
+	if minLength < minPasswordLengthLowerBound || minLength > minPasswordLengthUpperBound {
+		return fmt.Errorf("\"Constraints.MinLength\" must be an integer between %d and %d (inclusive)",
+			minPasswordLengthLowerBound, minPasswordLengthUpperBound)
+	}
+	if c.MaxLength != 0 && (c.MaxLength < minLength || c.MaxLength > maxPasswordLengthUpperBound) {
+		return fmt.Errorf("\"Constraints.MaxLength\" must be an integer between \"Constraints.MinLength\" and %d (inclusive)",
+			maxPasswordLengthUpperBound)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var testPasswordPolicy = &PasswordPolicy{
+	EnforcementState:     PasswordPolicyEnforcementEnforce,
+	ForceUpgradeOnSignIn: true,
+	Constraints: CustomStrengthOptionsConfig{
+		RequireUppercase: true,
+		RequireNumeric:   true,
+		MinLength:        8,
+		MaxLength:        30,
+	},
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := &PasswordPolicy{
+		EnforcementState: PasswordPolicyEnforcementEnforce,
+		Constraints: CustomStrengthOptionsConfig{
+			RequireUppercase:       true,
+			RequireLowercase:       true,
+			RequireNumeric:         true,
+			RequireNonAlphanumeric: true,
+			MinLength:              8,
+			MaxLength:              12,
+		},
+	}
+	cases := []struct {
+		name     string
+		policy   *PasswordPolicy
+		password string
+		want     []PasswordRequirement
+	}{
+		{"Valid", strict, "Passw0rd!", nil},
+		{"ValidUnicode", strict, "Pässwörd1€", nil},
+		{"NonASCIILetterIsNonAlphanumeric", strict, "Passw0rdé", nil},
+		{"NonASCIIUppercase", strict, "passw0rdÄ", []PasswordRequirement{PasswordRequirementUppercase}},
+		{"NonASCIILowercase", strict, "PASSW0RDä", []PasswordRequirement{PasswordRequirementLowercase}},
+		{"NonASCIINumeric", strict, "Password٣", []PasswordRequirement{PasswordRequirementNumeric}},
+		{"TooShort", strict, "Pa0!", []PasswordRequirement{PasswordRequirementMinLength}},
+		{"TooLong", strict, "Password123!!", []PasswordRequirement{PasswordRequirementMaxLength}},
+		{"Empty", strict, "", []PasswordRequirement{
+			PasswordRequirementMinLength,
+			PasswordRequirementUppercase,
+			PasswordRequirementLowercase,
+			PasswordRequirementNumeric,
+			PasswordRequirementNonAlphanumeric,
+		}},
+		{"NoUppercase", strict, "passw0rd!", []PasswordRequirement{PasswordRequirementUppercase}},
+		{"NoLowercase", strict, "PASSW0RD!", []PasswordRequirement{PasswordRequirementLowercase}},
+		{"NoNumeric", strict, "Password!", []PasswordRequirement{PasswordRequirementNumeric}},
+		{"NoNonAlphanumeric", strict, "Passw0rdd", []PasswordRequirement{PasswordRequirementNonAlphanumeric}},
+		{"DefaultMinLength", &PasswordPolicy{EnforcementState: PasswordPolicyEnforcementEnforce}, "12345",
+			[]PasswordRequirement{PasswordRequirementMinLength}},
+		{"NotEnforced", &PasswordPolicy{
+			EnforcementState: PasswordPolicyEnforcementOff,
+			Constraints:      strict.Constraints,
+		}, "", nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []PasswordRequirement
+			for _, v := range tc.policy.Validate(tc.password) {
+				if v.Message == "" {
+					t.Errorf("Validate(%q) violation %q has no message", tc.password, v.Requirement)
+				}
+				got = append(got, v.Requirement)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Validate(%q) = %v; want = %v", tc.password, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetPasswordPolicy(t *testing.T) {
+	s := echoServer([]byte(projectAuthConfigsResponse), t)
+	defer s.Close()
+
+	policy, err := s.Client.GetPasswordPolicy(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(policy, testPasswordPolicy) {
+		t.Errorf("GetPasswordPolicy() = %#v; want = %#v", policy, testPasswordPolicy)
+	}
+
+	wantURL := "/projects/mock-project-id/config"
+	if s.Req[0].Method != http.MethodGet || s.Req[0].URL.Path != wantURL {
+		t.Errorf("GetPasswordPolicy() = %s %s; want = GET %s", s.Req[0].Method, s.Req[0].URL.Path, wantURL)
+	}
+}
+
+func TestGetPasswordPolicyForTenant(t *testing.T) {
+	s := echoServer([]byte(projectAuthConfigsResponse), t)
+	defer s.Close()
+
+	client, err := s.Client.TenantManager.AuthForTenant("tenantID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := client.GetPasswordPolicy(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(policy, testPasswordPolicy) {
+		t.Errorf("GetPasswordPolicy() = %#v; want = %#v", policy, testPasswordPolicy)
+	}
+
+	wantURL := "/projects/mock-project-id/tenants/tenantID"
+	if s.Req[0].URL.Path != wantURL {
+		t.Errorf("GetPasswordPolicy() URL = %q; want = %q", s.Req[0].URL.Path, wantURL)
+	}
+}
+
+func TestGetPasswordPolicyNotConfigured(t *testing.T) {
+	s := echoServer([]byte(`{}`), t)
+	defer s.Close()
+
+	policy, err := s.Client.GetPasswordPolicy(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PasswordPolicy{EnforcementState: PasswordPolicyEnforcementOff}
+	if !reflect.DeepEqual(policy, want) {
+		t.Errorf("GetPasswordPolicy() = %#v; want = %#v", policy, want)
+	}
+	if v := policy.Validate(""); v != nil {
+		t.Errorf("Validate() = %v; want = nil", v)
+	}
+}
+
+func TestUnmetPasswordRequirements(t *testing.T) {
+	resp := []byte(`{"error":{"message":"PASSWORD_DOES_NOT_MEET_REQUIREMENTS : Missing password requirements: ` +
+		`[Password must contain at least 8 characters, Password must contain a numeric character]"}}`)
+	s := echoServer(resp, t)
+	defer s.Close()
+	s.Client.baseClient.httpClient.RetryConfig = nil
+	s.Status = http.StatusBadRequest
+
+	_, err := s.Client.CreateUser(context.Background(), (&UserToCreate{}).Password("password"))
+	if !IsPasswordDoesNotMeetRequirements(err) {
+		t.Fatalf("CreateUser() = %v; want = PasswordDoesNotMeetRequirements", err)
+	}
+	want := []PasswordPolicyViolation{
+		{PasswordRequirementMinLength, "Password must contain at least 8 characters"},
+		{PasswordRequirementNumeric, "Password must contain a numeric character"},
+	}
+	if got := UnmetPasswordRequirements(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmetPasswordRequirements() = %v; want = %v", got, want)
+	}
+
+	if got := UnmetPasswordRequirements(errors.New("password")); got != nil {
+		t.Errorf("UnmetPasswordRequirements() = %v; want = nil", got)
+	}
+}
+
+func TestParsePasswordRequirements(t *testing.T) {
+	detail := "Missing password requirements: [Password must contain at least 8 characters, " +
+		"Password may contain at most 16 characters, Password must contain an upper case character, " +
+		"Password must contain a lower case character, Password must contain a non-alphanumeric character, " +
+		"NUMERIC_CHARACTER, Password must be memorable]"
+	want := []PasswordRequirement{
+		PasswordRequirementMinLength,
+		PasswordRequirementMaxLength,
+		PasswordRequirementUppercase,
+		PasswordRequirementLowercase,
+		PasswordRequirementNonAlphanumeric,
+		PasswordRequirementNumeric,
+		"",
+	}
+
+	got := parsePasswordRequirements(detail)
+	if len(got) != len(want) {
+		t.Fatalf("parsePasswordRequirements() = %v; want = %d requirements", got, len(want))
+	}
+	for i, v := range got {
+		if v.Requirement != want[i] || v.Message == "" {
+			t.Errorf("parsePasswordRequirements()[%d] = %v; want = %q", i, v, want[i])
+		}
+	}
+
+	if got := parsePasswordRequirements("no requirements"); got != nil {
+		t.Errorf("parsePasswordRequirements() = %v; want = nil", got)
+	}
+}
@@ -26,7 +26,43 @@ import (
 
 // ProjectConfig represents the properties to update on the provided project config.
 type ProjectConfig struct {
-	MultiFactorConfig *MultiFactorConfig `json:"mfa,omitEmpty"`
+	MultiFactorConfig    *MultiFactorConfig    `json:"mfa,omitEmpty"`
+	PasswordPolicyConfig *PasswordPolicyConfig `json:"passwordPolicyConfig,omitempty"`
+	EmailPrivacyConfig   *EmailPrivacyConfig   `json:"emailPrivacyConfig,omitempty"`
+	RecaptchaConfig      *RecaptchaConfig      `json:"recaptchaConfig,omitempty"`
+	SMSRegionConfig      *SMSRegionConfig      `json:"smsRegionConfig,omitempty"`
+}
+
+// EmailPrivacyConfig represents the email privacy settings of a project or a tenant.
+type EmailPrivacyConfig struct {
+	// Whether email enumeration protection is enabled. When enabled, the responses of the
+	// client-side email APIs do not reveal whether an account exists for an email address.
+	EnableImprovedEmailPrivacy bool `json:"enableImprovedEmailPrivacy"`
+}
+
+func (e EmailPrivacyConfig) validate() error {
+	return nil
+}
+
+const (
+	passwordPolicyConfigKey = "passwordPolicyConfig"
+	emailPrivacyConfigKey   = "emailPrivacyConfig"
+	recaptchaConfigKey      = "recaptchaConfig"
+	smsRegionConfigKey      = "smsRegionConfig"
+)
+
+// validateAuthConfigs validates the auth configurations shared by projects and tenants.
+func validateAuthConfigs(params nestedMap) error {
+	for _, key := range []string{
+		passwordPolicyConfigKey, emailPrivacyConfigKey, recaptchaConfigKey, smsRegionConfigKey,
+	} {
+		if val, ok := params[key]; ok {
+			if err := val.(interface{ validate() error }).validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (base *baseClient) GetProjectConfig(ctx context.Context) (*ProjectConfig, error) {
@@ -81,6 +117,26 @@ func (pc *ProjectConfigToUpdate) MultiFactorConfig(multiFactorConfig MultiFactor
 	return pc.set(multiFactorConfigProjectKey, multiFactorConfig)
 }
 
+// PasswordPolicyConfig configures the project's password policy
+func (pc *ProjectConfigToUpdate) PasswordPolicyConfig(config PasswordPolicyConfig) *ProjectConfigToUpdate {
+	return pc.set(passwordPolicyConfigKey, config)
+}
+
+// EmailPrivacyConfig configures the project's email privacy settings
+func (pc *ProjectConfigToUpdate) EmailPrivacyConfig(config EmailPrivacyConfig) *ProjectConfigToUpdate {
+	return pc.set(emailPrivacyConfigKey, config)
+}
+
+// RecaptchaConfig configures the project's reCAPTCHA Enterprise settings
+func (pc *ProjectConfigToUpdate) RecaptchaConfig(config RecaptchaConfig) *ProjectConfigToUpdate {
+	return pc.set(recaptchaConfigKey, config.withoutKeys())
+}
+
+// SMSRegionConfig configures the regions to which the project can send SMS
+func (pc *ProjectConfigToUpdate) SMSRegionConfig(config SMSRegionConfig) *ProjectConfigToUpdate {
+	return pc.set(smsRegionConfigKey, config)
+}
+
 func (pc *ProjectConfigToUpdate) set(key string, value interface{}) *ProjectConfigToUpdate {
 	pc.ensureParams().Set(key, value)
 	return pc
@@ -108,5 +164,5 @@ func (pc *ProjectConfigToUpdate) validate() error {
 			return err
 		}
 	}
-	return nil
+	return validateAuthConfigs(pc.params)
 }
@@ -141,3 +141,205 @@ func checkUpdateProjectConfigRequest(s *mockAuthServer, wantBody interface{}, wa
 
 	return nil
 }
+
+const projectAuthConfigsResponse = `{
+	"passwordPolicyConfig": {
+		"passwordPolicyEnforcementState": "ENFORCE",
+		"forceUpgradeOnSignin": true,
+		"passwordPolicyVersions": [
+			{
+				"customStrengthOptions": {
+					"containsUppercaseCharacter": true,
+					"containsNumericCharacter": true,
+					"minPasswordLength": 8,
+					"maxPasswordLength": 30
+				},
+				"schemaVersion": 1
+			}
+		]
+	},
+	"emailPrivacyConfig": {
+		"enableImprovedEmailPrivacy": true
+	},
+	"recaptchaConfig": {
+		"emailPasswordEnforcementState": "AUDIT",
+		"phoneEnforcementState": "ENFORCE",
+		"managedRules": [{"endScore": 0.3, "action": "BLOCK"}],
+		"recaptchaKeys": [{"type": "WEB", "key": "projects/mock-project-id/keys/key1"}],
+		"useAccountDefender": true,
+		"useSmsTollFraudProtection": true,
+		"tollFraudManagedRules": [{"startScore": 0.8, "action": "BLOCK"}]
+	},
+	"smsRegionConfig": {
+		"allowlistOnly": {
+			"allowedRegions": ["US", "CA"]
+		}
+	}
+}`
+
+var testProjectAuthConfigs = &ProjectConfig{
+	PasswordPolicyConfig: &PasswordPolicyConfig{
+		EnforcementState:     PasswordPolicyEnforcementEnforce,
+		ForceUpgradeOnSignIn: true,
+		Constraints: &CustomStrengthOptionsConfig{
+			RequireUppercase: true,
+			RequireNumeric:   true,
+			MinLength:        8,
+			MaxLength:        30,
+		},
+	},
+	EmailPrivacyConfig: &EmailPrivacyConfig{
+		EnableImprovedEmailPrivacy: true,
+	},
+	RecaptchaConfig: &RecaptchaConfig{
+		EmailPasswordEnforcementState: RecaptchaProviderEnforcementAudit,
+		PhoneEnforcementState:         RecaptchaProviderEnforcementEnforce,
+		ManagedRules:                  []*RecaptchaManagedRule{{EndScore: 0.3, Action: RecaptchaActionBlock}},
+		RecaptchaKeys: []*RecaptchaKey{
+			{Type: RecaptchaKeyClientTypeWeb, Key: "projects/mock-project-id/keys/key1"},
+		},
+		UseAccountDefender:        true,
+		UseSMSTollFraudProtection: true,
+		SMSTollFraudManagedRules: []*RecaptchaTollFraudManagedRule{
+			{StartScore: 0.8, Action: RecaptchaActionBlock},
+		},
+	},
+	SMSRegionConfig: &SMSRegionConfig{
+		AllowlistOnly: &AllowlistOnlySMSRegions{
+			AllowedRegions: []string{"US", "CA"},
+		},
+	},
+}
+
+func TestGetProjectConfigWithAuthConfigs(t *testing.T) {
+	s := echoServer([]byte(projectAuthConfigsResponse), t)
+	defer s.Close()
+
+	projectConfig, err := s.Client.GetProjectConfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(projectConfig, testProjectAuthConfigs); diff != "" {
+		t.Errorf("GetProjectConfig() diff = %s", diff)
+	}
+}
+
+func TestUpdateProjectConfigWithAuthConfigs(t *testing.T) {
+	s := echoServer([]byte(projectAuthConfigsResponse), t)
+	defer s.Close()
+
+	options := (&ProjectConfigToUpdate{}).
+		PasswordPolicyConfig(*testProjectAuthConfigs.PasswordPolicyConfig).
+		EmailPrivacyConfig(EmailPrivacyConfig{}).
+		RecaptchaConfig(*testProjectAuthConfigs.RecaptchaConfig).
+		SMSRegionConfig(*testProjectAuthConfigs.SMSRegionConfig)
+	if _, err := s.Client.UpdateProjectConfig(context.Background(), options); err != nil {
+		t.Fatal(err)
+	}
+
+	wantBody := map[string]interface{}{
+		"passwordPolicyConfig": map[string]interface{}{
+			"passwordPolicyEnforcementState": "ENFORCE",
+			"forceUpgradeOnSignin":           true,
+			"passwordPolicyVersions": []interface{}{
+				map[string]interface{}{
+					"customStrengthOptions": map[string]interface{}{
+						"containsUppercaseCharacter": true,
+						"containsNumericCharacter":   true,
+						"minPasswordLength":          float64(8),
+						"maxPasswordLength":          float64(30),
+					},
+				},
+			},
+		},
+		"emailPrivacyConfig": map[string]interface{}{
+			"enableImprovedEmailPrivacy": false,
+		},
+		"recaptchaConfig": map[string]interface{}{
+			"emailPasswordEnforcementState": "AUDIT",
+			"phoneEnforcementState":         "ENFORCE",
+			"managedRules": []interface{}{
+				map[string]interface{}{"endScore": 0.3, "action": "BLOCK"},
+			},
+			"useAccountDefender":        true,
+			"useSmsTollFraudProtection": true,
+			"tollFraudManagedRules": []interface{}{
+				map[string]interface{}{"startScore": 0.8, "action": "BLOCK"},
+			},
+		},
+		"smsRegionConfig": map[string]interface{}{
+			"allowlistOnly": map[string]interface{}{
+				"allowedRegions": []interface{}{"US", "CA"},
+			},
+		},
+	}
+	wantMask := []string{"emailPrivacyConfig", "passwordPolicyConfig", "recaptchaConfig", "smsRegionConfig"}
+	if err := checkUpdateProjectConfigRequest(s, wantBody, wantMask); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateProjectConfigInvalidAuthConfigs(t *testing.T) {
+	cases := []struct {
+		config *ProjectConfigToUpdate
+		want   string
+	}{
+		{
+			(&ProjectConfigToUpdate{}).PasswordPolicyConfig(PasswordPolicyConfig{}),
+			`"PasswordPolicyConfig.EnforcementState" must be 'ENFORCE' or 'OFF'`,
+		},
+		{
+			(&ProjectConfigToUpdate{}).PasswordPolicyConfig(PasswordPolicyConfig{
+				EnforcementState: PasswordPolicyEnforcementEnforce,
+			}),
+			`"PasswordPolicyConfig.Constraints" must be defined when the policy is enforced`,
+		},
+		{
+			(&ProjectConfigToUpdate{}).PasswordPolicyConfig(PasswordPolicyConfig{
+				EnforcementState: PasswordPolicyEnforcementEnforce,
+				Constraints:      &CustomStrengthOptionsConfig{MinLength: 31},
+			}),
+			`"Constraints.MinLength" must be an integer between 6 and 30 (inclusive)`,
+		},
+		{
+			(&ProjectConfigToUpdate{}).PasswordPolicyConfig(PasswordPolicyConfig{
+				EnforcementState: PasswordPolicyEnforcementOff,
+				Constraints:      &CustomStrengthOptionsConfig{MinLength: 10, MaxLength: 8},
+			}),
+			`"Constraints.MaxLength" must be an integer between "Constraints.MinLength" and 4096 (inclusive)`,
+		},
+		{
+			(&ProjectConfigToUpdate{}).RecaptchaConfig(RecaptchaConfig{EmailPasswordEnforcementState: "ON"}),
+			`"RecaptchaConfig.EmailPasswordEnforcementState" must be 'OFF', 'AUDIT' or 'ENFORCE'`,
+		},
+		{
+			(&ProjectConfigToUpdate{}).RecaptchaConfig(RecaptchaConfig{
+				ManagedRules: []*RecaptchaManagedRule{{EndScore: 1.1, Action: RecaptchaActionBlock}},
+			}),
+			`"RecaptchaConfig.ManagedRules.EndScore" must be a number between 0 and 1 (inclusive)`,
+		},
+		{
+			(&ProjectConfigToUpdate{}).RecaptchaConfig(RecaptchaConfig{
+				SMSTollFraudManagedRules: []*RecaptchaTollFraudManagedRule{{StartScore: 0.5}},
+			}),
+			`the action of "RecaptchaConfig.SMSTollFraudManagedRules.StartScore" rules must be 'BLOCK'`,
+		},
+		{
+			(&ProjectConfigToUpdate{}).SMSRegionConfig(SMSRegionConfig{}),
+			`exactly one of "SMSRegionConfig.AllowByDefault" and "SMSRegionConfig.AllowlistOnly" must be defined`,
+		},
+		{
+			(&ProjectConfigToUpdate{}).SMSRegionConfig(SMSRegionConfig{
+				AllowByDefault: &AllowByDefaultSMSRegions{DisallowedRegions: []string{"usa"}},
+			}),
+			`"usa" is not a valid two letter ISO 3166 region code`,
+		},
+	}
+	base := &baseClient{}
+	for _, tc := range cases {
+		_, err := base.UpdateProjectConfig(context.Background(), tc.config)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("UpdateProjectConfig() = %v; want = %q", err, tc.want)
+		}
+	}
+}
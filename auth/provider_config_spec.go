@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ProviderConfigClient is the interface used to manage the OIDC and SAML provider configurations
+// of a project or a tenant. It is implemented by Client and TenantClient.
+type ProviderConfigClient interface {
+	OIDCProviderConfigs(ctx context.Context, nextPageToken string) *OIDCProviderConfigIterator
+	CreateOIDCProviderConfig(ctx context.Context, config *OIDCProviderConfigToCreate) (*OIDCProviderConfig, error)
+	UpdateOIDCProviderConfig(
+		ctx context.Context, id string, config *OIDCProviderConfigToUpdate) (*OIDCProviderConfig, error)
+	DeleteOIDCProviderConfig(ctx context.Context, id string) error
+	SAMLProviderConfigs(ctx context.Context, nextPageToken string) *SAMLProviderConfigIterator
+	CreateSAMLProviderConfig(ctx context.Context, config *SAMLProviderConfigToCreate) (*SAMLProviderConfig, error)
+	UpdateSAMLProviderConfig(
+		ctx context.Context, id string, config *SAMLProviderConfigToUpdate) (*SAMLProviderConfig, error)
+	DeleteSAMLProviderConfig(ctx context.Context, id string) error
+}
+
+// ProviderConfigSpec is a declarative description of the OIDC and SAML provider configurations of
+// a project or a tenant, applied with ApplyProviderConfigs.
+//
+// The spec can be parsed from JSON with ParseProviderConfigSpec. YAML is not supported.
+type ProviderConfigSpec struct {
+	OIDC []*OIDCProviderSpec `json:"oidc,omitempty"`
+	SAML []*SAMLProviderSpec `json:"saml,omitempty"`
+	// Prune deletes the provider configurations that are not in the spec. By default they are left
+	// unchanged.
+	Prune bool `json:"prune,omitempty"`
+}
+
+// OIDCProviderSpec describes an OIDC provider configuration.
+//
+// If neither response type is set, the ID token response type is used. If ClientSecret is empty,
+// the client secret of an existing configuration is left unchanged. The code response type
+// requires a client secret, which must therefore be set when the configuration does not exist yet.
+type OIDCProviderSpec struct {
+	ID                  string `json:"id"`
+	DisplayName         string `json:"displayName,omitempty"`
+	Enabled             bool   `json:"enabled"`
+	ClientID            string `json:"clientId"`
+	Issuer              string `json:"issuer"`
+	ClientSecret        string `json:"clientSecret,omitempty"`
+	CodeResponseType    bool   `json:"codeResponseType,omitempty"`
+	IDTokenResponseType bool   `json:"idTokenResponseType,omitempty"`
+}
+
+// SAMLProviderSpec describes a SAML provider configuration.
+//
+// X509Certificates are PEM encoded, or base64 encoded DER certificates.
+type SAMLProviderSpec struct {
+	ID                    string   `json:"id"`
+	DisplayName           string   `json:"displayName,omitempty"`
+	Enabled               bool     `json:"enabled"`
+	IDPEntityID           string   `json:"idpEntityId"`
+	SSOURL                string   `json:"ssoUrl"`
+	RequestSigningEnabled bool     `json:"requestSigningEnabled,omitempty"`
+	X509Certificates      []string `json:"x509Certificates"`
+	RPEntityID            string   `json:"rpEntityId"`
+	CallbackURL           string   `json:"callbackUrl"`
+}
+
+// ParseProviderConfigSpec parses and validates a ProviderConfigSpec from JSON. Unknown fields are
+// rejected.
+func ParseProviderConfigSpec(data []byte) (*ProviderConfigSpec, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var spec ProviderConfigSpec
+	if err := dec.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config spec: %v", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks the spec locally, without contacting the backend.
+//
+// Provider IDs must be unique and have the "oidc." or "saml." prefix. OIDC issuers must be https
+// URLs without a query or a fragment. SAML certificates must pass SAMLCertificateInfo.Validate.
+// Checks that depend on the existing configurations, such as the client secret of new OIDC
+// providers with the code response type, are made by ApplyProviderConfigs before any change.
+func (spec *ProviderConfigSpec) Validate() error {
+	if spec == nil {
+		return errors.New("provider config spec must not be nil")
+	}
+
+	ids := make(map[string]bool)
+	for i, p := range spec.OIDC {
+		if p == nil {
+			return fmt.Errorf("oidc[%d]: provider must not be nil", i)
+		}
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("oidc[%d]: %v", i, err)
+		}
+		if ids[p.ID] {
+			return fmt.Errorf("oidc[%d]: duplicate provider id: %q", i, p.ID)
+		}
+		ids[p.ID] = true
+	}
+	for i, p := range spec.SAML {
+		if p == nil {
+			return fmt.Errorf("saml[%d]: provider must not be nil", i)
+		}
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("saml[%d]: %v", i, err)
+		}
+		if ids[p.ID] {
+			return fmt.Errorf("saml[%d]: duplicate provider id: %q", i, p.ID)
+		}
+		ids[p.ID] = true
+	}
+	return nil
+}
+
+func (p *OIDCProviderSpec) validate() error {
+	if err := validateOIDCConfigID(p.ID); err != nil {
+		return err
+	}
+	if p.ClientID == "" {
+		return errors.New("ClientID must not be empty")
+	}
+	issuer, err := url.Parse(p.Issuer)
+	if err != nil {
+		return fmt.Errorf("failed to parse Issuer: %v", err)
+	}
+	if issuer.Scheme != "https" || issuer.Host == "" || issuer.RawQuery != "" || issuer.Fragment != "" {
+		return fmt.Errorf("Issuer must be an https URL without a query or a fragment: %q", p.Issuer)
+	}
+	if p.CodeResponseType && p.IDTokenResponseType {
+		return errors.New("Only one response type may be chosen")
+	}
+	return nil
+}
+
+func (p *SAMLProviderSpec) validate() error {
+	if err := validateSAMLConfigID(p.ID); err != nil {
+		return err
+	}
+	if p.IDPEntityID == "" {
+		return errors.New("IDPEntityID must not be empty")
+	}
+	if p.RPEntityID == "" {
+		return errors.New("RPEntityID must not be empty")
+	}
+	if _, err := url.ParseRequestURI(p.SSOURL); err != nil {
+		return fmt.Errorf("failed to parse SSOURL: %v", err)
+	}
+	if _, err := url.ParseRequestURI(p.CallbackURL); err != nil {
+		return fmt.Errorf("failed to parse CallbackURL: %v", err)
+	}
+	if len(p.X509Certificates) == 0 {
+		return errors.New("X509Certificates must not be empty")
+	}
+	for i, cert := range p.X509Certificates {
+		if err := validateX509Certificate(cert); err != nil {
+			return fmt.Errorf("X509Certificates[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func validateX509Certificate(cert string) error {
+	info, err := ParseSAMLCertificate(cert)
+	if err != nil {
+		return err
+	}
+	return info.Validate()
+}
+
+// ApplyProviderConfigs updates the OIDC and SAML provider configurations of a project or a tenant
+// to match the spec, and returns the changes that were applied.
+//
+// The spec is validated locally before any request is made. The existing configurations are then
+// listed, and only the configurations that differ from the spec are created, updated or, if
+// ProviderConfigSpec.Prune is set, deleted. Applying the same spec again therefore makes no changes.
+// If an error occurs, the returned ConfigDiff contains the changes that were applied before the
+// error.
+func ApplyProviderConfigs(ctx context.Context, client ProviderConfigClient, spec *ProviderConfigSpec) (*ConfigDiff, error) {
+	return applyProviderConfigs(ctx, client, spec, false)
+}
+
+// PlanProviderConfigs returns the changes that ApplyProviderConfigs would apply, without applying
+// them.
+func PlanProviderConfigs(ctx context.Context, client ProviderConfigClient, spec *ProviderConfigSpec) (*ConfigDiff, error) {
+	return applyProviderConfigs(ctx, client, spec, true)
+}
+
+func applyProviderConfigs(
+	ctx context.Context, client ProviderConfigClient, spec *ProviderConfigSpec, dryRun bool) (*ConfigDiff, error) {
+	if client == nil {
+		return nil, errors.New("client must not be nil")
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	current := &ConfigSnapshot{}
+	if err := snapshotProviders(ctx, client, current); err != nil {
+		return nil, err
+	}
+	if err := spec.validateClientSecrets(current); err != nil {
+		return nil, err
+	}
+	desired := spec.toSnapshot(current)
+	opts := &ApplySnapshotOptions{
+		DryRun: dryRun,
+		Prune:  spec.Prune,
+	}
+	return applySnapshot(ctx, client, current, desired, opts, func([]string) error {
+		return errors.New("provider config specs do not update settings")
+	})
+}
+
+// validateClientSecrets checks that the OIDC configurations that use the code response type and do
+// not exist in the current snapshot have a client secret.
+func (spec *ProviderConfigSpec) validateClientSecrets(current *ConfigSnapshot) error {
+	existing := make(map[string]bool)
+	for _, c := range current.OIDCProviderConfigs {
+		existing[c.ID] = true
+	}
+	for i, p := range spec.OIDC {
+		if p.CodeResponseType && p.ClientSecret == "" && !existing[p.ID] {
+			return fmt.Errorf("oidc[%d]: ClientSecret must not be empty for a new provider with the code response type", i)
+		}
+	}
+	return nil
+}
+
+// toSnapshot converts the spec to a snapshot that only has provider configurations. Client secrets
+// that are not in the spec are copied from the current snapshot.
+func (spec *ProviderConfigSpec) toSnapshot(current *ConfigSnapshot) *ConfigSnapshot {
+	secrets := make(map[string]string)
+	for _, c := range current.OIDCProviderConfigs {
+		secrets[c.ID] = c.ClientSecret
+	}
+
+	snapshot := &ConfigSnapshot{}
+	for _, p := range spec.OIDC {
+		config := &OIDCProviderConfig{
+			ID:                  p.ID,
+			DisplayName:         p.DisplayName,
+			Enabled:             p.Enabled,
+			ClientID:            p.ClientID,
+			Issuer:              p.Issuer,
+			ClientSecret:        p.ClientSecret,
+			CodeResponseType:    p.CodeResponseType,
+			IDTokenResponseType: p.IDTokenResponseType || !p.CodeResponseType,
+		}
+		if config.ClientSecret == "" {
+			config.ClientSecret = secrets[p.ID]
+		}
+		snapshot.OIDCProviderConfigs = append(snapshot.OIDCProviderConfigs, config)
+	}
+	for _, p := range spec.SAML {
+		certs := make([]string, len(p.X509Certificates))
+		for i, cert := range p.X509Certificates {
+			certs[i] = strings.TrimSpace(cert)
+		}
+		snapshot.SAMLProviderConfigs = append(snapshot.SAMLProviderConfigs, &SAMLProviderConfig{
+			ID:                    p.ID,
+			DisplayName:           p.DisplayName,
+			Enabled:               p.Enabled,
+			IDPEntityID:           p.IDPEntityID,
+			SSOURL:                p.SSOURL,
+			RequestSigningEnabled: p.RequestSigningEnabled,
+			X509Certificates:      certs,
+			RPEntityID:            p.RPEntityID,
+			CallbackURL:           p.CallbackURL,
+		})
+	}
+	return snapshot
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a self-signed PEM encoded certificate that is valid until notAfter.
+func testCertificate(t *testing.T, notAfter time.Time) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notBefore := notAfter.Add(-24 * time.Hour)
+	if now := time.Now(); notBefore.After(now) {
+		notBefore = now.Add(-time.Hour)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func testProviderConfigSpec(cert string) *ProviderConfigSpec {
+	return &ProviderConfigSpec{
+		OIDC: []*OIDCProviderSpec{
+			{ID: "oidc.a", ClientID: "CLIENT_A", Issuer: "https://oidc.com/a", Enabled: true},
+			{ID: "oidc.b", ClientID: "CLIENT_B", Issuer: "https://oidc.com/b", Enabled: true},
+		},
+		SAML: []*SAMLProviderSpec{
+			{
+				ID:               "saml.old",
+				Enabled:          true,
+				IDPEntityID:      "IDP_ENTITY_ID",
+				SSOURL:           "https://example.com/login",
+				X509Certificates: []string{cert},
+				RPEntityID:       "RP_ENTITY_ID",
+				CallbackURL:      "https://example.com/callback",
+			},
+		},
+	}
+}
+
+func TestApplyProviderConfigs(t *testing.T) {
+	s, writes := snapshotServer(t)
+	defer s.Close()
+	tc, err := s.Client.TenantManager.AuthForTenant("tenantID")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := testCertificate(t, time.Now().Add(time.Hour))
+	diff, err := ApplyProviderConfigs(context.Background(), tc, testProviderConfigSpec(cert+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*ConfigChange{
+		{Kind: ConfigUpdate, Resource: OIDCProviderResource, ID: "oidc.b", Fields: []string{"Enabled"}},
+		{Kind: ConfigUpdate, Resource: SAMLProviderResource, ID: "saml.old", Fields: []string{"X509Certificates"}},
+	}
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("ApplyProviderConfigs() = %v; want = %v", diff.Changes, want)
+	}
+
+	wantWrites := []snapshotRequest{
+		{"PATCH", "/oauthIdpConfigs/oidc.b", map[string]interface{}{"enabled": true}},
+		{"PATCH", "/inboundSamlConfigs/saml.old", map[string]interface{}{
+			"idpConfig": map[string]interface{}{
+				"idpCertificates": []interface{}{
+					map[string]interface{}{"x509Certificate": strings.TrimSpace(cert)},
+				},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(*writes, wantWrites) {
+		t.Errorf("ApplyProviderConfigs() requests = %v; want = %v", *writes, wantWrites)
+	}
+}
+
+func TestPlanProviderConfigs(t *testing.T) {
+	s, writes := snapshotServer(t)
+	defer s.Close()
+
+	spec := &ProviderConfigSpec{
+		OIDC: []*OIDCProviderSpec{
+			{ID: "oidc.a", ClientID: "CLIENT_A", Issuer: "https://oidc.com/a", Enabled: true},
+		},
+		Prune: true,
+	}
+	diff, err := PlanProviderConfigs(context.Background(), s.Client, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*ConfigChange{
+		{Kind: ConfigDelete, Resource: OIDCProviderResource, ID: "oidc.b"},
+		{Kind: ConfigDelete, Resource: SAMLProviderResource, ID: "saml.old"},
+	}
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("PlanProviderConfigs() = %v; want = %v", diff.Changes, want)
+	}
+	if len(*writes) != 0 {
+		t.Errorf("PlanProviderConfigs() requests = %v; want = none", *writes)
+	}
+}
+
+func TestParseProviderConfigSpec(t *testing.T) {
+	cert := testCertificate(t, time.Now().Add(time.Hour))
+	want := testProviderConfigSpec(cert)
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := ParseProviderConfigSpec(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Errorf("ParseProviderConfigSpec() = %#v; want = %#v", spec, want)
+	}
+
+	if _, err := ParseProviderConfigSpec([]byte(`{"oidc": [{"id": "oidc.a", "unknown": true}]}`)); err == nil {
+		t.Errorf("ParseProviderConfigSpec(unknown field) = nil; want = error")
+	}
+}
+
+func TestProviderConfigSpecValidate(t *testing.T) {
+	valid := testCertificate(t, time.Now().Add(time.Hour))
+	block, _ := pem.Decode([]byte(valid))
+	if err := testProviderConfigSpec(base64.StdEncoding.EncodeToString(block.Bytes)).Validate(); err != nil {
+		t.Errorf("Validate(DER certificate) = %v; want = nil", err)
+	}
+
+	expired := testCertificate(t, time.Now().Add(-time.Hour))
+	cases := []struct {
+		name   string
+		modify func(*ProviderConfigSpec)
+		want   string
+	}{
+		{
+			"OIDCID",
+			func(s *ProviderConfigSpec) { s.OIDC[0].ID = "a" },
+			`oidc[0]: invalid OIDC provider id: "a"`,
+		},
+		{
+			"DuplicateID",
+			func(s *ProviderConfigSpec) { s.OIDC[1].ID = "oidc.a" },
+			`oidc[1]: duplicate provider id: "oidc.a"`,
+		},
+		{
+			"ClientID",
+			func(s *ProviderConfigSpec) { s.OIDC[0].ClientID = "" },
+			"oidc[0]: ClientID must not be empty",
+		},
+		{
+			"HTTPIssuer",
+			func(s *ProviderConfigSpec) { s.OIDC[0].Issuer = "http://oidc.com/a" },
+			`oidc[0]: Issuer must be an https URL without a query or a fragment: "http://oidc.com/a"`,
+		},
+		{
+			"IssuerQuery",
+			func(s *ProviderConfigSpec) { s.OIDC[0].Issuer = "https://oidc.com/a?tenant=1" },
+			`oidc[0]: Issuer must be an https URL without a query or a fragment: "https://oidc.com/a?tenant=1"`,
+		},
+		{
+			"ResponseTypes",
+			func(s *ProviderConfigSpec) { s.OIDC[0].CodeResponseType, s.OIDC[0].IDTokenResponseType = true, true },
+			"oidc[0]: Only one response type may be chosen",
+		},
+		{
+			"SAMLID",
+			func(s *ProviderConfigSpec) { s.SAML[0].ID = "oidc.c" },
+			`saml[0]: invalid SAML provider id: "oidc.c"`,
+		},
+		{
+			"SSOURL",
+			func(s *ProviderConfigSpec) { s.SAML[0].SSOURL = "not a url" },
+			`saml[0]: failed to parse SSOURL: parse "not a url": invalid URI for request`,
+		},
+		{
+			"NoCertificates",
+			func(s *ProviderConfigSpec) { s.SAML[0].X509Certificates = nil },
+			"saml[0]: X509Certificates must not be empty",
+		},
+		{
+			"InvalidCertificate",
+			func(s *ProviderConfigSpec) { s.SAML[0].X509Certificates = []string{"CERT1"} },
+			"saml[0]: X509Certificates[0]: certificate must be PEM encoded or base64 encoded DER",
+		},
+		{
+			"ExpiredCertificate",
+			func(s *ProviderConfigSpec) { s.SAML[0].X509Certificates = []string{expired} },
+			"saml[0]: X509Certificates[0]: certificate expired at ",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := testProviderConfigSpec(valid)
+			tc.modify(spec)
+			err := spec.Validate()
+			if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("Validate() = %v; want = %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyProviderConfigsInvalidSpec(t *testing.T) {
+	s, writes := snapshotServer(t)
+	defer s.Close()
+
+	spec := testProviderConfigSpec("CERT1")
+	if diff, err := ApplyProviderConfigs(context.Background(), s.Client, spec); diff != nil || err == nil {
+		t.Errorf("ApplyProviderConfigs() = (%v, %v); want = (nil, error)", diff, err)
+	}
+	if diff, err := ApplyProviderConfigs(context.Background(), s.Client, nil); diff != nil || err == nil {
+		t.Errorf("ApplyProviderConfigs(nil) = (%v, %v); want = (nil, error)", diff, err)
+	}
+	if len(*writes) != 0 {
+		t.Errorf("ApplyProviderConfigs() requests = %v; want = none", *writes)
+	}
+}
+
+func TestApplyProviderConfigsCodeFlowWithoutSecret(t *testing.T) {
+	s, writes := snapshotServer(t)
+	defer s.Close()
+
+	spec := &ProviderConfigSpec{
+		OIDC: []*OIDCProviderSpec{
+			{ID: "oidc.a", ClientID: "CLIENT_A", Issuer: "https://oidc.com/a", CodeResponseType: true},
+			{ID: "oidc.new", ClientID: "CLIENT_NEW", Issuer: "https://oidc.com/new", CodeResponseType: true},
+		},
+	}
+	want := "oidc[1]: ClientSecret must not be empty for a new provider with the code response type"
+	if diff, err := ApplyProviderConfigs(context.Background(), s.Client, spec); diff != nil || err == nil ||
+		err.Error() != want {
+		t.Errorf("ApplyProviderConfigs() = (%v, %v); want = (nil, %q)", diff, err, want)
+	}
+	if len(*writes) != 0 {
+		t.Errorf("ApplyProviderConfigs() requests = %v; want = none", *writes)
+	}
+
+	spec.OIDC = spec.OIDC[:1]
+	if _, err := PlanProviderConfigs(context.Background(), s.Client, spec); err != nil {
+		t.Errorf("PlanProviderConfigs(existing) = %v; want = nil", err)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+)
+
+// RecaptchaProviderEnforcementState represents the reCAPTCHA enforcement state of a sign-in provider.
+type RecaptchaProviderEnforcementState string
+
+// These constants represent the possible values for the RecaptchaProviderEnforcementState type.
+const (
+	RecaptchaProviderEnforcementOff     RecaptchaProviderEnforcementState = "OFF"
+	RecaptchaProviderEnforcementAudit   RecaptchaProviderEnforcementState = "AUDIT"
+	RecaptchaProviderEnforcementEnforce RecaptchaProviderEnforcementState = "ENFORCE"
+)
+
+// RecaptchaAction represents the action taken when a reCAPTCHA rule matches a request.
+type RecaptchaAction string
+
+// These constants represent the possible values for the RecaptchaAction type.
+const (
+	RecaptchaActionBlock RecaptchaAction = "BLOCK"
+)
+
+// RecaptchaKeyClientType represents the client platform of a reCAPTCHA key.
+type RecaptchaKeyClientType string
+
+// These constants represent the possible values for the RecaptchaKeyClientType type.
+const (
+	RecaptchaKeyClientTypeWeb     RecaptchaKeyClientType = "WEB"
+	RecaptchaKeyClientTypeIOS     RecaptchaKeyClientType = "IOS"
+	RecaptchaKeyClientTypeAndroid RecaptchaKeyClientType = "ANDROID"
+)
+
+// RecaptchaConfig represents the reCAPTCHA Enterprise configuration of a project or a tenant.
+type RecaptchaConfig struct {
+	// The reCAPTCHA enforcement state of the email password provider.
+	EmailPasswordEnforcementState RecaptchaProviderEnforcementState `json:"emailPasswordEnforcementState,omitempty"`
+	// The reCAPTCHA enforcement state of the phone provider.
+	PhoneEnforcementState RecaptchaProviderEnforcementState `json:"phoneEnforcementState,omitempty"`
+	// The rules applied to the reCAPTCHA scores of email password requests.
+	ManagedRules []*RecaptchaManagedRule `json:"managedRules,omitempty"`
+	// The reCAPTCHA keys provisioned for the project or the tenant. This is output only, and is
+	// ignored in create and update requests.
+	RecaptchaKeys []*RecaptchaKey `json:"recaptchaKeys,omitempty"`
+	// Whether account defender assessments are used for email password requests.
+	UseAccountDefender bool `json:"useAccountDefender,omitempty"`
+	// Whether reCAPTCHA bot scores are used for phone requests.
+	UseSMSBotScore bool `json:"useSmsBotScore,omitempty"`
+	// Whether SMS toll fraud protection is used for phone requests.
+	UseSMSTollFraudProtection bool `json:"useSmsTollFraudProtection,omitempty"`
+	// The rules applied to the SMS toll fraud risk scores of phone requests.
+	SMSTollFraudManagedRules []*RecaptchaTollFraudManagedRule `json:"tollFraudManagedRules,omitempty"`
+}
+
+// RecaptchaManagedRule represents a rule applied to the reCAPTCHA score of a request.
+type RecaptchaManagedRule struct {
+	// Requests with a score up to EndScore are subject to the Action. Must be between 0 and 1.
+	EndScore float64         `json:"endScore"`
+	Action   RecaptchaAction `json:"action,omitempty"`
+}
+
+// RecaptchaTollFraudManagedRule represents a rule applied to the SMS toll fraud risk score of a
+// request.
+type RecaptchaTollFraudManagedRule struct {
+	// Requests with a risk score from StartScore are subject to the Action. Must be between 0 and 1.
+	StartScore float64         `json:"startScore"`
+	Action     RecaptchaAction `json:"action,omitempty"`
+}
+
+// RecaptchaKey represents a reCAPTCHA key provisioned for a client platform.
+type RecaptchaKey struct {
+	Type RecaptchaKeyClientType `json:"type,omitempty"`
+	// The resource name of the reCAPTCHA key.
+	Key string `json:"key,omitempty"`
+}
+
+// withoutKeys returns a copy of the config without the output-only reCAPTCHA keys, which the
+// backend rejects in create and update requests.
+func (r RecaptchaConfig) withoutKeys() RecaptchaConfig {
+	r.RecaptchaKeys = nil
+	return r
+}
+
+func (r RecaptchaConfig) validate() error {
+	if err := r.EmailPasswordEnforcementState.validate("EmailPasswordEnforcementState"); err != nil {
+		return err
+	}
+	if err := r.PhoneEnforcementState.validate("PhoneEnforcementState"); err != nil {
+		return err
+	}
+	for _, rule := range r.ManagedRules {
+		if rule == nil {
+			return fmt.Errorf("\"RecaptchaConfig.ManagedRules\" must not contain nil rules")
+		}
+		if err := validateRecaptchaRule("ManagedRules.EndScore", rule.EndScore, rule.Action); err != nil {
+			return err
+		}
+	}
+	for _, rule := range r.SMSTollFraudManagedRules {
+		if rule == nil {
+			return fmt.Errorf("\"RecaptchaConfig.SMSTollFraudManagedRules\" must not contain nil rules")
+		}
+		if err := validateRecaptchaRule("SMSTollFraudManagedRules.StartScore", rule.StartScore, rule.Action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s RecaptchaProviderEnforcementState) validate(name string) error {
+	switch s {
+	case "", RecaptchaProviderEnforcementOff, RecaptchaProviderEnforcementAudit, RecaptchaProviderEnforcementEnforce:
+		return nil
+	}
+	return fmt.Errorf("\"RecaptchaConfig.%s\" must be 'OFF', 'AUDIT' or 'ENFORCE'", name)
+}
+
+func validateRecaptchaRule(name string, score float64, action RecaptchaAction) error {
+	if score < 0 || score > 1 {
+		return fmt.Errorf("\"RecaptchaConfig.%s\" must be a number between 0 and 1 (inclusive)", name)
+	}
+	if action != RecaptchaActionBlock {
+		return fmt.Errorf("the action of \"RecaptchaConfig.%s\" rules must be 'BLOCK'", name)
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/iterator"
+)
+
+const (
+	minRSAKeyBits   = 2048
+	minECDSAKeyBits = 256
+
+	samlRedirectBinding = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	samlPOSTBinding     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+)
+
+// SAMLCertificateInfo describes an X.509 certificate of a SAML identity provider.
+type SAMLCertificateInfo struct {
+	Subject   string
+	Issuer    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// The public key algorithm of the certificate: "RSA", "ECDSA" or "Ed25519".
+	KeyAlgorithm string
+	// The size of the public key in bits.
+	KeyBits int
+}
+
+// ParseSAMLCertificate parses a PEM encoded, or a base64 encoded DER X.509 certificate.
+//
+// The certificate is not validated. Use SAMLCertificateInfo.Validate to check its validity period
+// and key strength.
+func ParseSAMLCertificate(cert string) (*SAMLCertificateInfo, error) {
+	cert = strings.TrimSpace(cert)
+	if cert == "" {
+		return nil, errors.New("certificate must not be empty")
+	}
+
+	var der []byte
+	if block, _ := pem.Decode([]byte(cert)); block != nil {
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block type: %q", block.Type)
+		}
+		der = block.Bytes
+	} else {
+		var err error
+		if der, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(cert), "")); err != nil {
+			return nil, errors.New("certificate must be PEM encoded or base64 encoded DER")
+		}
+	}
+
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+
+	info := &SAMLCertificateInfo{
+		Subject:   parsed.Subject.String(),
+		Issuer:    parsed.Issuer.String(),
+		NotBefore: parsed.NotBefore,
+		NotAfter:  parsed.NotAfter,
+	}
+	switch key := parsed.PublicKey.(type) {
+	case *rsa.PublicKey:
+		info.KeyAlgorithm = "RSA"
+		info.KeyBits = key.N.BitLen()
+	case *ecdsa.PublicKey:
+		info.KeyAlgorithm = "ECDSA"
+		info.KeyBits = key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		info.KeyAlgorithm = "Ed25519"
+		info.KeyBits = 256
+	default:
+		return nil, fmt.Errorf("unsupported public key algorithm: %v", parsed.PublicKeyAlgorithm)
+	}
+	return info, nil
+}
+
+// Validate checks that the certificate is currently valid, and that its key is at least 2048 bits
+// for RSA keys, or 256 bits for ECDSA keys.
+func (info *SAMLCertificateInfo) Validate() error {
+	return info.validateAt(time.Now())
+}
+
+func (info *SAMLCertificateInfo) validateAt(now time.Time) error {
+	if now.After(info.NotAfter) {
+		return fmt.Errorf("certificate expired at %s", info.NotAfter.Format(time.RFC3339))
+	}
+	if now.Before(info.NotBefore) {
+		return fmt.Errorf("certificate is not valid before %s", info.NotBefore.Format(time.RFC3339))
+	}
+	if (info.KeyAlgorithm == "RSA" && info.KeyBits < minRSAKeyBits) ||
+		(info.KeyAlgorithm == "ECDSA" && info.KeyBits < minECDSAKeyBits) {
+		return fmt.Errorf("%s key of %d bits is too weak", info.KeyAlgorithm, info.KeyBits)
+	}
+	return nil
+}
+
+// SAMLIdPMetadata is the configuration of a SAML identity provider, read from its metadata XML
+// document.
+type SAMLIdPMetadata struct {
+	EntityID string
+	// The single sign-on URL. The HTTP-Redirect binding is preferred over the HTTP-POST binding.
+	SSOURL string
+	// The PEM encoded signing certificates.
+	X509Certificates []string
+	// Whether the identity provider requires signed authentication requests.
+	WantAuthnRequestsSigned bool
+}
+
+type samlEntitiesDescriptor struct {
+	EntityDescriptors []*samlEntityDescriptor `xml:"EntityDescriptor"`
+}
+
+type samlEntityDescriptor struct {
+	EntityID          string                  `xml:"entityID,attr"`
+	IDPSSODescriptors []*samlIDPSSODescriptor `xml:"IDPSSODescriptor"`
+}
+
+type samlIDPSSODescriptor struct {
+	WantAuthnRequestsSigned bool `xml:"WantAuthnRequestsSigned,attr"`
+	KeyDescriptors          []struct {
+		Use          string   `xml:"use,attr"`
+		Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+	} `xml:"KeyDescriptor"`
+	SingleSignOnServices []struct {
+		Binding  string `xml:"Binding,attr"`
+		Location string `xml:"Location,attr"`
+	} `xml:"SingleSignOnService"`
+}
+
+// ParseSAMLMetadata reads the configuration of a SAML identity provider from its metadata XML
+// document.
+//
+// The document may contain a single EntityDescriptor, or an EntitiesDescriptor, in which case the
+// first entity with an IDPSSODescriptor is used. The certificates are parsed, but not validated.
+func ParseSAMLMetadata(data []byte) (*SAMLIdPMetadata, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse SAML metadata: %v", err)
+	}
+
+	var entities []*samlEntityDescriptor
+	switch root.XMLName.Local {
+	case "EntityDescriptor":
+		var entity samlEntityDescriptor
+		if err := xml.Unmarshal(data, &entity); err != nil {
+			return nil, fmt.Errorf("failed to parse SAML metadata: %v", err)
+		}
+		entities = append(entities, &entity)
+	case "EntitiesDescriptor":
+		var descriptor samlEntitiesDescriptor
+		if err := xml.Unmarshal(data, &descriptor); err != nil {
+			return nil, fmt.Errorf("failed to parse SAML metadata: %v", err)
+		}
+		entities = descriptor.EntityDescriptors
+	default:
+		return nil, fmt.Errorf("unexpected SAML metadata root element: %q", root.XMLName.Local)
+	}
+
+	for _, entity := range entities {
+		if len(entity.IDPSSODescriptors) > 0 {
+			return entity.toSAMLIdPMetadata()
+		}
+	}
+	return nil, errors.New("SAML metadata does not describe an identity provider")
+}
+
+func (entity *samlEntityDescriptor) toSAMLIdPMetadata() (*SAMLIdPMetadata, error) {
+	if entity.EntityID == "" {
+		return nil, errors.New("SAML metadata does not have an entity ID")
+	}
+	idp := entity.IDPSSODescriptors[0]
+	metadata := &SAMLIdPMetadata{
+		EntityID:                entity.EntityID,
+		WantAuthnRequestsSigned: idp.WantAuthnRequestsSigned,
+	}
+
+	var redirect, post, other string
+	for _, sso := range idp.SingleSignOnServices {
+		switch {
+		case sso.Binding == samlRedirectBinding && redirect == "":
+			redirect = sso.Location
+		case sso.Binding == samlPOSTBinding && post == "":
+			post = sso.Location
+		case other == "":
+			other = sso.Location
+		}
+	}
+	switch {
+	case redirect != "":
+		metadata.SSOURL = redirect
+	case post != "":
+		metadata.SSOURL = post
+	default:
+		metadata.SSOURL = other
+	}
+	if metadata.SSOURL == "" {
+		return nil, errors.New("SAML metadata does not have a single sign-on service")
+	}
+
+	for _, key := range idp.KeyDescriptors {
+		if key.Use != "" && key.Use != "signing" {
+			continue
+		}
+		for _, cert := range key.Certificates {
+			der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(cert), ""))
+			if err != nil {
+				return nil, fmt.Errorf("invalid certificate in SAML metadata: %v", err)
+			}
+			if _, err := x509.ParseCertificate(der); err != nil {
+				return nil, fmt.Errorf("invalid certificate in SAML metadata: %v", err)
+			}
+			encoded := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+			metadata.X509Certificates = append(metadata.X509Certificates, strings.TrimSpace(string(encoded)))
+		}
+	}
+	if len(metadata.X509Certificates) == 0 {
+		return nil, errors.New("SAML metadata does not have a signing certificate")
+	}
+	return metadata, nil
+}
+
+// ProviderConfigToCreate returns the options to create a SAMLProviderConfig with the given ID for
+// the identity provider. The RPEntityID and the CallbackURL must be set before the config is
+// created.
+//
+// Certificates that fail SAMLCertificateInfo.Validate are left out. This allows creating a config
+// from metadata that still lists a retired certificate. An error is returned if none of the
+// certificates is valid.
+func (m *SAMLIdPMetadata) ProviderConfigToCreate(id string) (*SAMLProviderConfigToCreate, error) {
+	certs, err := m.validCertificates()
+	if err != nil {
+		return nil, err
+	}
+	return (&SAMLProviderConfigToCreate{}).
+		ID(id).
+		IDPEntityID(m.EntityID).
+		SSOURL(m.SSOURL).
+		X509Certificates(certs).
+		RequestSigningEnabled(m.WantAuthnRequestsSigned), nil
+}
+
+// ProviderConfigToUpdate returns the options to update an existing SAMLProviderConfig with the
+// configuration of the identity provider, for example after the identity provider has rotated its
+// certificates. Certificates are selected as in ProviderConfigToCreate.
+func (m *SAMLIdPMetadata) ProviderConfigToUpdate() (*SAMLProviderConfigToUpdate, error) {
+	certs, err := m.validCertificates()
+	if err != nil {
+		return nil, err
+	}
+	return (&SAMLProviderConfigToUpdate{}).
+		IDPEntityID(m.EntityID).
+		SSOURL(m.SSOURL).
+		X509Certificates(certs).
+		RequestSigningEnabled(m.WantAuthnRequestsSigned), nil
+}
+
+func (m *SAMLIdPMetadata) validCertificates() ([]string, error) {
+	var certs []string
+	var lastErr error
+	for _, cert := range m.X509Certificates {
+		info, err := ParseSAMLCertificate(cert)
+		if err == nil {
+			err = info.Validate()
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("SAML metadata does not have a valid certificate: %v", lastErr)
+	}
+	return certs, nil
+}
+
+// ExpiringSAMLCertificate is a certificate of a SAMLProviderConfig that expires soon, or that
+// cannot be parsed.
+type ExpiringSAMLCertificate struct {
+	// The ID of the tenant of the SAMLProviderConfig. Empty for the configs of the project.
+	TenantID   string
+	ProviderID string
+	// The index of the certificate in SAMLProviderConfig.X509Certificates.
+	Index int
+	// The certificate, or nil if it cannot be parsed.
+	Certificate *SAMLCertificateInfo
+	// The error that occurred while parsing the certificate.
+	Err error
+}
+
+// ExpiringSAMLCertificates returns the certificates of the SAML provider configurations of the
+// project and of all its tenants that expire within the given duration, including certificates
+// that have already expired. Certificates that cannot be parsed are also returned.
+//
+// The project configs are listed first, followed by the configs of each tenant in the order of
+// the Tenants iterator.
+func (tm *TenantManager) ExpiringSAMLCertificates(
+	ctx context.Context, within time.Duration) ([]*ExpiringSAMLCertificate, error) {
+	deadline := tm.base.clock.Now().Add(within)
+	result, err := expiringSAMLCertificates(ctx, tm.base, "", deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	it := tm.Tenants(ctx, "")
+	for {
+		tenant, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tc, err := tm.AuthForTenant(tenant.ID)
+		if err != nil {
+			return nil, err
+		}
+		expiring, err := expiringSAMLCertificates(ctx, tc.baseClient, tenant.ID, deadline)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expiring...)
+	}
+	return result, nil
+}
+
+func expiringSAMLCertificates(
+	ctx context.Context, client *baseClient, tenantID string, deadline time.Time) ([]*ExpiringSAMLCertificate, error) {
+	var result []*ExpiringSAMLCertificate
+	it := client.SAMLProviderConfigs(ctx, "")
+	for {
+		config, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for i, cert := range config.X509Certificates {
+			info, err := ParseSAMLCertificate(cert)
+			if err == nil && info.NotAfter.After(deadline) {
+				continue
+			}
+			result = append(result, &ExpiringSAMLCertificate{
+				TenantID:    tenantID,
+				ProviderID:  config.ID,
+				Index:       i,
+				Certificate: info,
+				Err:         err,
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,351 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificateWithKey returns a PEM encoded certificate for the given key, self-signed by a
+// 2048 bit RSA key.
+func testCertificateWithKey(t *testing.T, pub crypto.PublicKey, notBefore, notAfter time.Time) string {
+	signer, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// certificateBody returns the base64 encoded DER of a PEM encoded certificate, as it appears in
+// SAML metadata.
+func certificateBody(cert string) string {
+	lines := strings.Split(strings.TrimSpace(cert), "\n")
+	return strings.Join(lines[1:len(lines)-1], "\n")
+}
+
+const testSAMLMetadata = `<?xml version="1.0"?>
+<md:EntitiesDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata">
+  <md:EntityDescriptor entityID="https://sp.example.com">
+    <md:SPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol"/>
+  </md:EntityDescriptor>
+  <md:EntityDescriptor entityID="https://idp.example.com/metadata">
+    <md:IDPSSODescriptor WantAuthnRequestsSigned="true"
+        protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+      <md:KeyDescriptor use="signing">
+        <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+          <ds:X509Data><ds:X509Certificate>
+%s
+          </ds:X509Certificate></ds:X509Data>
+        </ds:KeyInfo>
+      </md:KeyDescriptor>
+      <md:KeyDescriptor>
+        <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+          <ds:X509Data><ds:X509Certificate>%s</ds:X509Certificate></ds:X509Data>
+        </ds:KeyInfo>
+      </md:KeyDescriptor>
+      <md:KeyDescriptor use="encryption">
+        <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+          <ds:X509Data><ds:X509Certificate>%s</ds:X509Certificate></ds:X509Data>
+        </ds:KeyInfo>
+      </md:KeyDescriptor>
+      <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+          Location="https://idp.example.com/sso/post"/>
+      <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+          Location="https://idp.example.com/sso/redirect"/>
+    </md:IDPSSODescriptor>
+  </md:EntityDescriptor>
+</md:EntitiesDescriptor>`
+
+func TestParseSAMLMetadata(t *testing.T) {
+	now := time.Now()
+	current := testCertificate(t, now.Add(365*24*time.Hour))
+	retired := testCertificate(t, now.Add(-time.Hour))
+	encryption := testCertificate(t, now.Add(365*24*time.Hour))
+	data := fmt.Sprintf(testSAMLMetadata, certificateBody(current), certificateBody(retired), certificateBody(encryption))
+
+	metadata, err := ParseSAMLMetadata([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &SAMLIdPMetadata{
+		EntityID:                "https://idp.example.com/metadata",
+		SSOURL:                  "https://idp.example.com/sso/redirect",
+		X509Certificates:        []string{strings.TrimSpace(current), strings.TrimSpace(retired)},
+		WantAuthnRequestsSigned: true,
+	}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("ParseSAMLMetadata() = %#v; want = %#v", metadata, want)
+	}
+
+	create, err := metadata.ProviderConfigToCreate("saml.idp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	create.RPEntityID("RP_ENTITY_ID").CallbackURL("https://example.com/callback")
+	body, id, err := create.buildRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantIDPConfig := map[string]interface{}{
+		"idpEntityId":     "https://idp.example.com/metadata",
+		"ssoUrl":          "https://idp.example.com/sso/redirect",
+		"signRequest":     true,
+		"idpCertificates": []idpCertificate{{X509Certificate: strings.TrimSpace(current)}},
+	}
+	if id != "saml.idp" || !reflect.DeepEqual(body["idpConfig"], wantIDPConfig) {
+		t.Errorf("ProviderConfigToCreate() = (%q, %#v); want = (%q, %#v)", id, body["idpConfig"], "saml.idp", wantIDPConfig)
+	}
+
+	update, err := metadata.ProviderConfigToUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := update.buildRequest(); err != nil || !reflect.DeepEqual(body["idpConfig"], wantIDPConfig) {
+		t.Errorf("ProviderConfigToUpdate() = (%#v, %v); want = %#v", body["idpConfig"], err, wantIDPConfig)
+	}
+}
+
+func TestParseSAMLMetadataEntityDescriptor(t *testing.T) {
+	cert := testCertificate(t, time.Now().Add(time.Hour))
+	data := `<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="IDP_ENTITY_ID">
+  <IDPSSODescriptor>
+    <KeyDescriptor><KeyInfo><X509Data><X509Certificate>` + certificateBody(cert) + `</X509Certificate></X509Data></KeyInfo></KeyDescriptor>
+    <SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:SOAP" Location="https://idp.example.com/soap"/>
+  </IDPSSODescriptor>
+</EntityDescriptor>`
+
+	metadata, err := ParseSAMLMetadata([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &SAMLIdPMetadata{
+		EntityID:         "IDP_ENTITY_ID",
+		SSOURL:           "https://idp.example.com/soap",
+		X509Certificates: []string{strings.TrimSpace(cert)},
+	}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("ParseSAMLMetadata() = %#v; want = %#v", metadata, want)
+	}
+}
+
+func TestParseSAMLMetadataError(t *testing.T) {
+	expired := testCertificate(t, time.Now().Add(-time.Hour))
+	idp := func(content string) string {
+		return `<EntityDescriptor entityID="IDP"><IDPSSODescriptor>` + content + `</IDPSSODescriptor></EntityDescriptor>`
+	}
+	sso := `<SingleSignOnService Location="https://idp.example.com/sso"/>`
+	key := func(cert string) string {
+		return `<KeyDescriptor><KeyInfo><X509Data><X509Certificate>` + cert +
+			`</X509Certificate></X509Data></KeyInfo></KeyDescriptor>`
+	}
+	cases := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"NotXML", "not xml", "failed to parse SAML metadata: EOF"},
+		{"RootElement", "<Other/>", `unexpected SAML metadata root element: "Other"`},
+		{"NoIdP", `<EntityDescriptor entityID="SP"><SPSSODescriptor/></EntityDescriptor>`,
+			"SAML metadata does not describe an identity provider"},
+		{"NoEntityID", `<EntityDescriptor><IDPSSODescriptor/></EntityDescriptor>`,
+			"SAML metadata does not have an entity ID"},
+		{"NoSSO", idp(key(certificateBody(expired))), "SAML metadata does not have a single sign-on service"},
+		{"NoCertificate", idp(sso), "SAML metadata does not have a signing certificate"},
+		{"InvalidCertificate", idp(sso + key("AAAA")), "invalid certificate in SAML metadata: "},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			metadata, err := ParseSAMLMetadata([]byte(tc.data))
+			if metadata != nil || err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("ParseSAMLMetadata() = (%v, %v); want = (nil, %q)", metadata, err, tc.want)
+			}
+		})
+	}
+
+	metadata, err := ParseSAMLMetadata([]byte(idp(sso + key(certificateBody(expired)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantErr := "SAML metadata does not have a valid certificate: certificate expired at "
+	if _, err := metadata.ProviderConfigToCreate("saml.idp"); err == nil || !strings.HasPrefix(err.Error(), wantErr) {
+		t.Errorf("ProviderConfigToCreate() = %v; want = %q", err, wantErr)
+	}
+	if _, err := metadata.ProviderConfigToUpdate(); err == nil || !strings.HasPrefix(err.Error(), wantErr) {
+		t.Errorf("ProviderConfigToUpdate() = %v; want = %q", err, wantErr)
+	}
+}
+
+func TestParseSAMLCertificate(t *testing.T) {
+	now := time.Now()
+	cert := testCertificate(t, now.Add(time.Hour))
+	info, err := ParseSAMLCertificate(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Subject != "CN=idp.example.com" || info.KeyAlgorithm != "RSA" || info.KeyBits != 2048 ||
+		!info.NotAfter.Equal(now.Add(time.Hour).Truncate(time.Second)) {
+		t.Errorf("ParseSAMLCertificate() = %#v; want = 2048 bit RSA certificate for idp.example.com", info)
+	}
+	if err := info.Validate(); err != nil {
+		t.Errorf("Validate() = %v; want = nil", err)
+	}
+
+	// Base64 encoded DER certificates are also accepted.
+	if info, err := ParseSAMLCertificate(certificateBody(cert)); err != nil || info.KeyBits != 2048 {
+		t.Errorf("ParseSAMLCertificate(DER) = (%v, %v); want = 2048 bit RSA certificate", info, err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec := testCertificateWithKey(t, &ecKey.PublicKey, now.Add(-time.Hour), now.Add(time.Hour))
+	if info, err := ParseSAMLCertificate(ec); err != nil || info.KeyAlgorithm != "ECDSA" || info.KeyBits != 256 {
+		t.Errorf("ParseSAMLCertificate(ECDSA) = (%v, %v); want = 256 bit ECDSA certificate", info, err)
+	}
+
+	if _, err := ParseSAMLCertificate(""); err == nil {
+		t.Errorf("ParseSAMLCertificate(\"\") = nil; want = error")
+	}
+	key := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}))
+	if _, err := ParseSAMLCertificate(key); err == nil || err.Error() != `unexpected PEM block type: "PRIVATE KEY"` {
+		t.Errorf("ParseSAMLCertificate(key) = %v; want = unexpected PEM block type", err)
+	}
+}
+
+func TestSAMLCertificateValidate(t *testing.T) {
+	now := time.Now()
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		cert string
+		want string
+	}{
+		{"Expired", testCertificate(t, now.Add(-time.Hour)), "certificate expired at "},
+		{
+			"NotYetValid",
+			testCertificateWithKey(t, &weakKey.PublicKey, now.Add(time.Hour), now.Add(2*time.Hour)),
+			"certificate is not valid before ",
+		},
+		{
+			"WeakKey",
+			testCertificateWithKey(t, &weakKey.PublicKey, now.Add(-time.Hour), now.Add(time.Hour)),
+			"RSA key of 1024 bits is too weak",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := ParseSAMLCertificate(tc.cert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := info.Validate(); err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("Validate() = %v; want = %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpiringSAMLCertificates(t *testing.T) {
+	now := time.Now()
+	expiring := testCertificate(t, now.Add(10*24*time.Hour))
+	valid := testCertificate(t, now.Add(365*24*time.Hour))
+	samlConfig := func(id string, certs ...string) map[string]interface{} {
+		var idpCerts []map[string]string
+		for _, cert := range certs {
+			idpCerts = append(idpCerts, map[string]string{"x509Certificate": cert})
+		}
+		return map[string]interface{}{
+			"name":      "projects/mock-project-id/inboundSamlConfigs/" + id,
+			"idpConfig": map[string]interface{}{"idpCertificates": idpCerts},
+		}
+	}
+	responses := map[string]interface{}{
+		"/projects/mock-project-id/inboundSamlConfigs": map[string]interface{}{
+			"inboundSamlConfigs": []interface{}{samlConfig("saml.project", valid, expiring)},
+		},
+		"/projects/mock-project-id/tenants": map[string]interface{}{
+			"tenants": []interface{}{
+				map[string]string{"name": "projects/mock-project-id/tenants/tenant1"},
+				map[string]string{"name": "projects/mock-project-id/tenants/tenant2"},
+			},
+		},
+		"/projects/mock-project-id/tenants/tenant1/inboundSamlConfigs": map[string]interface{}{
+			"inboundSamlConfigs": []interface{}{samlConfig("saml.tenant", valid)},
+		},
+		"/projects/mock-project-id/tenants/tenant2/inboundSamlConfigs": map[string]interface{}{
+			"inboundSamlConfigs": []interface{}{samlConfig("saml.invalid", "CERT1")},
+		},
+	}
+
+	s := echoServer(nil, t)
+	defer s.Close()
+	s.Srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	result, err := s.Client.TenantManager.ExpiringSAMLCertificates(context.Background(), 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("ExpiringSAMLCertificates() = %d certificates; want = 2", len(result))
+	}
+
+	got := result[0]
+	if got.TenantID != "" || got.ProviderID != "saml.project" || got.Index != 1 || got.Err != nil ||
+		!got.Certificate.NotAfter.Equal(now.Add(10*24*time.Hour).Truncate(time.Second)) {
+		t.Errorf("ExpiringSAMLCertificates()[0] = %#v; want = saml.project certificate 1", got)
+	}
+	got = result[1]
+	if got.TenantID != "tenant2" || got.ProviderID != "saml.invalid" || got.Index != 0 ||
+		got.Certificate != nil || got.Err == nil {
+		t.Errorf("ExpiringSAMLCertificates()[1] = %#v; want = saml.invalid parse error", got)
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"firebase.google.com/go/v4/internal"
+)
+
+const (
+	defaultSessionCookieName = "session"
+	csrfNonceSize            = 16
+
+	// SDK-generated error codes
+	csrfTokenInvalid = "CSRF_TOKEN_INVALID"
+)
+
+// SessionCookieOptions specifies how session cookies are packaged into an http.Cookie.
+//
+// The zero value produces a cookie named "session" that is scoped to the path "/", and marked
+// Secure, HttpOnly and SameSite=Lax.
+type SessionCookieOptions struct {
+	// Name of the cookie. Defaults to "session".
+	Name string
+	// Domain of the cookie. Defaults to the host of the current request.
+	Domain string
+	// Path of the cookie. Defaults to "/".
+	Path string
+	// SameSite mode of the cookie. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// Insecure disables the Secure attribute of the cookie. This should only be used when
+	// serving over plain HTTP during local development.
+	Insecure bool
+}
+
+// SessionHTTPCookie creates a new Firebase session cookie from the given ID token and expiry
+// duration, and packages it into an http.Cookie that can be passed to http.SetCookie.
+//
+// The returned cookie has the same lifetime as the session cookie, and is HttpOnly so that it
+// cannot be read by client-side scripts. See SessionCookieOptions for the other defaults.
+func (c *Client) SessionHTTPCookie(
+	ctx context.Context,
+	idToken string,
+	expiresIn time.Duration,
+	opts *SessionCookieOptions,
+) (*http.Cookie, error) {
+	cookie, err := c.SessionCookie(ctx, idToken, expiresIn)
+	if err != nil {
+		return nil, err
+	}
+	return newHTTPCookie(cookie, expiresIn, c.clock.Now(), opts), nil
+}
+
+func newHTTPCookie(value string, expiresIn time.Duration, now time.Time, opts *SessionCookieOptions) *http.Cookie {
+	if opts == nil {
+		opts = &SessionCookieOptions{}
+	}
+
+	cookie := &http.Cookie{
+		Name:     opts.Name,
+		Value:    value,
+		Domain:   opts.Domain,
+		Path:     opts.Path,
+		Expires:  now.Add(expiresIn),
+		MaxAge:   int(expiresIn.Seconds()),
+		Secure:   !opts.Insecure,
+		HttpOnly: true,
+		SameSite: opts.SameSite,
+	}
+	if cookie.Name == "" {
+		cookie.Name = defaultSessionCookieName
+	}
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if cookie.SameSite == 0 {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+	return cookie
+}
+
+// SessionCookieExpiresWithin checks if the given decoded session cookie expires within the
+// specified duration.
+func (c *Client) SessionCookieExpiresWithin(token *Token, d time.Duration) bool {
+	return !c.clock.Now().Add(d).Before(time.Unix(token.Expires, 0))
+}
+
+// RefreshSessionCookie verifies the given session cookie, and re-mints it from the given ID token
+// when it is close to expiry.
+//
+// The session cookie is verified with VerifySessionCookieAndCheckRevoked. If it does not expire
+// within the refreshWindow, the decoded session cookie is returned along with an empty string.
+// Otherwise the ID token is verified (including revocation checks), and must belong to the same
+// user as the session cookie. A new session cookie valid for expiresIn is then created from it and
+// returned along with the decoded original session cookie.
+//
+// The ID token is only used when a refresh is due, so callers may pass an empty string when no
+// fresh ID token is available; in that case an error is returned only if a refresh is due.
+func (c *Client) RefreshSessionCookie(
+	ctx context.Context,
+	sessionCookie string,
+	idToken string,
+	expiresIn time.Duration,
+	refreshWindow time.Duration,
+) (*Token, string, error) {
+	decoded, err := c.VerifySessionCookieAndCheckRevoked(ctx, sessionCookie)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !c.SessionCookieExpiresWithin(decoded, refreshWindow) {
+		return decoded, "", nil
+	}
+
+	if idToken == "" {
+		return nil, "", errors.New("session cookie is close to expiry; id token must not be empty")
+	}
+	fresh, err := c.VerifyIDTokenAndCheckRevoked(ctx, idToken)
+	if err != nil {
+		return nil, "", err
+	}
+	if fresh.UID != decoded.UID {
+		return nil, "", fmt.Errorf("id token uid %q does not match session cookie uid %q", fresh.UID, decoded.UID)
+	}
+
+	cookie, err := c.SessionCookie(ctx, idToken, expiresIn)
+	if err != nil {
+		return nil, "", err
+	}
+	return decoded, cookie, nil
+}
+
+// CSRFToken creates a CSRF token that is bound to the given decoded session cookie.
+//
+// The returned token is intended for the double-submit cookie pattern: send it to the client in a
+// script-readable cookie or page, and require the client to echo it back in a request header or
+// form field on state-changing requests. Use VerifyCSRFToken to check the echoed value.
+//
+// The token is an HMAC computed with the given secret over the user ID and sign-in time of the
+// session, so it remains valid when the session cookie is re-minted by RefreshSessionCookie, but
+// not across different sign-ins. The secret must be kept on the server, and should be at least
+// 32 bytes long.
+func CSRFToken(session *Token, secret []byte) (string, error) {
+	if err := validateCSRFArgs(session, secret); err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, csrfNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	mac := csrfMAC(session, secret, nonce)
+	return fmt.Sprintf("%s.%s",
+		base64.RawURLEncoding.EncodeToString(nonce),
+		base64.RawURLEncoding.EncodeToString(mac)), nil
+}
+
+// VerifyCSRFToken verifies that the given CSRF token was created by CSRFToken for the given
+// decoded session cookie and secret.
+//
+// Use IsCSRFTokenInvalid to check if the returned error was due to an invalid CSRF token.
+func VerifyCSRFToken(session *Token, csrfToken string, secret []byte) error {
+	if err := validateCSRFArgs(session, secret); err != nil {
+		return err
+	}
+
+	segments := strings.Split(csrfToken, ".")
+	if len(segments) != 2 {
+		return newCSRFTokenError("csrf token is malformed")
+	}
+	nonce, err := base64.RawURLEncoding.DecodeString(segments[0])
+	if err != nil || len(nonce) != csrfNonceSize {
+		return newCSRFTokenError("csrf token is malformed")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(segments[1])
+	if err != nil {
+		return newCSRFTokenError("csrf token is malformed")
+	}
+
+	if !hmac.Equal(mac, csrfMAC(session, secret, nonce)) {
+		return newCSRFTokenError("csrf token does not match the session")
+	}
+	return nil
+}
+
+// IsCSRFTokenInvalid checks if the given error was due to an invalid CSRF token.
+func IsCSRFTokenInvalid(err error) bool {
+	return hasAuthErrorCode(err, csrfTokenInvalid)
+}
+
+func validateCSRFArgs(session *Token, secret []byte) error {
+	if session == nil || session.UID == "" {
+		return errors.New("session must be a decoded session cookie with a non-empty uid")
+	}
+	if len(secret) == 0 {
+		return errors.New("secret must not be empty")
+	}
+	return nil
+}
+
+func csrfMAC(session *Token, secret, nonce []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(nonce)
+	fmt.Fprintf(h, "%s|%d", session.UID, session.AuthTime)
+	return h.Sum(nil)
+}
+
+func newCSRFTokenError(msg string) error {
+	return &internal.FirebaseError{
+		ErrorCode: internal.InvalidArgument,
+		String:    msg,
+		Ext: map[string]interface{}{
+			authErrorCode: csrfTokenInvalid,
+		},
+	}
+}
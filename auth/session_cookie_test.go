@@ -0,0 +1,269 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionHTTPCookie(t *testing.T) {
+	s := echoServer([]byte(`{"sessionCookie": "expectedCookie"}`), t)
+	defer s.Close()
+	s.Client.clock = testClock
+
+	cookie, err := s.Client.SessionHTTPCookie(context.Background(), "idToken", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &http.Cookie{
+		Name:     "session",
+		Value:    "expectedCookie",
+		Path:     "/",
+		Expires:  testClock.Now().Add(time.Hour),
+		MaxAge:   3600,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if cookie.String() != want.String() {
+		t.Errorf("SessionHTTPCookie() = %q; want = %q", cookie.String(), want.String())
+	}
+
+	cookie, err = s.Client.SessionHTTPCookie(context.Background(), "idToken", time.Hour, &SessionCookieOptions{
+		Name:     "__session",
+		Domain:   "example.com",
+		Path:     "/app",
+		SameSite: http.SameSiteStrictMode,
+		Insecure: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Name = "__session"
+	want.Domain = "example.com"
+	want.Path = "/app"
+	want.SameSite = http.SameSiteStrictMode
+	want.Secure = false
+	if cookie.String() != want.String() {
+		t.Errorf("SessionHTTPCookie() = %q; want = %q", cookie.String(), want.String())
+	}
+}
+
+func TestSessionHTTPCookieError(t *testing.T) {
+	s := echoServer([]byte(`{"sessionCookie": "expectedCookie"}`), t)
+	defer s.Close()
+
+	cookie, err := s.Client.SessionHTTPCookie(context.Background(), "", time.Hour, nil)
+	if cookie != nil || err == nil {
+		t.Errorf("SessionHTTPCookie() = (%v, %v); want = (nil, error)", cookie, err)
+	}
+	if len(s.Req) != 0 {
+		t.Errorf("Requests = %d; want = 0", len(s.Req))
+	}
+}
+
+func TestRefreshSessionCookieNotDue(t *testing.T) {
+	s := sessionCookieServer(t)
+	defer s.Close()
+
+	decoded, cookie, err := s.Client.RefreshSessionCookie(
+		context.Background(), testSessionCookie, "", 24*time.Hour, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded == nil || decoded.UID != "1234567890" || cookie != "" {
+		t.Errorf("RefreshSessionCookie() = (%v, %q); want = (token, \"\")", decoded, cookie)
+	}
+	if len(s.Req) != 1 {
+		t.Errorf("Requests = %d; want = 1", len(s.Req))
+	}
+}
+
+func TestRefreshSessionCookie(t *testing.T) {
+	s := sessionCookieServer(t)
+	defer s.Close()
+
+	decoded, cookie, err := s.Client.RefreshSessionCookie(
+		context.Background(), testSessionCookie, testIDToken, 24*time.Hour, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded == nil || decoded.UID != "1234567890" || cookie != "expectedCookie" {
+		t.Errorf("RefreshSessionCookie() = (%v, %q); want = (token, %q)", decoded, cookie, "expectedCookie")
+	}
+	if len(s.Req) != 3 {
+		t.Fatalf("Requests = %d; want = 3", len(s.Req))
+	}
+	if got := s.Req[2].URL.Path; !strings.HasSuffix(got, ":createSessionCookie") {
+		t.Errorf("Path = %q; want = createSessionCookie", got)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(s.Rbody, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["idToken"] != testIDToken || body["validDuration"] != float64(86400) {
+		t.Errorf("createSessionCookie request = %v; want = {idToken, 86400}", body)
+	}
+}
+
+func TestRefreshSessionCookieError(t *testing.T) {
+	otherUser := getIDToken(mockIDTokenPayload{"sub": "other-user"})
+	cases := []struct {
+		name    string
+		cookie  string
+		idToken string
+	}{
+		{"InvalidCookie", "invalid", testIDToken},
+		{"RevokedCookie", getSessionCookie(mockIDTokenPayload{"iat": 1970}), testIDToken},
+		{"NoIDToken", testSessionCookie, ""},
+		{"InvalidIDToken", testSessionCookie, "invalid"},
+		{"UIDMismatch", testSessionCookie, otherUser},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := sessionCookieServer(t)
+			defer s.Close()
+
+			decoded, cookie, err := s.Client.RefreshSessionCookie(
+				context.Background(), tc.cookie, tc.idToken, 24*time.Hour, 2*time.Hour)
+			if decoded != nil || cookie != "" || err == nil {
+				t.Errorf("RefreshSessionCookie() = (%v, %q, %v); want = (nil, \"\", error)", decoded, cookie, err)
+			}
+			for _, r := range s.Req {
+				if strings.HasSuffix(r.URL.Path, ":createSessionCookie") {
+					t.Errorf("RefreshSessionCookie() created a new session cookie")
+				}
+			}
+		})
+	}
+}
+
+func TestSessionCookieExpiresWithin(t *testing.T) {
+	client := &Client{baseClient: &baseClient{clock: testClock}}
+	token := &Token{Expires: testClock.Now().Add(time.Hour).Unix()}
+
+	if client.SessionCookieExpiresWithin(token, 59*time.Minute) {
+		t.Errorf("SessionCookieExpiresWithin(59m) = true; want = false")
+	}
+	if !client.SessionCookieExpiresWithin(token, time.Hour) {
+		t.Errorf("SessionCookieExpiresWithin(1h) = false; want = true")
+	}
+}
+
+func TestCSRFToken(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	session := &Token{UID: "uid1", AuthTime: 1000}
+
+	token, err := CSRFToken(session, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := CSRFToken(session, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token == other {
+		t.Errorf("CSRFToken() returned the same token twice: %q", token)
+	}
+
+	for _, tok := range []string{token, other} {
+		if err := VerifyCSRFToken(session, tok, secret); err != nil {
+			t.Errorf("VerifyCSRFToken() = %v; want = nil", err)
+		}
+	}
+
+	// Re-minted session cookies from the same sign-in share the CSRF token.
+	reminted := &Token{UID: "uid1", AuthTime: 1000, IssuedAt: 5000}
+	if err := VerifyCSRFToken(reminted, token, secret); err != nil {
+		t.Errorf("VerifyCSRFToken(reminted) = %v; want = nil", err)
+	}
+}
+
+func TestVerifyCSRFTokenError(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	session := &Token{UID: "uid1", AuthTime: 1000}
+	token, err := CSRFToken(session, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		session *Token
+		token   string
+		secret  []byte
+	}{
+		{"OtherUser", &Token{UID: "uid2", AuthTime: 1000}, token, secret},
+		{"OtherSignIn", &Token{UID: "uid1", AuthTime: 2000}, token, secret},
+		{"OtherSecret", session, token, []byte("another-secret")},
+		{"Empty", session, "", secret},
+		{"Malformed", session, "not-a-token", secret},
+		{"BadNonce", session, "!!." + strings.Split(token, ".")[1], secret},
+		{"TruncatedNonce", session, "AAAA." + strings.Split(token, ".")[1], secret},
+		{"BadMAC", session, strings.Split(token, ".")[0] + ".!!", secret},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyCSRFToken(tc.session, tc.token, tc.secret)
+			if !IsCSRFTokenInvalid(err) {
+				t.Errorf("VerifyCSRFToken() = %v; want = CSRFTokenInvalid", err)
+			}
+		})
+	}
+}
+
+func TestCSRFTokenInvalidArgs(t *testing.T) {
+	secret := []byte("secret")
+	cases := []struct {
+		name    string
+		session *Token
+		secret  []byte
+	}{
+		{"NilSession", nil, secret},
+		{"EmptyUID", &Token{}, secret},
+		{"EmptySecret", &Token{UID: "uid1"}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if token, err := CSRFToken(tc.session, tc.secret); token != "" || err == nil {
+				t.Errorf("CSRFToken() = (%q, %v); want = (\"\", error)", token, err)
+			}
+			err := VerifyCSRFToken(tc.session, "a.b", tc.secret)
+			if err == nil || IsCSRFTokenInvalid(err) {
+				t.Errorf("VerifyCSRFToken() = %v; want = argument error", err)
+			}
+		})
+	}
+}
+
+func sessionCookieServer(t *testing.T) *mockAuthServer {
+	var resp map[string]interface{}
+	if err := json.Unmarshal(testGetUserResponse, &resp); err != nil {
+		t.Fatal(err)
+	}
+	resp["sessionCookie"] = "expectedCookie"
+
+	s := echoServer(resp, t)
+	s.Client.idTokenVerifier = testIDTokenVerifier
+	s.Client.cookieVerifier = testCookieVerifier
+	s.Client.clock = testClock
+	return s
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var regionCodePattern = regexp.MustCompile("^[A-Z]{2}$")
+
+// SMSRegionConfig represents the regions to which SMS verification codes can be sent.
+//
+// Exactly one of AllowByDefault and AllowlistOnly must be set.
+type SMSRegionConfig struct {
+	// Allows SMS to all regions except the disallowed ones.
+	AllowByDefault *AllowByDefaultSMSRegions `json:"allowByDefault,omitempty"`
+	// Only allows SMS to the allowed regions.
+	AllowlistOnly *AllowlistOnlySMSRegions `json:"allowlistOnly,omitempty"`
+}
+
+// AllowByDefaultSMSRegions lists the regions to which SMS cannot be sent. Regions are identified
+// by two letter ISO 3166 country codes.
+type AllowByDefaultSMSRegions struct {
+	DisallowedRegions []string `json:"disallowedRegions,omitempty"`
+}
+
+// AllowlistOnlySMSRegions lists the only regions to which SMS can be sent. Regions are identified
+// by two letter ISO 3166 country codes.
+type AllowlistOnlySMSRegions struct {
+	AllowedRegions []string `json:"allowedRegions,omitempty"`
+}
+
+func (s SMSRegionConfig) validate() error {
+	if (s.AllowByDefault == nil) == (s.AllowlistOnly == nil) {
+		return fmt.Errorf("exactly one of \"SMSRegionConfig.AllowByDefault\" and \"SMSRegionConfig.AllowlistOnly\" must be defined")
+	}
+	var regions []string
+	if s.AllowByDefault != nil {
+		regions = s.AllowByDefault.DisallowedRegions
+	} else {
+		regions = s.AllowlistOnly.AllowedRegions
+	}
+	for _, region := range regions {
+		if !regionCodePattern.MatchString(region) {
+			return fmt.Errorf("%q is not a valid two letter ISO 3166 region code", region)
+		}
+	}
+	return nil
+}
@@ -43,12 +43,16 @@ import (
 // All other settings of a tenant will also be inherited. These will need to be managed from the
 // Cloud Console UI.
 type Tenant struct {
-	ID                    string             `json:"name"`
-	DisplayName           string             `json:"displayName"`
-	AllowPasswordSignUp   bool               `json:"allowPasswordSignup"`
-	EnableEmailLinkSignIn bool               `json:"enableEmailLinkSignin"`
-	EnableAnonymousUsers  bool               `json:"enableAnonymousUser"`
-	MultiFactorConfig     *MultiFactorConfig `json:"mfaConfig"`
+	ID                    string                `json:"name"`
+	DisplayName           string                `json:"displayName"`
+	AllowPasswordSignUp   bool                  `json:"allowPasswordSignup"`
+	EnableEmailLinkSignIn bool                  `json:"enableEmailLinkSignin"`
+	EnableAnonymousUsers  bool                  `json:"enableAnonymousUser"`
+	MultiFactorConfig     *MultiFactorConfig    `json:"mfaConfig"`
+	PasswordPolicyConfig  *PasswordPolicyConfig `json:"passwordPolicyConfig,omitempty"`
+	EmailPrivacyConfig    *EmailPrivacyConfig   `json:"emailPrivacyConfig,omitempty"`
+	RecaptchaConfig       *RecaptchaConfig      `json:"recaptchaConfig,omitempty"`
+	SMSRegionConfig       *SMSRegionConfig      `json:"smsRegionConfig,omitempty"`
 }
 
 // TenantClient is used for managing users, configuring SAML/OIDC providers, and generating email
@@ -258,6 +262,26 @@ func (t *TenantToCreate) MultiFactorConfig(multiFactorConfig MultiFactorConfig)
 	return t.set(multiFactorConfigTenantKey, multiFactorConfig)
 }
 
+// PasswordPolicyConfig configures the tenant's password policy
+func (t *TenantToCreate) PasswordPolicyConfig(config PasswordPolicyConfig) *TenantToCreate {
+	return t.set(passwordPolicyConfigKey, config)
+}
+
+// EmailPrivacyConfig configures the tenant's email privacy settings
+func (t *TenantToCreate) EmailPrivacyConfig(config EmailPrivacyConfig) *TenantToCreate {
+	return t.set(emailPrivacyConfigKey, config)
+}
+
+// RecaptchaConfig configures the tenant's reCAPTCHA Enterprise settings
+func (t *TenantToCreate) RecaptchaConfig(config RecaptchaConfig) *TenantToCreate {
+	return t.set(recaptchaConfigKey, config.withoutKeys())
+}
+
+// SMSRegionConfig configures the regions to which the tenant can send SMS
+func (t *TenantToCreate) SMSRegionConfig(config SMSRegionConfig) *TenantToCreate {
+	return t.set(smsRegionConfigKey, config)
+}
+
 func (t *TenantToCreate) set(key string, value interface{}) *TenantToCreate {
 	t.ensureParams().Set(key, value)
 	return t
@@ -285,7 +309,7 @@ func (t *TenantToCreate) validate() error {
 			return err
 		}
 	}
-	return nil
+	return validateAuthConfigs(t.params)
 }
 
 // TenantToUpdate represents the options used to update an existing tenant.
@@ -320,6 +344,26 @@ func (t *TenantToUpdate) MultiFactorConfig(multiFactorConfig MultiFactorConfig)
 	return t.set(multiFactorConfigTenantKey, multiFactorConfig)
 }
 
+// PasswordPolicyConfig configures the tenant's password policy
+func (t *TenantToUpdate) PasswordPolicyConfig(config PasswordPolicyConfig) *TenantToUpdate {
+	return t.set(passwordPolicyConfigKey, config)
+}
+
+// EmailPrivacyConfig configures the tenant's email privacy settings
+func (t *TenantToUpdate) EmailPrivacyConfig(config EmailPrivacyConfig) *TenantToUpdate {
+	return t.set(emailPrivacyConfigKey, config)
+}
+
+// RecaptchaConfig configures the tenant's reCAPTCHA Enterprise settings
+func (t *TenantToUpdate) RecaptchaConfig(config RecaptchaConfig) *TenantToUpdate {
+	return t.set(recaptchaConfigKey, config.withoutKeys())
+}
+
+// SMSRegionConfig configures the regions to which the tenant can send SMS
+func (t *TenantToUpdate) SMSRegionConfig(config SMSRegionConfig) *TenantToUpdate {
+	return t.set(smsRegionConfigKey, config)
+}
+
 func (t *TenantToUpdate) set(key string, value interface{}) *TenantToUpdate {
 	if t.params == nil {
 		t.params = make(nestedMap)
@@ -344,7 +388,7 @@ func (t *TenantToUpdate) validate() error {
 			return err
 		}
 	}
-	return nil
+	return validateAuthConfigs(t.params)
 }
 
 // TenantIterator is an iterator over tenants.
@@ -1424,6 +1424,52 @@ func TestUpdateTenant(t *testing.T) {
 	}
 }
 
+func TestUpdateTenantAuthConfigs(t *testing.T) {
+	s := echoServer([]byte(tenantResponse), t)
+	defer s.Close()
+
+	options := (&TenantToUpdate{}).
+		PasswordPolicyConfig(PasswordPolicyConfig{EnforcementState: PasswordPolicyEnforcementOff}).
+		EmailPrivacyConfig(EmailPrivacyConfig{EnableImprovedEmailPrivacy: true}).
+		RecaptchaConfig(RecaptchaConfig{PhoneEnforcementState: RecaptchaProviderEnforcementAudit}).
+		SMSRegionConfig(SMSRegionConfig{AllowByDefault: &AllowByDefaultSMSRegions{}})
+	if _, err := s.Client.TenantManager.UpdateTenant(context.Background(), "tenantID", options); err != nil {
+		t.Fatal(err)
+	}
+
+	wantBody := map[string]interface{}{
+		"passwordPolicyConfig": map[string]interface{}{
+			"passwordPolicyEnforcementState": "OFF",
+		},
+		"emailPrivacyConfig": map[string]interface{}{
+			"enableImprovedEmailPrivacy": true,
+		},
+		"recaptchaConfig": map[string]interface{}{
+			"phoneEnforcementState": "AUDIT",
+		},
+		"smsRegionConfig": map[string]interface{}{
+			"allowByDefault": map[string]interface{}{},
+		},
+	}
+	wantMask := []string{"emailPrivacyConfig", "passwordPolicyConfig", "recaptchaConfig", "smsRegionConfig"}
+	if err := checkUpdateTenantRequest(s, wantBody, wantMask); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTenantInvalidAuthConfigs(t *testing.T) {
+	tm := &TenantManager{}
+	want := `exactly one of "SMSRegionConfig.AllowByDefault" and "SMSRegionConfig.AllowlistOnly" must be defined`
+	create := (&TenantToCreate{}).SMSRegionConfig(SMSRegionConfig{})
+	if _, err := tm.CreateTenant(context.Background(), create); err == nil || err.Error() != want {
+		t.Errorf("CreateTenant() = %v; want = %q", err, want)
+	}
+	update := (&TenantToUpdate{}).SMSRegionConfig(SMSRegionConfig{})
+	if _, err := tm.UpdateTenant(context.Background(), "tenantID", update); err == nil || err.Error() != want {
+		t.Errorf("UpdateTenant() = %v; want = %q", err, want)
+	}
+}
+
 func TestUpdateTenantMinimal(t *testing.T) {
 	s := echoServer([]byte(tenantResponse), t)
 	defer s.Close()
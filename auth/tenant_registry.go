@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// TenantClientRegistry caches TenantClient instances by tenant ID, and routes ID tokens to the
+// TenantClient of their tenant. It is safe for concurrent use.
+//
+// A registry can optionally be restricted to a list of allowed tenants.
+type TenantClientRegistry struct {
+	tm      *TenantManager
+	allowed map[string]bool
+
+	mu      sync.Mutex
+	clients map[string]*TenantClient
+}
+
+// NewTenantClientRegistry creates a new TenantClientRegistry.
+//
+// If allowedTenantIDs are specified, the registry only returns clients for, and only accepts ID
+// tokens of, those tenants. Otherwise all tenants are allowed.
+func (tm *TenantManager) NewTenantClientRegistry(allowedTenantIDs ...string) *TenantClientRegistry {
+	r := &TenantClientRegistry{
+		tm:      tm,
+		clients: make(map[string]*TenantClient),
+	}
+	if len(allowedTenantIDs) > 0 {
+		r.allowed = make(map[string]bool, len(allowedTenantIDs))
+		for _, id := range allowedTenantIDs {
+			r.allowed[id] = true
+		}
+	}
+	return r
+}
+
+// TenantClient returns the TenantClient of the given tenant. The same instance is returned for
+// all the calls with the same tenant ID.
+func (r *TenantClientRegistry) TenantClient(tenantID string) (*TenantClient, error) {
+	if !r.isAllowed(tenantID) {
+		return nil, fmt.Errorf("tenant %q is not allowed", tenantID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tc, ok := r.clients[tenantID]; ok {
+		return tc, nil
+	}
+	tc, err := r.tm.AuthForTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	r.clients[tenantID] = tc
+	return tc, nil
+}
+
+func (r *TenantClientRegistry) isAllowed(tenantID string) bool {
+	return r.allowed == nil || r.allowed[tenantID]
+}
+
+// VerifyIDTokenAnyTenant verifies an ID token issued to a user of any tenant, and checks it with
+// the TenantClient of the tenant in its firebase.tenant claim.
+//
+// Tokens that do not belong to a tenant, and tokens of tenants that are not allowed by the
+// registry, are rejected with an error for which IsTenantIDMismatch returns true. The tenant of a
+// token is only trusted after its signature has been verified. Otherwise, this behaves like
+// TenantClient.VerifyIDToken.
+func (r *TenantClientRegistry) VerifyIDTokenAnyTenant(ctx context.Context, idToken string) (*Token, error) {
+	return r.verifyIDToken(ctx, idToken, false)
+}
+
+// VerifyIDTokenAnyTenantAndCheckRevoked verifies an ID token issued to a user of any tenant like
+// VerifyIDTokenAnyTenant, and additionally checks that the token has not been revoked or disabled.
+func (r *TenantClientRegistry) VerifyIDTokenAnyTenantAndCheckRevoked(
+	ctx context.Context, idToken string) (*Token, error) {
+	return r.verifyIDToken(ctx, idToken, true)
+}
+
+func (r *TenantClientRegistry) verifyIDToken(
+	ctx context.Context, idToken string, checkRevokedOrDisabled bool) (*Token, error) {
+	base := r.tm.base
+	decoded, err := base.idTokenVerifier.VerifyToken(ctx, idToken, base.isEmulator)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := decoded.Firebase.Tenant
+	if tenantID == "" {
+		return nil, newTenantIDMismatchError("ID token does not belong to a tenant")
+	}
+	if !r.isAllowed(tenantID) {
+		return nil, newTenantIDMismatchError(fmt.Sprintf("tenant id is not allowed: %q", tenantID))
+	}
+
+	tc, err := r.TenantClient(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return tc.checkIDToken(ctx, decoded, checkRevokedOrDisabled)
+}
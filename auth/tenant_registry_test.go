@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func getTenantIDToken(tenantID string) string {
+	firebase := map[string]interface{}{
+		"sign_in_provider": "custom",
+	}
+	if tenantID != "" {
+		firebase["tenant"] = tenantID
+	}
+	return getIDToken(mockIDTokenPayload{"firebase": firebase})
+}
+
+func TestTenantClientRegistry(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	registry := s.Client.TenantManager.NewTenantClientRegistry()
+	var wg sync.WaitGroup
+	clients := make([]*TenantClient, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tc, err := registry.TenantClient("tenantID")
+			if err != nil {
+				t.Error(err)
+			}
+			clients[i] = tc
+		}(i)
+	}
+	wg.Wait()
+
+	for _, tc := range clients {
+		if tc != clients[0] || tc.TenantID() != "tenantID" {
+			t.Errorf("TenantClient() = %p (%q); want = %p (%q)", tc, tc.TenantID(), clients[0], "tenantID")
+		}
+	}
+
+	other, err := registry.TenantClient("otherTenantID")
+	if err != nil || other == clients[0] || other.TenantID() != "otherTenantID" {
+		t.Errorf("TenantClient(other) = (%v, %v); want = new client for otherTenantID", other, err)
+	}
+
+	if tc, err := registry.TenantClient(""); tc != nil || err == nil {
+		t.Errorf("TenantClient(\"\") = (%v, %v); want = (nil, error)", tc, err)
+	}
+}
+
+func TestTenantClientRegistryAllowList(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	registry := s.Client.TenantManager.NewTenantClientRegistry("tenant1", "tenant2")
+	if tc, err := registry.TenantClient("tenant2"); err != nil || tc.TenantID() != "tenant2" {
+		t.Errorf("TenantClient(tenant2) = (%v, %v); want = client for tenant2", tc, err)
+	}
+
+	want := `tenant "tenant3" is not allowed`
+	if tc, err := registry.TenantClient("tenant3"); tc != nil || err == nil || err.Error() != want {
+		t.Errorf("TenantClient(tenant3) = (%v, %v); want = (nil, %q)", tc, err, want)
+	}
+}
+
+func TestVerifyIDTokenAnyTenant(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+	s.Client.TenantManager.base.idTokenVerifier = testIDTokenVerifier
+
+	registry := s.Client.TenantManager.NewTenantClientRegistry()
+	for _, tenantID := range []string{"tenant1", "tenant2"} {
+		ft, err := registry.VerifyIDTokenAnyTenant(context.Background(), getTenantIDToken(tenantID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ft.Firebase.Tenant != tenantID {
+			t.Errorf("Tenant = %q; want = %q", ft.Firebase.Tenant, tenantID)
+		}
+	}
+	if len(s.Req) != 0 {
+		t.Errorf("VerifyIDTokenAnyTenant() requests = %d; want = 0", len(s.Req))
+	}
+	if len(registry.clients) != 2 {
+		t.Errorf("VerifyIDTokenAnyTenant() cached clients = %d; want = 2", len(registry.clients))
+	}
+}
+
+func TestVerifyIDTokenAnyTenantAndCheckRevoked(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+	s.Client.TenantManager.base.idTokenVerifier = testIDTokenVerifier
+
+	registry := s.Client.TenantManager.NewTenantClientRegistry()
+	ft, err := registry.VerifyIDTokenAnyTenantAndCheckRevoked(context.Background(), getTenantIDToken("tenant1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft.Firebase.Tenant != "tenant1" {
+		t.Errorf("Tenant = %q; want = %q", ft.Firebase.Tenant, "tenant1")
+	}
+
+	wantURI := "/projects/mock-project-id/tenants/tenant1/accounts:lookup"
+	if len(s.Req) != 1 || s.Req[0].RequestURI != wantURI {
+		t.Errorf("VerifyIDTokenAnyTenantAndCheckRevoked() requests = %d; want = %q", len(s.Req), wantURI)
+	}
+}
+
+func TestVerifyIDTokenAnyTenantMismatch(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+	s.Client.TenantManager.base.idTokenVerifier = testIDTokenVerifier
+
+	registry := s.Client.TenantManager.NewTenantClientRegistry("tenant1")
+	cases := []struct {
+		name    string
+		idToken string
+		want    string
+	}{
+		{"NoTenant", getTenantIDToken(""), "ID token does not belong to a tenant"},
+		{"NotAllowed", getTenantIDToken("tenant2"), `tenant id is not allowed: "tenant2"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ft, err := registry.VerifyIDTokenAnyTenant(context.Background(), tc.idToken)
+			if ft != nil || !IsTenantIDMismatch(err) || err.Error() != tc.want {
+				t.Errorf("VerifyIDTokenAnyTenant() = (%v, %v); want = (nil, %q)", ft, err, tc.want)
+			}
+		})
+	}
+	if len(registry.clients) != 0 {
+		t.Errorf("VerifyIDTokenAnyTenant() cached clients = %d; want = 0", len(registry.clients))
+	}
+}
+
+func TestVerifyIDTokenAnyTenantInvalidToken(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+	s.Client.TenantManager.base.idTokenVerifier = testIDTokenVerifier
+
+	registry := s.Client.TenantManager.NewTenantClientRegistry()
+	idToken := getTenantIDToken("tenant1")
+	ft, err := registry.VerifyIDTokenAnyTenant(context.Background(), idToken[:len(idToken)-8])
+	if ft != nil || !IsIDTokenInvalid(err) {
+		t.Errorf("VerifyIDTokenAnyTenant() = (%v, %v); want = (nil, IDTokenInvalid)", ft, err)
+	}
+	if len(registry.clients) != 0 {
+		t.Errorf("VerifyIDTokenAnyTenant() cached clients = %d; want = 0", len(registry.clients))
+	}
+}
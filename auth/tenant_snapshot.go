@@ -0,0 +1,558 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"google.golang.org/api/iterator"
+)
+
+// ConfigSnapshot is a copy of the configuration of a tenant or a project, including its OIDC and
+// SAML provider configurations.
+//
+// A snapshot does not contain the ID or the display name of the tenant it was taken from, so that
+// it can be applied to any other tenant or project. Settings that are nil are left unchanged when
+// the snapshot is applied. The tenant-only settings AllowPasswordSignUp, EnableEmailLinkSignIn and
+// EnableAnonymousUsers are nil in project snapshots, and are ignored when applying a snapshot to a
+// project.
+type ConfigSnapshot struct {
+	AllowPasswordSignUp   *bool
+	EnableEmailLinkSignIn *bool
+	EnableAnonymousUsers  *bool
+	MultiFactorConfig     *MultiFactorConfig
+	PasswordPolicyConfig  *PasswordPolicyConfig
+	EmailPrivacyConfig    *EmailPrivacyConfig
+	RecaptchaConfig       *RecaptchaConfig
+	SMSRegionConfig       *SMSRegionConfig
+	// The OIDC provider configurations, sorted by ID.
+	OIDCProviderConfigs []*OIDCProviderConfig
+	// The SAML provider configurations, sorted by ID.
+	SAMLProviderConfigs []*SAMLProviderConfig
+}
+
+// ConfigChangeKind is the kind of a ConfigChange.
+type ConfigChangeKind string
+
+const (
+	// ConfigCreate creates a provider configuration.
+	ConfigCreate ConfigChangeKind = "CREATE"
+	// ConfigUpdate updates the settings or a provider configuration.
+	ConfigUpdate ConfigChangeKind = "UPDATE"
+	// ConfigDelete deletes a provider configuration.
+	ConfigDelete ConfigChangeKind = "DELETE"
+)
+
+// ConfigResource is the type of configuration a ConfigChange applies to.
+type ConfigResource string
+
+const (
+	// SettingsResource is the settings of a tenant or a project.
+	SettingsResource ConfigResource = "settings"
+	// OIDCProviderResource is an OIDC provider configuration.
+	OIDCProviderResource ConfigResource = "oidcProviderConfig"
+	// SAMLProviderResource is a SAML provider configuration.
+	SAMLProviderResource ConfigResource = "samlProviderConfig"
+)
+
+// ConfigChange is a single change between two configuration snapshots.
+type ConfigChange struct {
+	Kind     ConfigChangeKind
+	Resource ConfigResource
+	// The ID of the provider configuration. Empty for settings changes.
+	ID string
+	// The names of the ConfigSnapshot, OIDCProviderConfig or SAMLProviderConfig fields that
+	// changed. Empty for create and delete changes.
+	Fields []string
+}
+
+func (c *ConfigChange) String() string {
+	s := fmt.Sprintf("%s %s", c.Kind, c.Resource)
+	if c.ID != "" {
+		s += fmt.Sprintf(" %q", c.ID)
+	}
+	if len(c.Fields) > 0 {
+		s += fmt.Sprintf(" %v", c.Fields)
+	}
+	return s
+}
+
+// ConfigDiff is the list of changes required to turn one configuration snapshot into another.
+//
+// Settings changes come first, followed by the OIDC and the SAML provider configuration changes
+// sorted by ID.
+type ConfigDiff struct {
+	Changes []*ConfigChange
+}
+
+// Empty returns true if the diff has no changes.
+func (d *ConfigDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// ApplySnapshotOptions specifies how a ConfigSnapshot is applied.
+type ApplySnapshotOptions struct {
+	// DryRun computes and returns the changes without applying them.
+	DryRun bool
+	// Prune deletes the provider configurations that are not in the snapshot. By default they are
+	// left unchanged.
+	Prune bool
+}
+
+// DiffSnapshots returns the changes required to turn the current configuration snapshot into the
+// desired one.
+//
+// Settings that are nil in the desired snapshot are not compared. Provider configurations that are
+// only in the current snapshot are reported as deletions. The output-only reCAPTCHA keys are not
+// compared.
+func DiffSnapshots(current, desired *ConfigSnapshot) *ConfigDiff {
+	if current == nil {
+		current = &ConfigSnapshot{}
+	}
+	if desired == nil {
+		desired = &ConfigSnapshot{}
+	}
+
+	diff := &ConfigDiff{}
+	if fields := changedFields(current.settings(), desired.settings(), true); len(fields) > 0 {
+		diff.Changes = append(diff.Changes, &ConfigChange{
+			Kind:     ConfigUpdate,
+			Resource: SettingsResource,
+			Fields:   fields,
+		})
+	}
+
+	currentOIDC := make(map[string]interface{})
+	for _, c := range current.OIDCProviderConfigs {
+		currentOIDC[c.ID] = c
+	}
+	desiredOIDC := make(map[string]interface{})
+	for _, c := range desired.OIDCProviderConfigs {
+		desiredOIDC[c.ID] = c
+	}
+	diff.Changes = append(diff.Changes, diffProviders(OIDCProviderResource, currentOIDC, desiredOIDC)...)
+
+	currentSAML := make(map[string]interface{})
+	for _, c := range current.SAMLProviderConfigs {
+		currentSAML[c.ID] = c
+	}
+	desiredSAML := make(map[string]interface{})
+	for _, c := range desired.SAMLProviderConfigs {
+		desiredSAML[c.ID] = c
+	}
+	diff.Changes = append(diff.Changes, diffProviders(SAMLProviderResource, currentSAML, desiredSAML)...)
+	return diff
+}
+
+func diffProviders(resource ConfigResource, current, desired map[string]interface{}) []*ConfigChange {
+	ids := make([]string, 0, len(current)+len(desired))
+	for id := range current {
+		ids = append(ids, id)
+	}
+	for id := range desired {
+		if _, ok := current[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var changes []*ConfigChange
+	for _, id := range ids {
+		c, inCurrent := current[id]
+		d, inDesired := desired[id]
+		change := &ConfigChange{Resource: resource, ID: id}
+		switch {
+		case !inCurrent:
+			change.Kind = ConfigCreate
+		case !inDesired:
+			change.Kind = ConfigDelete
+		default:
+			change.Kind = ConfigUpdate
+			if change.Fields = changedFields(c, d, false); len(change.Fields) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// changedFields returns the names of the fields that differ between two pointers to structs of
+// the same type. If ignoreNil is true, nil pointer fields of desired are not compared.
+func changedFields(current, desired interface{}, ignoreNil bool) []string {
+	cv := reflect.ValueOf(current).Elem()
+	dv := reflect.ValueOf(desired).Elem()
+	var fields []string
+	for i := 0; i < dv.NumField(); i++ {
+		name := dv.Type().Field(i).Name
+		if name == "ID" {
+			continue
+		}
+		d := dv.Field(i)
+		if ignoreNil && d.Kind() == reflect.Ptr && d.IsNil() {
+			continue
+		}
+		if !reflect.DeepEqual(cv.Field(i).Interface(), d.Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// settings returns a copy of the snapshot without the provider configurations, for comparison. The
+// output-only reCAPTCHA keys are also removed, since they cannot be changed.
+func (s *ConfigSnapshot) settings() *ConfigSnapshot {
+	settings := *s
+	settings.OIDCProviderConfigs = nil
+	settings.SAMLProviderConfigs = nil
+	if s.RecaptchaConfig != nil {
+		recaptcha := *s.RecaptchaConfig
+		recaptcha.RecaptchaKeys = nil
+		settings.RecaptchaConfig = &recaptcha
+	}
+	return &settings
+}
+
+// TenantSnapshot returns a snapshot of the configuration of the tenant with the given ID.
+func (tm *TenantManager) TenantSnapshot(ctx context.Context, tenantID string) (*ConfigSnapshot, error) {
+	tenant, err := tm.Tenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	tc, err := tm.AuthForTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &ConfigSnapshot{
+		AllowPasswordSignUp:   &tenant.AllowPasswordSignUp,
+		EnableEmailLinkSignIn: &tenant.EnableEmailLinkSignIn,
+		EnableAnonymousUsers:  &tenant.EnableAnonymousUsers,
+		MultiFactorConfig:     tenant.MultiFactorConfig,
+		PasswordPolicyConfig:  tenant.PasswordPolicyConfig,
+		EmailPrivacyConfig:    tenant.EmailPrivacyConfig,
+		RecaptchaConfig:       tenant.RecaptchaConfig,
+		SMSRegionConfig:       tenant.SMSRegionConfig,
+	}
+	if err := snapshotProviders(ctx, tc, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// ApplyTenantSnapshot updates the configuration of the tenant with the given ID to match the
+// snapshot, and returns the changes that were applied.
+//
+// Only the settings and the provider configurations that differ from the snapshot are updated,
+// hence applying the same snapshot again makes no changes. Options may be nil. With
+// ApplySnapshotOptions.DryRun, the changes are returned without being applied.
+//
+// The changes are applied one at a time, in the order of the ConfigDiff. If an error occurs, the
+// returned ConfigDiff contains the changes that were applied before the error.
+func (tm *TenantManager) ApplyTenantSnapshot(
+	ctx context.Context, tenantID string, snapshot *ConfigSnapshot, opts *ApplySnapshotOptions) (*ConfigDiff, error) {
+	if snapshot == nil {
+		return nil, errors.New("snapshot must not be nil")
+	}
+	current, err := tm.TenantSnapshot(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	tc, err := tm.AuthForTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return applySnapshot(ctx, tc, current, snapshot, opts, func(fields []string) error {
+		_, err := tm.UpdateTenant(ctx, tenantID, snapshot.tenantToUpdate(fields))
+		return err
+	})
+}
+
+// ProjectSnapshot returns a snapshot of the configuration of the project.
+func (c *Client) ProjectSnapshot(ctx context.Context) (*ConfigSnapshot, error) {
+	config, err := c.GetProjectConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &ConfigSnapshot{
+		MultiFactorConfig:    config.MultiFactorConfig,
+		PasswordPolicyConfig: config.PasswordPolicyConfig,
+		EmailPrivacyConfig:   config.EmailPrivacyConfig,
+		RecaptchaConfig:      config.RecaptchaConfig,
+		SMSRegionConfig:      config.SMSRegionConfig,
+	}
+	if err := snapshotProviders(ctx, c, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// ApplyProjectSnapshot updates the configuration of the project to match the snapshot, and returns
+// the changes that were applied.
+//
+// The tenant-only settings of the snapshot are ignored, so that a tenant snapshot can be applied to
+// a project. Otherwise, this behaves like TenantManager.ApplyTenantSnapshot.
+func (c *Client) ApplyProjectSnapshot(
+	ctx context.Context, snapshot *ConfigSnapshot, opts *ApplySnapshotOptions) (*ConfigDiff, error) {
+	if snapshot == nil {
+		return nil, errors.New("snapshot must not be nil")
+	}
+	current, err := c.ProjectSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := *snapshot
+	desired.AllowPasswordSignUp = nil
+	desired.EnableEmailLinkSignIn = nil
+	desired.EnableAnonymousUsers = nil
+	return applySnapshot(ctx, c, current, &desired, opts, func(fields []string) error {
+		_, err := c.UpdateProjectConfig(ctx, desired.projectConfigToUpdate(fields))
+		return err
+	})
+}
+
+func snapshotProviders(ctx context.Context, client ProviderConfigClient, snapshot *ConfigSnapshot) error {
+	oidc := client.OIDCProviderConfigs(ctx, "")
+	for {
+		config, err := oidc.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		snapshot.OIDCProviderConfigs = append(snapshot.OIDCProviderConfigs, config)
+	}
+	sort.Slice(snapshot.OIDCProviderConfigs, func(i, j int) bool {
+		return snapshot.OIDCProviderConfigs[i].ID < snapshot.OIDCProviderConfigs[j].ID
+	})
+
+	saml := client.SAMLProviderConfigs(ctx, "")
+	for {
+		config, err := saml.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		snapshot.SAMLProviderConfigs = append(snapshot.SAMLProviderConfigs, config)
+	}
+	sort.Slice(snapshot.SAMLProviderConfigs, func(i, j int) bool {
+		return snapshot.SAMLProviderConfigs[i].ID < snapshot.SAMLProviderConfigs[j].ID
+	})
+	return nil
+}
+
+// applySnapshot computes the changes between the current and the desired snapshots, and applies
+// them. Settings changes are applied with updateSettings, and provider changes with client.
+func applySnapshot(
+	ctx context.Context,
+	client ProviderConfigClient,
+	current, desired *ConfigSnapshot,
+	opts *ApplySnapshotOptions,
+	updateSettings func(fields []string) error) (*ConfigDiff, error) {
+	if opts == nil {
+		opts = &ApplySnapshotOptions{}
+	}
+
+	plan := &ConfigDiff{}
+	for _, change := range DiffSnapshots(current, desired).Changes {
+		if change.Kind == ConfigDelete && !opts.Prune {
+			continue
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	oidc := make(map[string]*OIDCProviderConfig)
+	for _, config := range desired.OIDCProviderConfigs {
+		oidc[config.ID] = config
+	}
+	saml := make(map[string]*SAMLProviderConfig)
+	for _, config := range desired.SAMLProviderConfigs {
+		saml[config.ID] = config
+	}
+
+	applied := &ConfigDiff{}
+	for _, change := range plan.Changes {
+		var err error
+		switch change.Resource {
+		case SettingsResource:
+			err = updateSettings(change.Fields)
+		case OIDCProviderResource:
+			err = applyOIDCChange(ctx, client, change, oidc[change.ID])
+		case SAMLProviderResource:
+			err = applySAMLChange(ctx, client, change, saml[change.ID])
+		}
+		if err != nil {
+			return applied, err
+		}
+		applied.Changes = append(applied.Changes, change)
+	}
+	return applied, nil
+}
+
+func applyOIDCChange(ctx context.Context, client ProviderConfigClient, change *ConfigChange, config *OIDCProviderConfig) error {
+	switch change.Kind {
+	case ConfigCreate:
+		create := (&OIDCProviderConfigToCreate{}).
+			ID(config.ID).
+			ClientID(config.ClientID).
+			Issuer(config.Issuer).
+			Enabled(config.Enabled).
+			IDTokenResponseType(config.IDTokenResponseType).
+			CodeResponseType(config.CodeResponseType)
+		if config.DisplayName != "" {
+			create.DisplayName(config.DisplayName)
+		}
+		if config.ClientSecret != "" {
+			create.ClientSecret(config.ClientSecret)
+		}
+		_, err := client.CreateOIDCProviderConfig(ctx, create)
+		return err
+
+	case ConfigUpdate:
+		update := &OIDCProviderConfigToUpdate{}
+		for _, field := range change.Fields {
+			switch field {
+			case "DisplayName":
+				update.DisplayName(config.DisplayName)
+			case "Enabled":
+				update.Enabled(config.Enabled)
+			case "ClientID":
+				update.ClientID(config.ClientID)
+			case "Issuer":
+				update.Issuer(config.Issuer)
+			case "ClientSecret":
+				update.ClientSecret(config.ClientSecret)
+			case "CodeResponseType", "IDTokenResponseType":
+				// The response types are validated together, and the code flow requires the client
+				// secret.
+				update.IDTokenResponseType(config.IDTokenResponseType)
+				update.CodeResponseType(config.CodeResponseType)
+				if config.CodeResponseType {
+					update.ClientSecret(config.ClientSecret)
+				}
+			}
+		}
+		_, err := client.UpdateOIDCProviderConfig(ctx, change.ID, update)
+		return err
+
+	default:
+		return client.DeleteOIDCProviderConfig(ctx, change.ID)
+	}
+}
+
+func applySAMLChange(ctx context.Context, client ProviderConfigClient, change *ConfigChange, config *SAMLProviderConfig) error {
+	switch change.Kind {
+	case ConfigCreate:
+		create := (&SAMLProviderConfigToCreate{}).
+			ID(config.ID).
+			IDPEntityID(config.IDPEntityID).
+			SSOURL(config.SSOURL).
+			RequestSigningEnabled(config.RequestSigningEnabled).
+			X509Certificates(config.X509Certificates).
+			RPEntityID(config.RPEntityID).
+			CallbackURL(config.CallbackURL).
+			Enabled(config.Enabled)
+		if config.DisplayName != "" {
+			create.DisplayName(config.DisplayName)
+		}
+		_, err := client.CreateSAMLProviderConfig(ctx, create)
+		return err
+
+	case ConfigUpdate:
+		update := &SAMLProviderConfigToUpdate{}
+		for _, field := range change.Fields {
+			switch field {
+			case "DisplayName":
+				update.DisplayName(config.DisplayName)
+			case "Enabled":
+				update.Enabled(config.Enabled)
+			case "IDPEntityID":
+				update.IDPEntityID(config.IDPEntityID)
+			case "SSOURL":
+				update.SSOURL(config.SSOURL)
+			case "RequestSigningEnabled":
+				update.RequestSigningEnabled(config.RequestSigningEnabled)
+			case "X509Certificates":
+				update.X509Certificates(config.X509Certificates)
+			case "RPEntityID":
+				update.RPEntityID(config.RPEntityID)
+			case "CallbackURL":
+				update.CallbackURL(config.CallbackURL)
+			}
+		}
+		_, err := client.UpdateSAMLProviderConfig(ctx, change.ID, update)
+		return err
+
+	default:
+		return client.DeleteSAMLProviderConfig(ctx, change.ID)
+	}
+}
+
+func (s *ConfigSnapshot) tenantToUpdate(fields []string) *TenantToUpdate {
+	update := &TenantToUpdate{}
+	for _, field := range fields {
+		switch field {
+		case "AllowPasswordSignUp":
+			update.AllowPasswordSignUp(*s.AllowPasswordSignUp)
+		case "EnableEmailLinkSignIn":
+			update.EnableEmailLinkSignIn(*s.EnableEmailLinkSignIn)
+		case "EnableAnonymousUsers":
+			update.EnableAnonymousUsers(*s.EnableAnonymousUsers)
+		case "MultiFactorConfig":
+			update.MultiFactorConfig(*s.MultiFactorConfig)
+		case "PasswordPolicyConfig":
+			update.PasswordPolicyConfig(*s.PasswordPolicyConfig)
+		case "EmailPrivacyConfig":
+			update.EmailPrivacyConfig(*s.EmailPrivacyConfig)
+		case "RecaptchaConfig":
+			update.RecaptchaConfig(*s.RecaptchaConfig)
+		case "SMSRegionConfig":
+			update.SMSRegionConfig(*s.SMSRegionConfig)
+		}
+	}
+	return update
+}
+
+func (s *ConfigSnapshot) projectConfigToUpdate(fields []string) *ProjectConfigToUpdate {
+	update := &ProjectConfigToUpdate{}
+	for _, field := range fields {
+		switch field {
+		case "MultiFactorConfig":
+			update.MultiFactorConfig(*s.MultiFactorConfig)
+		case "PasswordPolicyConfig":
+			update.PasswordPolicyConfig(*s.PasswordPolicyConfig)
+		case "EmailPrivacyConfig":
+			update.EmailPrivacyConfig(*s.EmailPrivacyConfig)
+		case "RecaptchaConfig":
+			update.RecaptchaConfig(*s.RecaptchaConfig)
+		case "SMSRegionConfig":
+			update.SMSRegionConfig(*s.SMSRegionConfig)
+		}
+	}
+	return update
+}
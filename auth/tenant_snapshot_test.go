@@ -0,0 +1,352 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const snapshotTenantResponse = `{
+	"name": "projects/mock-project-id/tenants/tenantID",
+	"displayName": "Test Tenant",
+	"allowPasswordSignup": true,
+	"enableEmailLinkSignin": false,
+	"enableAnonymousUser": true,
+	"emailPrivacyConfig": {"enableImprovedEmailPrivacy": true},
+	"recaptchaConfig": {
+		"emailPasswordEnforcementState": "AUDIT",
+		"recaptchaKeys": [{"type": "WEB", "key": "projects/p/keys/key"}]
+	}
+}`
+
+const snapshotOIDCResponse = `{
+	"oauthIdpConfigs": [
+		{
+			"name": "projects/mock-project-id/tenants/tenantID/oauthIdpConfigs/oidc.b",
+			"clientId": "CLIENT_B",
+			"issuer": "https://oidc.com/b",
+			"enabled": false,
+			"responseType": {"idToken": true}
+		},
+		{
+			"name": "projects/mock-project-id/tenants/tenantID/oauthIdpConfigs/oidc.a",
+			"clientId": "CLIENT_A",
+			"issuer": "https://oidc.com/a",
+			"enabled": true,
+			"responseType": {"idToken": true}
+		}
+	]
+}`
+
+const snapshotSAMLResponse = `{
+	"inboundSamlConfigs": [
+		{
+			"name": "projects/mock-project-id/tenants/tenantID/inboundSamlConfigs/saml.old",
+			"idpConfig": {
+				"idpEntityId": "IDP_ENTITY_ID",
+				"ssoUrl": "https://example.com/login",
+				"idpCertificates": [{"x509Certificate": "CERT1"}]
+			},
+			"spConfig": {
+				"spEntityId": "RP_ENTITY_ID",
+				"callbackUri": "https://example.com/callback"
+			},
+			"enabled": true
+		}
+	]
+}`
+
+type snapshotRequest struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+// snapshotServer serves a tenant with the snapshotTenantResponse settings, or a project with the
+// same settings, along with the OIDC and SAML provider configurations above. All the requests
+// other than GET requests are recorded.
+func snapshotServer(t *testing.T) (*mockAuthServer, *[]snapshotRequest) {
+	var writes []snapshotRequest
+	s := echoServer(nil, t)
+	s.Srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/projects/mock-project-id")
+		path = strings.TrimPrefix(path, "/tenants/tenantID")
+		if r.Method != http.MethodGet {
+			var body map[string]interface{}
+			if b, _ := ioutil.ReadAll(r.Body); len(b) > 0 {
+				if err := json.Unmarshal(b, &body); err != nil {
+					t.Error(err)
+				}
+			}
+			writes = append(writes, snapshotRequest{r.Method, path, body})
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		switch path {
+		case "", "/config":
+			w.Write([]byte(snapshotTenantResponse))
+		case "/oauthIdpConfigs":
+			w.Write([]byte(snapshotOIDCResponse))
+		case "/inboundSamlConfigs":
+			w.Write([]byte(snapshotSAMLResponse))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	return s, &writes
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+var wantTenantSnapshot = &ConfigSnapshot{
+	AllowPasswordSignUp:   boolPtr(true),
+	EnableEmailLinkSignIn: boolPtr(false),
+	EnableAnonymousUsers:  boolPtr(true),
+	EmailPrivacyConfig:    &EmailPrivacyConfig{EnableImprovedEmailPrivacy: true},
+	RecaptchaConfig: &RecaptchaConfig{
+		EmailPasswordEnforcementState: "AUDIT",
+		RecaptchaKeys:                 []*RecaptchaKey{{Type: "WEB", Key: "projects/p/keys/key"}},
+	},
+	OIDCProviderConfigs: []*OIDCProviderConfig{
+		{ID: "oidc.a", ClientID: "CLIENT_A", Issuer: "https://oidc.com/a", Enabled: true, IDTokenResponseType: true},
+		{ID: "oidc.b", ClientID: "CLIENT_B", Issuer: "https://oidc.com/b", IDTokenResponseType: true},
+	},
+	SAMLProviderConfigs: []*SAMLProviderConfig{
+		{
+			ID:               "saml.old",
+			Enabled:          true,
+			IDPEntityID:      "IDP_ENTITY_ID",
+			SSOURL:           "https://example.com/login",
+			X509Certificates: []string{"CERT1"},
+			RPEntityID:       "RP_ENTITY_ID",
+			CallbackURL:      "https://example.com/callback",
+		},
+	},
+}
+
+// desiredSnapshot returns a snapshot that disables password sign up, changes the reCAPTCHA
+// enforcement state, enables oidc.b, adds oidc.c and saml.new, and does not have saml.old.
+func desiredSnapshot() *ConfigSnapshot {
+	return &ConfigSnapshot{
+		AllowPasswordSignUp: boolPtr(false),
+		EmailPrivacyConfig:  &EmailPrivacyConfig{EnableImprovedEmailPrivacy: true},
+		RecaptchaConfig: &RecaptchaConfig{
+			EmailPasswordEnforcementState: "ENFORCE",
+			RecaptchaKeys:                 []*RecaptchaKey{{Type: "WEB", Key: "projects/other/keys/key"}},
+		},
+		OIDCProviderConfigs: []*OIDCProviderConfig{
+			{ID: "oidc.a", ClientID: "CLIENT_A", Issuer: "https://oidc.com/a", Enabled: true, IDTokenResponseType: true},
+			{ID: "oidc.b", ClientID: "CLIENT_B", Issuer: "https://oidc.com/b", Enabled: true, IDTokenResponseType: true},
+			{
+				ID:               "oidc.c",
+				ClientID:         "CLIENT_C",
+				Issuer:           "https://oidc.com/c",
+				ClientSecret:     "SECRET",
+				CodeResponseType: true,
+			},
+		},
+		SAMLProviderConfigs: []*SAMLProviderConfig{
+			{
+				ID:               "saml.new",
+				DisplayName:      "New",
+				IDPEntityID:      "IDP_ENTITY_ID",
+				SSOURL:           "https://example.com/login",
+				X509Certificates: []string{"CERT2"},
+				RPEntityID:       "RP_ENTITY_ID",
+				CallbackURL:      "https://example.com/callback",
+			},
+		},
+	}
+}
+
+var wantSnapshotChanges = []*ConfigChange{
+	{Kind: ConfigUpdate, Resource: SettingsResource, Fields: []string{"AllowPasswordSignUp", "RecaptchaConfig"}},
+	{Kind: ConfigUpdate, Resource: OIDCProviderResource, ID: "oidc.b", Fields: []string{"Enabled"}},
+	{Kind: ConfigCreate, Resource: OIDCProviderResource, ID: "oidc.c"},
+	{Kind: ConfigCreate, Resource: SAMLProviderResource, ID: "saml.new"},
+	{Kind: ConfigDelete, Resource: SAMLProviderResource, ID: "saml.old"},
+}
+
+func TestTenantSnapshot(t *testing.T) {
+	s, _ := snapshotServer(t)
+	defer s.Close()
+
+	snapshot, err := s.Client.TenantManager.TenantSnapshot(context.Background(), "tenantID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snapshot, wantTenantSnapshot) {
+		t.Errorf("TenantSnapshot() = %#v; want = %#v", snapshot, wantTenantSnapshot)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	diff := DiffSnapshots(wantTenantSnapshot, desiredSnapshot())
+	if !reflect.DeepEqual(diff.Changes, wantSnapshotChanges) {
+		t.Errorf("DiffSnapshots() = %v; want = %v", diff.Changes, wantSnapshotChanges)
+	}
+
+	if diff := DiffSnapshots(wantTenantSnapshot, wantTenantSnapshot); !diff.Empty() {
+		t.Errorf("DiffSnapshots(same) = %v; want = empty", diff.Changes)
+	}
+	if diff := DiffSnapshots(wantTenantSnapshot, &ConfigSnapshot{}); len(diff.Changes) != 3 {
+		t.Errorf("DiffSnapshots(empty) = %v; want = 3 deletions", diff.Changes)
+	}
+	if diff := DiffSnapshots(nil, nil); !diff.Empty() {
+		t.Errorf("DiffSnapshots(nil, nil) = %v; want = empty", diff.Changes)
+	}
+}
+
+func TestApplyTenantSnapshot(t *testing.T) {
+	s, writes := snapshotServer(t)
+	defer s.Close()
+
+	diff, err := s.Client.TenantManager.ApplyTenantSnapshot(
+		context.Background(), "tenantID", desiredSnapshot(), &ApplySnapshotOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(diff.Changes, wantSnapshotChanges) {
+		t.Errorf("ApplyTenantSnapshot() = %v; want = %v", diff.Changes, wantSnapshotChanges)
+	}
+
+	wantWrites := []snapshotRequest{
+		{"PATCH", "", map[string]interface{}{
+			"allowPasswordSignup": false,
+			"recaptchaConfig":     map[string]interface{}{"emailPasswordEnforcementState": "ENFORCE"},
+		}},
+		{"PATCH", "/oauthIdpConfigs/oidc.b", map[string]interface{}{"enabled": true}},
+		{"POST", "/oauthIdpConfigs", map[string]interface{}{
+			"clientId":     "CLIENT_C",
+			"issuer":       "https://oidc.com/c",
+			"enabled":      false,
+			"clientSecret": "SECRET",
+			"responseType": map[string]interface{}{"code": true, "idToken": false},
+		}},
+		{"POST", "/inboundSamlConfigs", map[string]interface{}{
+			"displayName": "New",
+			"enabled":     false,
+			"idpConfig": map[string]interface{}{
+				"idpEntityId":     "IDP_ENTITY_ID",
+				"ssoUrl":          "https://example.com/login",
+				"signRequest":     false,
+				"idpCertificates": []interface{}{map[string]interface{}{"x509Certificate": "CERT2"}},
+			},
+			"spConfig": map[string]interface{}{
+				"spEntityId":  "RP_ENTITY_ID",
+				"callbackUri": "https://example.com/callback",
+			},
+		}},
+		{"DELETE", "/inboundSamlConfigs/saml.old", nil},
+	}
+	if !reflect.DeepEqual(*writes, wantWrites) {
+		t.Errorf("ApplyTenantSnapshot() requests = %v; want = %v", *writes, wantWrites)
+	}
+}
+
+func TestApplyTenantSnapshotDryRun(t *testing.T) {
+	s, writes := snapshotServer(t)
+	defer s.Close()
+
+	diff, err := s.Client.TenantManager.ApplyTenantSnapshot(
+		context.Background(), "tenantID", desiredSnapshot(), &ApplySnapshotOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Deletions are not planned without Prune.
+	want := wantSnapshotChanges[:4]
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("ApplyTenantSnapshot(DryRun) = %v; want = %v", diff.Changes, want)
+	}
+	if len(*writes) != 0 {
+		t.Errorf("ApplyTenantSnapshot(DryRun) requests = %v; want = none", *writes)
+	}
+}
+
+func TestApplyTenantSnapshotIdempotent(t *testing.T) {
+	s, writes := snapshotServer(t)
+	defer s.Close()
+
+	snapshot, err := s.Client.TenantManager.TenantSnapshot(context.Background(), "tenantID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := s.Client.TenantManager.ApplyTenantSnapshot(
+		context.Background(), "tenantID", snapshot, &ApplySnapshotOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() || len(*writes) != 0 {
+		t.Errorf("ApplyTenantSnapshot() = %v, %d requests; want = no changes", diff.Changes, len(*writes))
+	}
+}
+
+func TestApplyProjectSnapshot(t *testing.T) {
+	s, writes := snapshotServer(t)
+	defer s.Close()
+
+	desired := desiredSnapshot()
+	desired.OIDCProviderConfigs = wantTenantSnapshot.OIDCProviderConfigs
+	desired.SAMLProviderConfigs = nil
+	diff, err := s.Client.ApplyProjectSnapshot(context.Background(), desired, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tenant-only settings are ignored, and saml.old is not deleted without Prune.
+	want := []*ConfigChange{
+		{Kind: ConfigUpdate, Resource: SettingsResource, Fields: []string{"RecaptchaConfig"}},
+	}
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("ApplyProjectSnapshot() = %v; want = %v", diff.Changes, want)
+	}
+	wantWrites := []snapshotRequest{
+		{"PATCH", "/config", map[string]interface{}{
+			"recaptchaConfig": map[string]interface{}{"emailPasswordEnforcementState": "ENFORCE"},
+		}},
+	}
+	if !reflect.DeepEqual(*writes, wantWrites) {
+		t.Errorf("ApplyProjectSnapshot() requests = %v; want = %v", *writes, wantWrites)
+	}
+	if desired.AllowPasswordSignUp == nil {
+		t.Errorf("ApplyProjectSnapshot() modified the snapshot")
+	}
+}
+
+func TestApplySnapshotError(t *testing.T) {
+	s, _ := snapshotServer(t)
+	defer s.Close()
+
+	if _, err := s.Client.ApplyProjectSnapshot(context.Background(), nil, nil); err == nil {
+		t.Errorf("ApplyProjectSnapshot(nil) = nil; want = error")
+	}
+	if _, err := s.Client.TenantManager.ApplyTenantSnapshot(context.Background(), "tenantID", nil, nil); err == nil {
+		t.Errorf("ApplyTenantSnapshot(nil) = nil; want = error")
+	}
+	if _, err := s.Client.TenantManager.ApplyTenantSnapshot(context.Background(), "", &ConfigSnapshot{}, nil); err == nil {
+		t.Errorf("ApplyTenantSnapshot(\"\") = nil; want = error")
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/api/iterator"
+)
+
+// AllTenantUsersOptions specifies how AllTenantUsers lists users.
+type AllTenantUsersOptions struct {
+	// Concurrency is the maximum number of tenants whose users are listed at the same time.
+	// Defaults to 1.
+	Concurrency int
+
+	// PageSize is the maximum number of users fetched in a single request. Defaults to, and is
+	// capped at, 1000.
+	PageSize int
+}
+
+// AllTenantUsers returns an iterator over the users of all the tenants in the project.
+//
+// The tenants are listed with Tenants, and the users of up to AllTenantUsersOptions.Concurrency
+// tenants are listed at the same time. Users of the same tenant are returned in order, but users
+// of different tenants may be interleaved. Options may be nil.
+//
+// The users are fetched in the background as soon as the iterator is created. Callers that stop
+// before the iterator returns [iterator.Done] or an error must call Close, or cancel the context,
+// to release the resources held by the iterator.
+func (tm *TenantManager) AllTenantUsers(ctx context.Context, opts *AllTenantUsersOptions) *TenantUserIterator {
+	concurrency, pageSize := 1, maxReturnedResults
+	if opts != nil {
+		if opts.Concurrency > 0 {
+			concurrency = opts.Concurrency
+		}
+		if opts.PageSize > 0 && opts.PageSize < maxReturnedResults {
+			pageSize = opts.PageSize
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	pages := make(chan *tenantUserPage, concurrency)
+	it := &TenantUserIterator{
+		ctx:    ctx,
+		cancel: cancel,
+		pages:  pages,
+	}
+
+	tenantIDs := make(chan string)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(tenantIDs)
+		tenants := tm.Tenants(ctx, "")
+		for {
+			tenant, err := tenants.Next()
+			if err == iterator.Done {
+				return
+			}
+			if err != nil {
+				sendTenantUserPage(ctx, pages, &tenantUserPage{err: err})
+				return
+			}
+			select {
+			case tenantIDs <- tenant.ID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tenantID := range tenantIDs {
+				if !tm.listTenantUsers(ctx, tenantID, pageSize, pages) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(pages)
+	}()
+	return it
+}
+
+// listTenantUsers sends the users of the given tenant to pages, one page at a time. It returns
+// false if the listing must stop.
+func (tm *TenantManager) listTenantUsers(
+	ctx context.Context, tenantID string, pageSize int, pages chan<- *tenantUserPage) bool {
+	users := tm.base.withTenantID(tenantID).Users(ctx, "")
+	users.pageInfo.MaxSize = pageSize
+	for {
+		err := users.nextFunc()
+		if err == iterator.Done {
+			return true
+		}
+		page := &tenantUserPage{tenantID: tenantID, err: err}
+		if err == nil {
+			page.users, users.users = users.users, nil
+		}
+		if !sendTenantUserPage(ctx, pages, page) || err != nil {
+			return false
+		}
+	}
+}
+
+func sendTenantUserPage(ctx context.Context, pages chan<- *tenantUserPage, page *tenantUserPage) bool {
+	select {
+	case pages <- page:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+type tenantUserPage struct {
+	tenantID string
+	users    []*ExportedUserRecord
+	err      error
+}
+
+// TenantUserIterator is an iterator over the users of all the tenants in a project. It is not
+// safe for concurrent use.
+type TenantUserIterator struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	pages    <-chan *tenantUserPage
+	tenantID string
+	users    []*ExportedUserRecord
+	err      error
+}
+
+// Next returns the ID of the tenant of the next user, and the user. Its third return value is
+// [iterator.Done] if there are no more results. Once Next returns [iterator.Done] or an error,
+// all subsequent calls will return the same error.
+func (it *TenantUserIterator) Next() (string, *ExportedUserRecord, error) {
+	for len(it.users) == 0 {
+		if it.err != nil {
+			return "", nil, it.err
+		}
+
+		page, ok := <-it.pages
+		switch {
+		case !ok && it.ctx.Err() != nil:
+			it.stop(it.ctx.Err())
+		case !ok:
+			it.stop(iterator.Done)
+		case page.err != nil:
+			it.stop(page.err)
+		default:
+			it.tenantID, it.users = page.tenantID, page.users
+		}
+	}
+
+	user := it.users[0]
+	it.users = it.users[1:]
+	return it.tenantID, user, nil
+}
+
+// Close stops fetching users. Subsequent calls to Next return [iterator.Done], unless the
+// iterator has already returned an error.
+func (it *TenantUserIterator) Close() {
+	it.users = nil
+	if it.err == nil {
+		it.stop(iterator.Done)
+	}
+}
+
+func (it *TenantUserIterator) stop(err error) {
+	it.err = err
+	it.cancel()
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/iterator"
+)
+
+// tenantUsersServer lists the given tenants one per page, and serves the users of each tenant in
+// pages of the requested size. Tenants that are not in users are not found.
+func tenantUsersServer(
+	t *testing.T, tenantIDs []string, users map[string][]string) (*mockAuthServer, *[]string) {
+	var mutex sync.Mutex
+	var requests []string
+
+	s := echoServer(nil, t)
+	s.Srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, r.URL.RequestURI())
+		mutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/projects/mock-project-id")
+		query := r.URL.Query()
+		if path == "/tenants" {
+			i, _ := strconv.Atoi(query.Get("pageToken"))
+			next := ""
+			if i+1 < len(tenantIDs) {
+				next = strconv.Itoa(i + 1)
+			}
+			fmt.Fprintf(w, `{"tenants": [{"name": "projects/mock-project-id/tenants/%s"}], "nextPageToken": %q}`,
+				tenantIDs[i], next)
+			return
+		}
+
+		tenantID := strings.TrimSuffix(strings.TrimPrefix(path, "/tenants/"), "/accounts:batchGet")
+		uids, ok := users[tenantID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"message": "TENANT_NOT_FOUND"}}`))
+			return
+		}
+		start, _ := strconv.Atoi(query.Get("nextPageToken"))
+		size, _ := strconv.Atoi(query.Get("maxResults"))
+		end, next := start+size, ""
+		if end < len(uids) {
+			next = strconv.Itoa(end)
+		} else {
+			end = len(uids)
+		}
+		var accounts []string
+		for _, uid := range uids[start:end] {
+			accounts = append(accounts, fmt.Sprintf(`{"localId": %q}`, uid))
+		}
+		fmt.Fprintf(w, `{"users": [%s], "nextPageToken": %q}`, strings.Join(accounts, ","), next)
+	})
+	return s, &requests
+}
+
+func TestAllTenantUsers(t *testing.T) {
+	users := map[string][]string{
+		"tenant1": {"user1", "user2", "user3"},
+		"tenant2": {},
+		"tenant3": {"user4", "user5"},
+		"tenant4": {"user6"},
+	}
+	s, requests := tenantUsersServer(t, []string{"tenant1", "tenant2", "tenant3", "tenant4"}, users)
+	defer s.Close()
+
+	for _, concurrency := range []int{0, 1, 3, 10} {
+		*requests = nil
+		opts := &AllTenantUsersOptions{Concurrency: concurrency, PageSize: 2}
+		it := s.Client.TenantManager.AllTenantUsers(context.Background(), opts)
+		got := make(map[string][]string)
+		for {
+			tenantID, user, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[tenantID] = append(got[tenantID], user.UID)
+		}
+		if _, _, err := it.Next(); err != iterator.Done {
+			t.Errorf("AllTenantUsers(%d) = %v; want = %v", concurrency, err, iterator.Done)
+		}
+
+		want := map[string][]string{
+			"tenant1": {"user1", "user2", "user3"},
+			"tenant3": {"user4", "user5"},
+			"tenant4": {"user6"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("AllTenantUsers(%d) = %v; want = %v", concurrency, got, want)
+		}
+
+		// 4 tenant pages, and 2 + 1 + 1 + 1 user pages.
+		if len(*requests) != 9 {
+			t.Errorf("AllTenantUsers(%d) requests = %v; want = 9 requests", concurrency, *requests)
+		}
+		for _, req := range *requests {
+			if strings.Contains(req, "batchGet") && !strings.Contains(req, "maxResults=2") {
+				t.Errorf("AllTenantUsers(%d) request = %q; want = maxResults=2", concurrency, req)
+			}
+		}
+	}
+}
+
+func TestAllTenantUsersDefaultPageSize(t *testing.T) {
+	s, requests := tenantUsersServer(t, []string{"tenant1"}, map[string][]string{"tenant1": {"user1"}})
+	defer s.Close()
+
+	for _, opts := range []*AllTenantUsersOptions{nil, {PageSize: 5000}} {
+		*requests = nil
+		it := s.Client.TenantManager.AllTenantUsers(context.Background(), opts)
+		tenantID, user, err := it.Next()
+		if err != nil || tenantID != "tenant1" || user.UID != "user1" {
+			t.Errorf("AllTenantUsers(%v) = (%q, %v, %v); want = (tenant1, user1, nil)", opts, tenantID, user, err)
+		}
+		if _, _, err := it.Next(); err != iterator.Done {
+			t.Errorf("AllTenantUsers(%v) = %v; want = %v", opts, err, iterator.Done)
+		}
+
+		want := "/projects/mock-project-id/tenants/tenant1/accounts:batchGet?maxResults=1000"
+		if len(*requests) != 2 || (*requests)[1] != want {
+			t.Errorf("AllTenantUsers(%v) requests = %v; want = [..., %q]", opts, *requests, want)
+		}
+	}
+}
+
+func TestAllTenantUsersError(t *testing.T) {
+	s, _ := tenantUsersServer(t, []string{"unknown"}, nil)
+	defer s.Close()
+	s.Client.TenantManager.base.httpClient.RetryConfig = nil
+
+	it := s.Client.TenantManager.AllTenantUsers(context.Background(), nil)
+	tenantID, user, err := it.Next()
+	if tenantID != "" || user != nil || !IsTenantNotFound(err) {
+		t.Errorf("AllTenantUsers() = (%q, %v, %v); want = TenantNotFound", tenantID, user, err)
+	}
+	if _, _, err2 := it.Next(); err2 != err {
+		t.Errorf("AllTenantUsers() = %v; want = %v", err2, err)
+	}
+}
+
+func TestAllTenantUsersClose(t *testing.T) {
+	users := map[string][]string{
+		"tenant1": {"user1", "user2", "user3"},
+		"tenant2": {"user4", "user5"},
+	}
+	s, _ := tenantUsersServer(t, []string{"tenant1", "tenant2"}, users)
+	defer s.Close()
+
+	it := s.Client.TenantManager.AllTenantUsers(context.Background(), &AllTenantUsersOptions{PageSize: 1})
+	if _, _, err := it.Next(); err != nil {
+		t.Fatal(err)
+	}
+	it.Close()
+	if _, _, err := it.Next(); err != iterator.Done {
+		t.Errorf("Next() after Close() = %v; want = %v", err, iterator.Done)
+	}
+}
+
+func TestAllTenantUsersCanceled(t *testing.T) {
+	s, _ := tenantUsersServer(t, []string{"tenant1"}, map[string][]string{"tenant1": {"user1"}})
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it := s.Client.TenantManager.AllTenantUsers(ctx, nil)
+	if _, _, err := it.Next(); err == nil || err == iterator.Done {
+		t.Errorf("AllTenantUsers(canceled) = %v; want = error", err)
+	}
+}
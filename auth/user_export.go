@@ -0,0 +1,531 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/iterator"
+)
+
+// ExportFormat is the file format used by ExportUsers and UserImportReader.
+type ExportFormat int
+
+const (
+	// ExportFormatJSONL writes one JSON object per line, using the same field names as the user
+	// objects in the JSON files produced by the Firebase CLI auth:export command.
+	ExportFormatJSONL ExportFormat = iota
+
+	// ExportFormatCSV writes one CSV record per user, with the same columns as the CSV files
+	// produced by the Firebase CLI auth:export command. Only the google.com, facebook.com,
+	// twitter.com and github.com providers can be represented in this format.
+	ExportFormatCSV
+)
+
+// ExportField is the name of a user field that can be selected for export.
+type ExportField string
+
+// Fields that can be selected for export. The user ID is always exported.
+const (
+	ExportFieldEmail            ExportField = "email"
+	ExportFieldEmailVerified    ExportField = "emailVerified"
+	ExportFieldPasswordHash     ExportField = "passwordHash"
+	ExportFieldPasswordSalt     ExportField = "salt"
+	ExportFieldDisplayName      ExportField = "displayName"
+	ExportFieldPhotoURL         ExportField = "photoUrl"
+	ExportFieldLastSignedInAt   ExportField = "lastSignedInAt"
+	ExportFieldCreatedAt        ExportField = "createdAt"
+	ExportFieldPhoneNumber      ExportField = "phoneNumber"
+	ExportFieldDisabled         ExportField = "disabled"
+	ExportFieldCustomAttributes ExportField = "customAttributes"
+	ExportFieldProviderUserInfo ExportField = "providerUserInfo"
+)
+
+var allExportFields = []ExportField{
+	ExportFieldEmail,
+	ExportFieldEmailVerified,
+	ExportFieldPasswordHash,
+	ExportFieldPasswordSalt,
+	ExportFieldDisplayName,
+	ExportFieldPhotoURL,
+	ExportFieldLastSignedInAt,
+	ExportFieldCreatedAt,
+	ExportFieldPhoneNumber,
+	ExportFieldDisabled,
+	ExportFieldCustomAttributes,
+	ExportFieldProviderUserInfo,
+}
+
+// Providers that have dedicated columns in the CSV format, in column order.
+var csvProviders = []string{"google.com", "facebook.com", "twitter.com", "github.com"}
+
+const (
+	csvProviderStart = 7
+	csvColumns       = 28
+)
+
+// ExportUsersOptions specifies how users are exported by ExportUsers.
+type ExportUsersOptions struct {
+	// Format of the output. Defaults to ExportFormatJSONL.
+	Format ExportFormat
+
+	// Fields to export. Defaults to all fields. In the CSV format, the columns of the fields that
+	// are not selected are left empty.
+	Fields []ExportField
+
+	// Filter is an optional predicate that selects the users to export.
+	Filter func(*ExportedUserRecord) bool
+
+	// TenantID scopes the export to the specified tenant. When invoked on a TenantClient, this
+	// must either be empty or match the tenant ID of the client.
+	TenantID string
+}
+
+// ExportUsers writes all the users of the project (or tenant) to the given io.Writer.
+//
+// Password hashes and salts are written as standard base64 strings, and timestamps as
+// milliseconds since the epoch. The output can be read back with UserImportReader, and
+// imported with ImportUsers. Password hashes are only available when the credentials used to
+// initialize the SDK have permission to read them.
+func (c *baseClient) ExportUsers(ctx context.Context, w io.Writer, opts *ExportUsersOptions) error {
+	if opts == nil {
+		opts = &ExportUsersOptions{}
+	}
+
+	client := c
+	if opts.TenantID != "" && opts.TenantID != c.tenantID {
+		if c.tenantID != "" {
+			return fmt.Errorf("tenant id %q does not match the tenant id of the client: %q", opts.TenantID, c.tenantID)
+		}
+		client = c.withTenantID(opts.TenantID)
+	}
+
+	fields, err := exportFieldSet(opts.Fields)
+	if err != nil {
+		return err
+	}
+
+	var enc userEncoder
+	switch opts.Format {
+	case ExportFormatJSONL:
+		enc = &jsonlUserEncoder{enc: json.NewEncoder(w)}
+	case ExportFormatCSV:
+		enc = &csvUserEncoder{w: csv.NewWriter(w)}
+	default:
+		return fmt.Errorf("unsupported export format: %d", opts.Format)
+	}
+
+	it := client.Users(ctx, "")
+	for {
+		user, err := it.Next()
+		if err == iterator.Done {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if opts.Filter != nil && !opts.Filter(user) {
+			continue
+		}
+		eu, err := newExportedUser(user, fields)
+		if err != nil {
+			return err
+		}
+		if err := enc.encode(eu); err != nil {
+			return err
+		}
+	}
+	return enc.flush()
+}
+
+func exportFieldSet(fields []ExportField) (map[ExportField]bool, error) {
+	if len(fields) == 0 {
+		fields = allExportFields
+	}
+
+	set := make(map[ExportField]bool)
+	for _, f := range fields {
+		known := false
+		for _, k := range allExportFields {
+			if f == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported export field: %q", f)
+		}
+		set[f] = true
+	}
+	return set, nil
+}
+
+// exportedUser is the representation of a user in the Firebase CLI export formats.
+type exportedUser struct {
+	UID              string          `json:"localId"`
+	Email            string          `json:"email,omitempty"`
+	EmailVerified    bool            `json:"emailVerified,omitempty"`
+	PasswordHash     string          `json:"passwordHash,omitempty"`
+	PasswordSalt     string          `json:"salt,omitempty"`
+	DisplayName      string          `json:"displayName,omitempty"`
+	PhotoURL         string          `json:"photoUrl,omitempty"`
+	LastSignedInAt   int64           `json:"lastSignedInAt,string,omitempty"`
+	CreatedAt        int64           `json:"createdAt,string,omitempty"`
+	PhoneNumber      string          `json:"phoneNumber,omitempty"`
+	Disabled         bool            `json:"disabled,omitempty"`
+	CustomAttributes string          `json:"customAttributes,omitempty"`
+	ProviderUserInfo []*UserProvider `json:"providerUserInfo,omitempty"`
+}
+
+func newExportedUser(user *ExportedUserRecord, fields map[ExportField]bool) (*exportedUser, error) {
+	eu := &exportedUser{UID: user.UID}
+	if fields[ExportFieldEmail] {
+		eu.Email = user.Email
+	}
+	if fields[ExportFieldEmailVerified] {
+		eu.EmailVerified = user.EmailVerified
+	}
+	if fields[ExportFieldPasswordHash] {
+		hash, err := toStdBase64(user.PasswordHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid password hash for user %q: %v", user.UID, err)
+		}
+		eu.PasswordHash = hash
+	}
+	if fields[ExportFieldPasswordSalt] {
+		salt, err := toStdBase64(user.PasswordSalt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid password salt for user %q: %v", user.UID, err)
+		}
+		eu.PasswordSalt = salt
+	}
+	if fields[ExportFieldDisplayName] {
+		eu.DisplayName = user.DisplayName
+	}
+	if fields[ExportFieldPhotoURL] {
+		eu.PhotoURL = user.PhotoURL
+	}
+	if user.UserMetadata != nil {
+		if fields[ExportFieldLastSignedInAt] {
+			eu.LastSignedInAt = user.UserMetadata.LastLogInTimestamp
+		}
+		if fields[ExportFieldCreatedAt] {
+			eu.CreatedAt = user.UserMetadata.CreationTimestamp
+		}
+	}
+	if fields[ExportFieldPhoneNumber] {
+		eu.PhoneNumber = user.PhoneNumber
+	}
+	if fields[ExportFieldDisabled] {
+		eu.Disabled = user.Disabled
+	}
+	if fields[ExportFieldCustomAttributes] && len(user.CustomClaims) > 0 {
+		b, err := json.Marshal(user.CustomClaims)
+		if err != nil {
+			return nil, err
+		}
+		eu.CustomAttributes = string(b)
+	}
+	if fields[ExportFieldProviderUserInfo] {
+		for _, p := range user.ProviderUserInfo {
+			eu.ProviderUserInfo = append(eu.ProviderUserInfo, &UserProvider{
+				UID:         p.UID,
+				ProviderID:  p.ProviderID,
+				Email:       p.Email,
+				DisplayName: p.DisplayName,
+				PhotoURL:    p.PhotoURL,
+			})
+		}
+	}
+	return eu, nil
+}
+
+// toStdBase64 converts a base64 string, as returned by the backend, to the standard base64
+// encoding used in Firebase CLI exports. The backend normally returns web-safe base64, but values
+// that are already in the standard encoding, padded or not, are also accepted.
+func toStdBase64(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	var err error
+	for _, enc := range []*base64.Encoding{
+		base64.URLEncoding, base64.RawURLEncoding, base64.StdEncoding, base64.RawStdEncoding,
+	} {
+		var b []byte
+		if b, err = enc.DecodeString(s); err == nil {
+			return base64.StdEncoding.EncodeToString(b), nil
+		}
+	}
+	return "", err
+}
+
+type userEncoder interface {
+	encode(u *exportedUser) error
+	flush() error
+}
+
+type jsonlUserEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlUserEncoder) encode(u *exportedUser) error {
+	return e.enc.Encode(u)
+}
+
+func (e *jsonlUserEncoder) flush() error {
+	return nil
+}
+
+type csvUserEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvUserEncoder) encode(u *exportedUser) error {
+	record := make([]string, csvColumns)
+	record[0] = u.UID
+	record[1] = u.Email
+	record[2] = strconv.FormatBool(u.EmailVerified)
+	record[3] = u.PasswordHash
+	record[4] = u.PasswordSalt
+	record[5] = u.DisplayName
+	record[6] = u.PhotoURL
+	for _, p := range u.ProviderUserInfo {
+		for i, id := range csvProviders {
+			if p.ProviderID == id {
+				col := csvProviderStart + 4*i
+				record[col] = p.UID
+				record[col+1] = p.Email
+				record[col+2] = p.DisplayName
+				record[col+3] = p.PhotoURL
+			}
+		}
+	}
+	record[23] = formatTimestamp(u.CreatedAt)
+	record[24] = formatTimestamp(u.LastSignedInAt)
+	record[25] = u.PhoneNumber
+	record[26] = strconv.FormatBool(u.Disabled)
+	record[27] = u.CustomAttributes
+	return e.w.Write(record)
+}
+
+func (e *csvUserEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func formatTimestamp(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return strconv.FormatInt(ts, 10)
+}
+
+// UserImportReader reads users from the output of ExportUsers, or from a file in the same format
+// produced by the Firebase CLI auth:export command, and returns them as UserToImport values
+// that can be passed to ImportUsers.
+type UserImportReader struct {
+	format ExportFormat
+	jsonl  *bufio.Scanner
+	csv    *csv.Reader
+	line   int
+}
+
+// NewUserImportReader creates a new UserImportReader that reads users in the given format.
+func NewUserImportReader(r io.Reader, format ExportFormat) *UserImportReader {
+	ur := &UserImportReader{format: format}
+	switch format {
+	case ExportFormatJSONL:
+		ur.jsonl = bufio.NewScanner(r)
+		ur.jsonl.Buffer(nil, 1024*1024)
+	case ExportFormatCSV:
+		ur.csv = csv.NewReader(r)
+		ur.csv.FieldsPerRecord = -1
+	}
+	return ur
+}
+
+// Next returns the next user. The error value of [iterator.Done] is returned when there are no
+// more users.
+func (r *UserImportReader) Next() (*UserToImport, error) {
+	var eu *exportedUser
+	var err error
+	switch r.format {
+	case ExportFormatJSONL:
+		eu, err = r.nextJSONL()
+	case ExportFormatCSV:
+		eu, err = r.nextCSV()
+	default:
+		return nil, fmt.Errorf("unsupported export format: %d", r.format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return eu.userToImport()
+}
+
+func (r *UserImportReader) nextJSONL() (*exportedUser, error) {
+	for r.jsonl.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.jsonl.Text())
+		if line == "" {
+			continue
+		}
+
+		var eu exportedUser
+		if err := json.Unmarshal([]byte(line), &eu); err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		return &eu, nil
+	}
+	if err := r.jsonl.Err(); err != nil {
+		return nil, err
+	}
+	return nil, iterator.Done
+}
+
+func (r *UserImportReader) nextCSV() (*exportedUser, error) {
+	record, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, iterator.Done
+	} else if err != nil {
+		return nil, err
+	}
+	r.line++
+	if len(record) < csvProviderStart+4*len(csvProviders) {
+		return nil, fmt.Errorf("record %d: too few columns: %d", r.line, len(record))
+	}
+	for len(record) < csvColumns {
+		record = append(record, "")
+	}
+
+	eu := &exportedUser{
+		UID:              record[0],
+		Email:            record[1],
+		PasswordHash:     record[3],
+		PasswordSalt:     record[4],
+		DisplayName:      record[5],
+		PhotoURL:         record[6],
+		PhoneNumber:      record[25],
+		CustomAttributes: record[27],
+	}
+	if eu.EmailVerified, err = parseCSVBool(record[2]); err != nil {
+		return nil, fmt.Errorf("record %d: %v", r.line, err)
+	}
+	if eu.Disabled, err = parseCSVBool(record[26]); err != nil {
+		return nil, fmt.Errorf("record %d: %v", r.line, err)
+	}
+	if eu.CreatedAt, err = parseCSVInt(record[23]); err != nil {
+		return nil, fmt.Errorf("record %d: %v", r.line, err)
+	}
+	if eu.LastSignedInAt, err = parseCSVInt(record[24]); err != nil {
+		return nil, fmt.Errorf("record %d: %v", r.line, err)
+	}
+	for i, id := range csvProviders {
+		col := csvProviderStart + 4*i
+		if record[col] == "" {
+			continue
+		}
+		eu.ProviderUserInfo = append(eu.ProviderUserInfo, &UserProvider{
+			ProviderID:  id,
+			UID:         record[col],
+			Email:       record[col+1],
+			DisplayName: record[col+2],
+			PhotoURL:    record[col+3],
+		})
+	}
+	return eu, nil
+}
+
+func parseCSVBool(s string) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+func parseCSVInt(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func (eu *exportedUser) userToImport() (*UserToImport, error) {
+	if eu.UID == "" {
+		return nil, errors.New("user id must not be empty")
+	}
+
+	u := (&UserToImport{}).UID(eu.UID)
+	if eu.Email != "" {
+		u.Email(eu.Email)
+	}
+	if eu.EmailVerified {
+		u.EmailVerified(true)
+	}
+	if eu.PasswordHash != "" {
+		hash, err := base64.StdEncoding.DecodeString(eu.PasswordHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid password hash for user %q: %v", eu.UID, err)
+		}
+		u.PasswordHash(hash)
+	}
+	if eu.PasswordSalt != "" {
+		salt, err := base64.StdEncoding.DecodeString(eu.PasswordSalt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid password salt for user %q: %v", eu.UID, err)
+		}
+		u.PasswordSalt(salt)
+	}
+	if eu.DisplayName != "" {
+		u.DisplayName(eu.DisplayName)
+	}
+	if eu.PhotoURL != "" {
+		u.PhotoURL(eu.PhotoURL)
+	}
+	if eu.CreatedAt != 0 || eu.LastSignedInAt != 0 {
+		u.Metadata(&UserMetadata{
+			CreationTimestamp:  eu.CreatedAt,
+			LastLogInTimestamp: eu.LastSignedInAt,
+		})
+	}
+	if eu.PhoneNumber != "" {
+		u.PhoneNumber(eu.PhoneNumber)
+	}
+	if eu.Disabled {
+		u.Disabled(true)
+	}
+	if eu.CustomAttributes != "" {
+		var claims map[string]interface{}
+		if err := json.Unmarshal([]byte(eu.CustomAttributes), &claims); err != nil {
+			return nil, fmt.Errorf("invalid custom attributes for user %q: %v", eu.UID, err)
+		}
+		if len(claims) > 0 {
+			u.CustomClaims(claims)
+		}
+	}
+	if len(eu.ProviderUserInfo) > 0 {
+		u.ProviderData(eu.ProviderUserInfo)
+	}
+	return u, nil
+}
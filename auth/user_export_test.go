@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/iterator"
+)
+
+// Password hashes and salts are returned as web-safe base64 strings by the backend.
+const testExportUsersResponse = `{
+	"users": [
+		{
+			"localId": "user1",
+			"email": "user1@example.com",
+			"emailVerified": true,
+			"displayName": "User, One",
+			"photoUrl": "http://www.example.com/user1/photo.png",
+			"phoneNumber": "+11234567890",
+			"passwordHash": "_-8=",
+			"salt": "c2FsdA==",
+			"createdAt": "1234567890000",
+			"lastLoginAt": "1233211232000",
+			"customAttributes": "{\"admin\": true}",
+			"providerUserInfo": [
+				{
+					"providerId": "google.com",
+					"rawId": "google-uid",
+					"email": "user1@gmail.com",
+					"displayName": "User One"
+				},
+				{
+					"providerId": "password",
+					"rawId": "user1@example.com",
+					"email": "user1@example.com"
+				}
+			]
+		},
+		{
+			"localId": "user2",
+			"disabled": true
+		}
+	]
+}`
+
+func TestExportUsersJSONL(t *testing.T) {
+	s := echoServer([]byte(testExportUsersResponse), t)
+	defer s.Close()
+
+	var buf bytes.Buffer
+	if err := s.Client.ExportUsers(context.Background(), &buf, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ExportUsers() = %d lines; want = 2", len(lines))
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"localId":          "user1",
+		"email":            "user1@example.com",
+		"emailVerified":    true,
+		"passwordHash":     "/+8=",
+		"salt":             "c2FsdA==",
+		"displayName":      "User, One",
+		"photoUrl":         "http://www.example.com/user1/photo.png",
+		"lastSignedInAt":   "1233211232000",
+		"createdAt":        "1234567890000",
+		"phoneNumber":      "+11234567890",
+		"customAttributes": `{"admin":true}`,
+		"providerUserInfo": []interface{}{
+			map[string]interface{}{
+				"providerId":  "google.com",
+				"rawId":       "google-uid",
+				"email":       "user1@gmail.com",
+				"displayName": "User One",
+			},
+			map[string]interface{}{
+				"providerId": "password",
+				"rawId":      "user1@example.com",
+				"email":      "user1@example.com",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExportUsers() = %v; want = %v", got, want)
+	}
+	if lines[1] != `{"localId":"user2","disabled":true}` {
+		t.Errorf("ExportUsers() = %s; want = %s", lines[1], `{"localId":"user2","disabled":true}`)
+	}
+}
+
+func TestExportUsersCSV(t *testing.T) {
+	s := echoServer([]byte(testExportUsersResponse), t)
+	defer s.Close()
+
+	var buf bytes.Buffer
+	opts := &ExportUsersOptions{Format: ExportFormatCSV}
+	if err := s.Client.ExportUsers(context.Background(), &buf, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `user1,user1@example.com,true,/+8=,c2FsdA==,"User, One",http://www.example.com/user1/photo.png,` +
+		`google-uid,user1@gmail.com,User One,,,,,,,,,,,,,,` +
+		`1234567890000,1233211232000,+11234567890,false,"{""admin"":true}"` + "\n" +
+		`user2,,false,,,,,,,,,,,,,,,,,,,,,,,,true,` + "\n"
+	if buf.String() != want {
+		t.Errorf("ExportUsers() = %q; want = %q", buf.String(), want)
+	}
+}
+
+func TestExportUsersFieldsAndFilter(t *testing.T) {
+	s := echoServer([]byte(testExportUsersResponse), t)
+	defer s.Close()
+
+	var buf bytes.Buffer
+	opts := &ExportUsersOptions{
+		Fields: []ExportField{ExportFieldEmail, ExportFieldCustomAttributes},
+		Filter: func(u *ExportedUserRecord) bool {
+			return !u.Disabled
+		},
+		TenantID: "tenant1",
+	}
+	if err := s.Client.ExportUsers(context.Background(), &buf, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"localId":"user1","email":"user1@example.com","customAttributes":"{\"admin\":true}"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("ExportUsers() = %q; want = %q", buf.String(), want)
+	}
+	if got := s.Req[0].URL.Path; got != "/projects/mock-project-id/tenants/tenant1/accounts:batchGet" {
+		t.Errorf("Path = %q; want = tenant-scoped batchGet", got)
+	}
+}
+
+func TestExportUsersError(t *testing.T) {
+	s := echoServer([]byte(testExportUsersResponse), t)
+	defer s.Close()
+
+	tenantClient, err := s.Client.TenantManager.AuthForTenant("tenant1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		client *baseClient
+		opts   *ExportUsersOptions
+	}{
+		{"UnknownField", s.Client.baseClient, &ExportUsersOptions{Fields: []ExportField{"password"}}},
+		{"UnknownFormat", s.Client.baseClient, &ExportUsersOptions{Format: ExportFormat(99)}},
+		{"TenantMismatch", tenantClient.baseClient, &ExportUsersOptions{TenantID: "tenant2"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.client.ExportUsers(context.Background(), &buf, tc.opts); err == nil {
+				t.Errorf("ExportUsers() = nil; want = error")
+			}
+		})
+	}
+	if len(s.Req) != 0 {
+		t.Errorf("Requests = %d; want = 0", len(s.Req))
+	}
+}
+
+func TestUserImportReaderRoundTrip(t *testing.T) {
+	for _, format := range []ExportFormat{ExportFormatJSONL, ExportFormatCSV} {
+		s := echoServer([]byte(testExportUsersResponse), t)
+		defer s.Close()
+
+		var buf bytes.Buffer
+		if err := s.Client.ExportUsers(context.Background(), &buf, &ExportUsersOptions{Format: format}); err != nil {
+			t.Fatal(err)
+		}
+
+		r := NewUserImportReader(&buf, format)
+		var users []map[string]interface{}
+		for {
+			u, err := r.Next()
+			if err == iterator.Done {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			info, err := u.validatedUserInfo()
+			if err != nil {
+				t.Fatal(err)
+			}
+			users = append(users, info)
+		}
+		if len(users) != 2 {
+			t.Fatalf("UserImportReader(%d) = %d users; want = 2", format, len(users))
+		}
+
+		want := map[string]interface{}{
+			"localId":          "user1",
+			"email":            "user1@example.com",
+			"emailVerified":    true,
+			"passwordHash":     "_-8",
+			"salt":             "c2FsdA",
+			"displayName":      "User, One",
+			"photoUrl":         "http://www.example.com/user1/photo.png",
+			"createdAt":        int64(1234567890000),
+			"lastLoginAt":      int64(1233211232000),
+			"phoneNumber":      "+11234567890",
+			"customAttributes": `{"admin":true}`,
+			"providerUserInfo": []*UserProvider{
+				{ProviderID: "google.com", UID: "google-uid", Email: "user1@gmail.com", DisplayName: "User One"},
+			},
+		}
+		if format == ExportFormatJSONL {
+			want["providerUserInfo"] = append(want["providerUserInfo"].([]*UserProvider), &UserProvider{
+				ProviderID: "password", UID: "user1@example.com", Email: "user1@example.com",
+			})
+		}
+		if !reflect.DeepEqual(users[0], want) {
+			t.Errorf("UserImportReader(%d) = %#v; want = %#v", format, users[0], want)
+		}
+
+		want2 := map[string]interface{}{"localId": "user2", "disabled": true}
+		if !reflect.DeepEqual(users[1], want2) {
+			t.Errorf("UserImportReader(%d) = %#v; want = %#v", format, users[1], want2)
+		}
+	}
+}
+
+func TestUserImportReaderError(t *testing.T) {
+	cases := []struct {
+		name   string
+		format ExportFormat
+		input  string
+	}{
+		{"MalformedJSON", ExportFormatJSONL, `{"localId":`},
+		{"MissingUID", ExportFormatJSONL, `{"email":"user@example.com"}`},
+		{"InvalidHash", ExportFormatJSONL, `{"localId":"user1","passwordHash":"not base64!"}`},
+		{"InvalidClaims", ExportFormatJSONL, `{"localId":"user1","customAttributes":"{"}`},
+		{"ShortRecord", ExportFormatCSV, `user1,user1@example.com`},
+		{"InvalidBool", ExportFormatCSV, `user1,,yes` + strings.Repeat(",", 25)},
+		{"InvalidTimestamp", ExportFormatCSV, `user1` + strings.Repeat(",", 23) + `yesterday,,,,`},
+		{"UnknownFormat", ExportFormat(99), `{}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewUserImportReader(strings.NewReader(tc.input), tc.format)
+			if u, err := r.Next(); u != nil || err == nil || err == iterator.Done {
+				t.Errorf("Next() = (%v, %v); want = (nil, error)", u, err)
+			}
+		})
+	}
+}
+
+func TestToStdBase64(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"_-8=", "/+8="},
+		{"_-8", "/+8="},
+		{"/+8=", "/+8="},
+		{"/+8", "/+8="},
+		{"c2FsdA==", "c2FsdA=="},
+	}
+	for _, tc := range cases {
+		if got, err := toStdBase64(tc.in); err != nil || got != tc.want {
+			t.Errorf("toStdBase64(%q) = (%q, %v); want = (%q, nil)", tc.in, got, err, tc.want)
+		}
+	}
+
+	if got, err := toStdBase64("not base64!"); got != "" || err == nil {
+		t.Errorf("toStdBase64(invalid) = (%q, %v); want = (\"\", error)", got, err)
+	}
+}
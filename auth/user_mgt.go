@@ -20,7 +20,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -79,13 +81,18 @@ type PhoneMultiFactorInfo struct {
 }
 
 // TOTPMultiFactorInfo describes a user enrolled in TOTP second factor.
+//
+// The TOTP secret is never returned by the backend. The display name, enrollment time and
+// enrollment ID of a TOTP factor are available on the enclosing MultiFactorInfo.
 type TOTPMultiFactorInfo struct{}
 
 type multiFactorEnrollments struct {
 	Enrollments []*multiFactorInfoResponse `json:"enrollments"`
 }
 
-// MultiFactorInfo describes a user enrolled second phone factor.
+// MultiFactorInfo describes a user enrolled second factor.
+//
+// Either Phone or TOTP is set depending on the FactorID.
 type MultiFactorInfo struct {
 	UID                 string
 	DisplayName         string
@@ -101,6 +108,15 @@ type MultiFactorSettings struct {
 	EnrolledFactors []*MultiFactorInfo
 }
 
+// PasskeyInfo describes a passkey enrolled by a user.
+type PasskeyInfo struct {
+	// Name is the resource name of the passkey.
+	Name string `json:"name,omitempty"`
+	// CredentialID is the ID of the WebAuthn credential, used to delete the passkey.
+	CredentialID string `json:"credentialId,omitempty"`
+	DisplayName  string `json:"displayName,omitempty"`
+}
+
 // UserMetadata contains additional metadata associated with a user account.
 // Timestamps are in milliseconds since epoch.
 type UserMetadata struct {
@@ -111,6 +127,20 @@ type UserMetadata struct {
 	LastRefreshTimestamp int64
 }
 
+// LastRefreshTime returns the time at which the user was last active (ID token refreshed), or the
+// zero time if the user was never active.
+func (m *UserMetadata) LastRefreshTime() time.Time {
+	if m.LastRefreshTimestamp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, m.LastRefreshTimestamp*int64(time.Millisecond))
+}
+
+// ActiveSince checks if the user has been active (ID token refreshed) at or after the given time.
+func (m *UserMetadata) ActiveSince(t time.Time) bool {
+	return m.LastRefreshTimestamp != 0 && !m.LastRefreshTime().Before(t)
+}
+
 // UserRecord contains metadata associated with a Firebase user account.
 type UserRecord struct {
 	*UserInfo
@@ -122,6 +152,7 @@ type UserRecord struct {
 	UserMetadata           *UserMetadata
 	TenantID               string
 	MultiFactor            *MultiFactorSettings
+	Passkeys               []*PasskeyInfo
 }
 
 // UserToCreate is the parameter struct for the CreateUser function.
@@ -330,6 +361,11 @@ func (u *UserToUpdate) ProvidersToDelete(providerIds []string) *UserToUpdate {
 	return u.set("providersToDelete", providerIds)
 }
 
+// PasskeysToDelete deletes the passkeys with the specified credential IDs from this user.
+func (u *UserToUpdate) PasskeysToDelete(credentialIDs []string) *UserToUpdate {
+	return u.set("deletePasskey", credentialIDs)
+}
+
 // revokeRefreshTokens revokes all refresh tokens for a user by setting the validSince property
 // to the present in epoch seconds.
 func (u *UserToUpdate) revokeRefreshTokens() *UserToUpdate {
@@ -457,6 +493,17 @@ func (u *UserToUpdate) validatedRequest() (map[string]interface{}, error) {
 		}
 	}
 
+	if credentialIDs, ok := req["deletePasskey"]; ok {
+		if len(credentialIDs.([]string)) == 0 {
+			return nil, errors.New("passkeysToDelete must not be empty")
+		}
+		for _, id := range credentialIDs.([]string) {
+			if id == "" {
+				return nil, errors.New("passkeysToDelete must not include empty strings")
+			}
+		}
+	}
+
 	if providersToDelete, ok := req["providersToDelete"]; ok {
 		var deleteProvider []string
 		list, ok := req["deleteProvider"]
@@ -518,16 +565,20 @@ func marshalCustomClaims(claims map[string]interface{}) (string, error) {
 
 const (
 	// Backend-generated error codes
-	configurationNotFound    = "CONFIGURATION_NOT_FOUND"
-	emailAlreadyExists       = "EMAIL_ALREADY_EXISTS"
-	emailNotFound            = "EMAIL_NOT_FOUND"
-	invalidDynamicLinkDomain = "INVALID_DYNAMIC_LINK_DOMAIN"
-	invalidHostingLinkDomain = "INVALID_HOSTING_LINK_DOMAIN"
-	phoneNumberAlreadyExists = "PHONE_NUMBER_ALREADY_EXISTS"
-	tenantNotFound           = "TENANT_NOT_FOUND"
-	uidAlreadyExists         = "UID_ALREADY_EXISTS"
-	unauthorizedContinueURI  = "UNAUTHORIZED_CONTINUE_URI"
-	userNotFound             = "USER_NOT_FOUND"
+	configurationNotFound           = "CONFIGURATION_NOT_FOUND"
+	emailAlreadyExists              = "EMAIL_ALREADY_EXISTS"
+	emailNotFound                   = "EMAIL_NOT_FOUND"
+	expiredActionCode               = "EXPIRED_ACTION_CODE"
+	invalidActionCode               = "INVALID_ACTION_CODE"
+	invalidDynamicLinkDomain        = "INVALID_DYNAMIC_LINK_DOMAIN"
+	invalidHostingLinkDomain        = "INVALID_HOSTING_LINK_DOMAIN"
+	phoneNumberAlreadyExists        = "PHONE_NUMBER_ALREADY_EXISTS"
+	passwordDoesNotMeetRequirements = "PASSWORD_DOES_NOT_MEET_REQUIREMENTS"
+	quotaExceeded                   = "QUOTA_EXCEEDED"
+	tenantNotFound                  = "TENANT_NOT_FOUND"
+	uidAlreadyExists                = "UID_ALREADY_EXISTS"
+	unauthorizedContinueURI         = "UNAUTHORIZED_CONTINUE_URI"
+	userNotFound                    = "USER_NOT_FOUND"
 )
 
 // IsConfigurationNotFound checks if the given error was due to a non-existing IdP configuration.
@@ -581,6 +632,11 @@ func IsProjectNotFound(err error) bool {
 	return false
 }
 
+// IsQuotaExceeded checks if the given error was due to the client exceeding a quota.
+func IsQuotaExceeded(err error) bool {
+	return hasAuthErrorCode(err, quotaExceeded)
+}
+
 // IsTenantNotFound checks if the given error was due to a non-existing tenant ID.
 func IsTenantNotFound(err error) bool {
 	return hasAuthErrorCode(err, tenantNotFound)
@@ -723,6 +779,19 @@ func validateAndFormatMfaSettings(mfaSettings MultiFactorSettings, methodType st
 				return nil, fmt.Errorf("\"PhoneMultiFactorInfo\" must be defined")
 			}
 		}
+		if multiFactorInfo.FactorID == totpMultiFactorID {
+			// TOTP factors can only be enrolled by the user from a client app, since enrollment
+			// requires a shared secret. The admin APIs can only retain existing TOTP factors.
+			if methodType == createUserMethod {
+				return nil, fmt.Errorf("\"TOTP\" second factors are not supported when adding second factors via \"createUser()\"")
+			}
+			if multiFactorInfo.UID == "" {
+				return nil, fmt.Errorf("\"uid\" must be specified to retain an enrolled \"TOTP\" second factor")
+			}
+			if multiFactorInfo.TOTP == nil {
+				multiFactorInfo.TOTP = &TOTPMultiFactorInfo{}
+			}
+		}
 		obj, err := convertMultiFactorInfoToServerFormat(*multiFactorInfo)
 		if err != nil {
 			return nil, err
@@ -839,6 +908,15 @@ type getAccountInfoResponse struct {
 }
 
 func (c *baseClient) getUser(ctx context.Context, query *userQuery) (*UserRecord, error) {
+	user, err := c.getUserResponse(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.makeUserRecord()
+}
+
+func (c *baseClient) getUserResponse(ctx context.Context, query *userQuery) (*userQueryResponse, error) {
 	var parsed getAccountInfoResponse
 	resp, err := c.post(ctx, "/accounts:lookup", query.build(), &parsed)
 	if err != nil {
@@ -856,7 +934,7 @@ func (c *baseClient) getUser(ctx context.Context, query *userQuery) (*UserRecord
 		}
 	}
 
-	return parsed.Users[0].makeUserRecord()
+	return parsed.Users[0], nil
 }
 
 // A UserIdentifier identifies a user to be looked up.
@@ -1239,6 +1317,7 @@ type userQueryResponse struct {
 	TenantID           string                     `json:"tenantId,omitempty"`
 	ValidSinceSeconds  int64                      `json:"validSince,string,omitempty"`
 	MFAInfo            []*multiFactorInfoResponse `json:"mfaInfo,omitempty"`
+	PasskeyInfo        []*PasskeyInfo             `json:"passkeyInfo,omitempty"`
 }
 
 func (r *userQueryResponse) makeUserRecord() (*UserRecord, error) {
@@ -1278,8 +1357,7 @@ func (r *userQueryResponse) makeExportedUserRecord() (*ExportedUserRecord, error
 		lastRefreshTimestamp = t.Unix() * 1000
 	}
 
-	// Map the MFA info to a slice of enrolled factors. Currently there is only
-	// support for PhoneMultiFactorInfo.
+	// Map the MFA info to a slice of enrolled phone and TOTP factors.
 	var enrolledFactors []*MultiFactorInfo
 	for _, factor := range r.MFAInfo {
 		var enrollmentTimestamp int64
@@ -1339,6 +1417,7 @@ func (r *userQueryResponse) makeExportedUserRecord() (*ExportedUserRecord, error
 			MultiFactor: &MultiFactorSettings{
 				EnrolledFactors: enrolledFactors,
 			},
+			Passkeys: r.PasskeyInfo,
 		},
 		PasswordHash: hash,
 		PasswordSalt: r.PasswordSalt,
@@ -1392,6 +1471,43 @@ func (c *baseClient) RevokeRefreshTokens(ctx context.Context, uid string) error
 	return c.updateUser(ctx, uid, (&UserToUpdate{}).revokeRefreshTokens())
 }
 
+// RevokeRefreshTokensIssuedBefore revokes the refresh tokens of an existing user that were issued
+// before the given time. Refresh tokens issued at or after that time remain valid.
+//
+// The time must not be in the future, and is truncated to whole seconds. Revocations are recorded
+// as a single point in time, so the current revocation time of the user is read first, and left
+// unchanged if it is already later than t.
+//
+// The read and the update are separate requests, and the backend offers no way to make them
+// atomic. A revocation that lands between them, such as a concurrent call to
+// RevokeRefreshTokens for the same user, is overwritten with the earlier time t, which makes the
+// tokens it revoked valid again. Do not call this concurrently with other revocations of the same
+// user.
+func (c *baseClient) RevokeRefreshTokensIssuedBefore(ctx context.Context, uid string, t time.Time) error {
+	if err := validateUID(uid); err != nil {
+		return err
+	}
+	if t.IsZero() {
+		return errors.New("revocation time must not be zero")
+	}
+	if t.After(time.Now()) {
+		return errors.New("revocation time must not be in the future")
+	}
+
+	user, err := c.getUserResponse(ctx, &userQuery{
+		field: "localId",
+		value: uid,
+		label: "uid",
+	})
+	if err != nil {
+		return err
+	}
+	if user.ValidSinceSeconds >= t.Unix() {
+		return nil
+	}
+	return c.updateUser(ctx, uid, (&UserToUpdate{}).set("validSince", strconv.FormatInt(t.Unix(), 10)))
+}
+
 // SetCustomUserClaims sets additional claims on an existing user account.
 //
 // Custom claims set via this function can be used to define user roles and privilege levels.
@@ -1407,6 +1523,228 @@ func (c *baseClient) SetCustomUserClaims(ctx context.Context, uid string, custom
 	return c.updateUser(ctx, uid, (&UserToUpdate{}).CustomClaims(customClaims))
 }
 
+// maxClaimsUpdateAttempts is the number of times UpdateCustomClaims reads, mutates and writes the
+// custom claims of a user before giving up due to concurrent modifications.
+const maxClaimsUpdateAttempts = 5
+
+// claimsUpdateBackoff is the base delay between two attempts of UpdateCustomClaims. The delay is
+// doubled after each attempt, and a random jitter of up to the same amount is added to it.
+var claimsUpdateBackoff = 100 * time.Millisecond
+
+// UpdateCustomClaims updates the custom claims of an existing user account, with best-effort
+// detection of concurrent modifications.
+//
+// The current claims of the user are read and passed to the update function, which returns the new
+// claims. The update function may modify and return the map it is given. Returning a nil or empty
+// map removes all the custom claims of the user. If the update function returns an error, the
+// claims are left unchanged and the error is returned.
+//
+// The backend does not support conditional updates. Instead, the stored claims are compared with
+// the claims that were read right before they are written, and the whole read-modify-write cycle
+// is retried with a jittered exponential backoff when a concurrent modification is detected. This
+// narrows, but does not close, the window for lost updates: a concurrent write that lands between
+// the compare step and the write is overwritten. Once the claims have been written, the update is
+// never retried, so that it is not applied twice. The update function may be called more than once
+// before that, and should not have side effects. IsConcurrentModification reports errors due to
+// concurrent modifications that persist after all the retries.
+func (c *baseClient) UpdateCustomClaims(
+	ctx context.Context, uid string,
+	update func(claims map[string]interface{}) (map[string]interface{}, error)) error {
+	if err := validateUID(uid); err != nil {
+		return err
+	}
+	if update == nil {
+		return fmt.Errorf("update function must not be nil")
+	}
+
+	for i := 0; i < maxClaimsUpdateAttempts; i++ {
+		if i > 0 {
+			if err := waitClaimsUpdateBackoff(ctx, i); err != nil {
+				return err
+			}
+		}
+
+		current, err := c.getCustomAttributes(ctx, uid)
+		if err != nil {
+			return err
+		}
+		claims, err := parseCustomAttributes(current)
+		if err != nil {
+			return err
+		}
+
+		updated, err := update(claims)
+		if err != nil {
+			return err
+		}
+		serialized, err := marshalCustomClaims(updated)
+		if err != nil {
+			return err
+		}
+		if sameCustomAttributes(current, serialized) {
+			return nil
+		}
+
+		// Compare step: skip the write if the claims have changed since they were read.
+		latest, err := c.getCustomAttributes(ctx, uid)
+		if err != nil {
+			return err
+		}
+		if !sameCustomAttributes(current, latest) {
+			continue
+		}
+
+		return c.SetCustomUserClaims(ctx, uid, updated)
+	}
+
+	return &internal.FirebaseError{
+		ErrorCode: internal.Aborted,
+		String: fmt.Sprintf(
+			"custom claims of user %q were modified concurrently; giving up after %d attempts",
+			uid, maxClaimsUpdateAttempts),
+		Ext: map[string]interface{}{
+			authErrorCode: concurrentModification,
+		},
+	}
+}
+
+// waitClaimsUpdateBackoff waits before the given retry attempt of UpdateCustomClaims, or until the
+// context is done.
+func waitClaimsUpdateBackoff(ctx context.Context, attempt int) error {
+	delay := claimsUpdateBackoff << uint(attempt-1)
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)))
+	}
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// getCustomAttributes returns the serialized custom claims of a user, as stored by the backend.
+func (c *baseClient) getCustomAttributes(ctx context.Context, uid string) (string, error) {
+	user, err := c.getUserResponse(ctx, &userQuery{
+		field: "localId",
+		value: uid,
+		label: "uid",
+	})
+	if err != nil {
+		return "", err
+	}
+	return user.CustomAttributes, nil
+}
+
+func parseCustomAttributes(attrs string) (map[string]interface{}, error) {
+	claims := make(map[string]interface{})
+	if attrs != "" {
+		if err := json.Unmarshal([]byte(attrs), &claims); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal custom claims: %v", err)
+		}
+	}
+	if claims == nil {
+		claims = make(map[string]interface{})
+	}
+	return claims, nil
+}
+
+// sameCustomAttributes reports whether two serialized custom claims hold the same claims,
+// regardless of their formatting.
+func sameCustomAttributes(a, b string) bool {
+	ca, err := parseCustomAttributes(a)
+	if err != nil {
+		return a == b
+	}
+	cb, err := parseCustomAttributes(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(ca, cb)
+}
+
+// IsConcurrentModification checks if the given error was due to a resource being modified
+// concurrently by another client.
+func IsConcurrentModification(err error) bool {
+	return hasAuthErrorCode(err, concurrentModification)
+}
+
+// RemoveMultiFactorEnrollments unenrolls the second factors with the given enrollment IDs from an
+// existing user account.
+//
+// The enrollment IDs are available as the UID field of the MultiFactorInfo entries in the
+// UserRecord. All other enrolled factors, including TOTP factors, are retained as they are. An
+// error is returned if any of the given IDs is not enrolled by the user. The current enrollments
+// are read before they are updated, so concurrent changes to the second factors of the same user
+// may be overwritten.
+func (c *baseClient) RemoveMultiFactorEnrollments(ctx context.Context, uid string, factorUIDs ...string) error {
+	if err := validateUID(uid); err != nil {
+		return err
+	}
+	if len(factorUIDs) == 0 {
+		return fmt.Errorf("at least one second factor uid must be specified")
+	}
+
+	remove := make(map[string]bool, len(factorUIDs))
+	for _, id := range factorUIDs {
+		if id == "" {
+			return fmt.Errorf("second factor uid must be a non-empty string")
+		}
+		remove[id] = true
+	}
+
+	user, err := c.getUserResponse(ctx, &userQuery{
+		field: "localId",
+		value: uid,
+		label: "uid",
+	})
+	if err != nil {
+		return err
+	}
+
+	retained := make([]*multiFactorInfoResponse, 0, len(user.MFAInfo))
+	for _, factor := range user.MFAInfo {
+		if remove[factor.MFAEnrollmentID] {
+			delete(remove, factor.MFAEnrollmentID)
+			continue
+		}
+		retained = append(retained, factor)
+	}
+	for _, id := range factorUIDs {
+		if remove[id] {
+			return fmt.Errorf("no second factor with uid %q is enrolled for user %q", id, uid)
+		}
+	}
+
+	return c.updateMultiFactorEnrollments(ctx, uid, retained)
+}
+
+// ResetMultiFactorEnrollments unenrolls all the second factors of an existing user account.
+//
+// After the reset the user can sign in with their first factor only, and may enroll new second
+// factors from a client app.
+func (c *baseClient) ResetMultiFactorEnrollments(ctx context.Context, uid string) error {
+	if err := validateUID(uid); err != nil {
+		return err
+	}
+	return c.updateMultiFactorEnrollments(ctx, uid, nil)
+}
+
+func (c *baseClient) updateMultiFactorEnrollments(
+	ctx context.Context, uid string, enrollments []*multiFactorInfoResponse) error {
+	// An empty mfa object clears all the enrolled factors.
+	var mfa interface{} = map[string]interface{}{}
+	if len(enrollments) > 0 {
+		mfa = multiFactorEnrollments{enrollments}
+	}
+	request := map[string]interface{}{
+		"localId": uid,
+		"mfa":     mfa,
+	}
+	_, err := c.post(ctx, "/accounts:update", request, nil)
+	return err
+}
+
 func (c *baseClient) updateUser(ctx context.Context, uid string, user *UserToUpdate) error {
 	if err := validateUID(uid); err != nil {
 		return err
@@ -1620,6 +1958,11 @@ var serverError = map[string]*authError{
 		message:  "no user record found for the given email",
 		authCode: emailNotFound,
 	},
+	"EXPIRED_OOB_CODE": {
+		code:     internal.InvalidArgument,
+		message:  "the action code has expired",
+		authCode: expiredActionCode,
+	},
 	"INVALID_DYNAMIC_LINK_DOMAIN": {
 		code:     internal.InvalidArgument,
 		message:  "the provided dynamic link domain is not configured or authorized for the current project",
@@ -1630,11 +1973,26 @@ var serverError = map[string]*authError{
 		message:  "the provided hosting link domain is not configured in Firebase Hosting or is not owned by the current project",
 		authCode: invalidHostingLinkDomain,
 	},
+	"INVALID_OOB_CODE": {
+		code:     internal.InvalidArgument,
+		message:  "the action code is invalid; it may be malformed or already used",
+		authCode: invalidActionCode,
+	},
+	"PASSWORD_DOES_NOT_MEET_REQUIREMENTS": {
+		code:     internal.InvalidArgument,
+		message:  "the password does not meet the password policy requirements",
+		authCode: passwordDoesNotMeetRequirements,
+	},
 	"PHONE_NUMBER_EXISTS": {
 		code:     internal.AlreadyExists,
 		message:  "user with the provided phone number already exists",
 		authCode: phoneNumberAlreadyExists,
 	},
+	"QUOTA_EXCEEDED": {
+		code:     internal.ResourceExhausted,
+		message:  "the request exceeded a quota of the project",
+		authCode: quotaExceeded,
+	},
 	"TENANT_NOT_FOUND": {
 		code:     internal.NotFound,
 		message:  "tenant with the specified ID does not exist",
@@ -1663,6 +2021,9 @@ func handleHTTPError(resp *internal.Response) error {
 		} else {
 			err.String = authErr.message
 		}
+		if authErr.authCode == passwordDoesNotMeetRequirements {
+			err.Ext[passwordRequirementsKey] = parsePasswordRequirements(detail)
+		}
 	}
 
 	return err
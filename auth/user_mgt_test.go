@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -147,6 +148,36 @@ func TestGetUser(t *testing.T) {
 	}
 }
 
+func TestGetUserWithPasskeys(t *testing.T) {
+	resp := `{
+		"users": [{
+			"localId": "testuser",
+			"passkeyInfo": [{
+				"name": "projects/mock-project-id/accounts/testuser/passkeys/passkey1",
+				"credentialId": "credential1",
+				"displayName": "My Passkey"
+			}]
+		}]
+	}`
+	s := echoServer([]byte(resp), t)
+	defer s.Close()
+
+	user, err := s.Client.GetUser(context.Background(), "testuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*PasskeyInfo{
+		{
+			Name:         "projects/mock-project-id/accounts/testuser/passkeys/passkey1",
+			CredentialID: "credential1",
+			DisplayName:  "My Passkey",
+		},
+	}
+	if !reflect.DeepEqual(user.Passkeys, want) {
+		t.Errorf("GetUser().Passkeys = %#v; want = %#v", user.Passkeys, want)
+	}
+}
+
 func TestGetUserByEmail(t *testing.T) {
 	s := echoServer(testGetUserResponse, t)
 	defer s.Close()
@@ -718,6 +749,17 @@ func TestInvalidCreateUser(t *testing.T) {
 				},
 			}),
 			`the second factor "displayName" for "" must be a valid non-empty string`,
+		}, {
+			(&UserToCreate{}).MFASettings(MultiFactorSettings{
+				EnrolledFactors: []*MultiFactorInfo{
+					{
+						TOTP:        &TOTPMultiFactorInfo{},
+						DisplayName: "My TOTP app",
+						FactorID:    "totp",
+					},
+				},
+			}),
+			`"TOTP" second factors are not supported when adding second factors via "createUser()"`,
 		},
 	}
 	client := &Client{
@@ -933,6 +975,23 @@ func TestInvalidUpdateUser(t *testing.T) {
 				},
 			}),
 			`the second factor "phoneNumber" for "invalid" must be a non-empty E.164 standard compliant identifier string`,
+		}, {
+			(&UserToUpdate{}).MFASettings(MultiFactorSettings{
+				EnrolledFactors: []*MultiFactorInfo{
+					{
+						TOTP:        &TOTPMultiFactorInfo{},
+						DisplayName: "My TOTP app",
+						FactorID:    "totp",
+					},
+				},
+			}),
+			`"uid" must be specified to retain an enrolled "TOTP" second factor`,
+		}, {
+			(&UserToUpdate{}).PasskeysToDelete([]string{}),
+			"passkeysToDelete must not be empty",
+		}, {
+			(&UserToUpdate{}).PasskeysToDelete([]string{"credential1", ""}),
+			"passkeysToDelete must not include empty strings",
 		}, {
 			(&UserToUpdate{}).ProviderToLink(&UserProvider{UID: "google_uid"}),
 			"user provider must specify a provider ID",
@@ -1121,10 +1180,33 @@ var updateUserCases = []struct {
 		}},
 		},
 	},
+	{
+		(&UserToUpdate{}).MFASettings(MultiFactorSettings{
+			EnrolledFactors: []*MultiFactorInfo{
+				{
+					UID:         "enrolledTOTPFactor",
+					DisplayName: "My TOTP app",
+					FactorID:    "totp",
+				},
+			},
+		}),
+		map[string]interface{}{"mfa": multiFactorEnrollments{Enrollments: []*multiFactorInfoResponse{
+			{
+				MFAEnrollmentID: "enrolledTOTPFactor",
+				DisplayName:     "My TOTP app",
+				TOTPInfo:        &TOTPInfo{},
+			},
+		}},
+		},
+	},
 	{
 		(&UserToUpdate{}).MFASettings(MultiFactorSettings{}),
 		map[string]interface{}{"mfa": multiFactorEnrollments{Enrollments: nil}},
 	},
+	{
+		(&UserToUpdate{}).PasskeysToDelete([]string{"credential1", "credential2"}),
+		map[string]interface{}{"deletePasskey": []string{"credential1", "credential2"}},
+	},
 	{
 		(&UserToUpdate{}).ProviderToLink(&UserProvider{
 			ProviderID: "google.com",
@@ -1256,6 +1338,465 @@ func TestRevokeRefreshTokensInvalidUID(t *testing.T) {
 	}
 }
 
+func TestRevokeRefreshTokensIssuedBefore(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	if err := s.Client.RevokeRefreshTokensIssuedBefore(
+		context.Background(), "testuser", time.Unix(1600000000, 500)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Req) != 2 {
+		t.Fatalf("Requests = %d; want = 2", len(s.Req))
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(s.Rbody, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"localId":    "testuser",
+		"validSince": "1600000000",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RevokeRefreshTokensIssuedBefore() request = %v; want = %v", got, want)
+	}
+}
+
+func TestRevokeRefreshTokensIssuedBeforeAlreadyRevoked(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	// testGetUserResponse has tokens revoked at 1494364393.
+	if err := s.Client.RevokeRefreshTokensIssuedBefore(
+		context.Background(), "testuser", time.Unix(1494364393, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Req) != 1 {
+		t.Errorf("Requests = %d; want = 1", len(s.Req))
+	}
+}
+
+func TestRevokeRefreshTokensIssuedBeforeInvalidArgs(t *testing.T) {
+	cases := []struct {
+		uid  string
+		t    time.Time
+		want string
+	}{
+		{"", time.Now(), "uid must be a non-empty string"},
+		{"uid", time.Time{}, "revocation time must not be zero"},
+		{"uid", time.Now().Add(time.Hour), "revocation time must not be in the future"},
+	}
+	client := &Client{
+		baseClient: &baseClient{},
+	}
+	for _, tc := range cases {
+		err := client.RevokeRefreshTokensIssuedBefore(context.Background(), tc.uid, tc.t)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("RevokeRefreshTokensIssuedBefore(%q, %v) = %v; want = %q", tc.uid, tc.t, err, tc.want)
+		}
+	}
+}
+
+func TestUserMetadataLastRefresh(t *testing.T) {
+	m := &UserMetadata{LastRefreshTimestamp: 1614776780542}
+	if got, want := m.LastRefreshTime(), time.Unix(1614776780, 542000000); !got.Equal(want) {
+		t.Errorf("LastRefreshTime() = %v; want = %v", got, want)
+	}
+	if !m.ActiveSince(time.Unix(1614776780, 542000000)) || !m.ActiveSince(time.Unix(1614776780, 0)) {
+		t.Errorf("ActiveSince(before last refresh) = false; want = true")
+	}
+	if m.ActiveSince(time.Unix(1614776781, 0)) {
+		t.Errorf("ActiveSince(after last refresh) = true; want = false")
+	}
+
+	never := &UserMetadata{}
+	if !never.LastRefreshTime().IsZero() || never.ActiveSince(time.Time{}) {
+		t.Errorf("LastRefreshTime() = %v; want = zero time and not active", never.LastRefreshTime())
+	}
+}
+
+func TestRemoveMultiFactorEnrollments(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	if err := s.Client.RemoveMultiFactorEnrollments(context.Background(), "uid", "enrolledPhoneFactor"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Req) != 2 {
+		t.Fatalf("Requests = %d; want = 2", len(s.Req))
+	}
+	wantPath := "/projects/mock-project-id/accounts:update"
+	if s.Req[1].RequestURI != wantPath {
+		t.Errorf("RemoveMultiFactorEnrollments() URL = %q; want = %q", s.Req[1].RequestURI, wantPath)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(s.Rbody, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"localId": "uid",
+		"mfa": map[string]interface{}{
+			"enrollments": []interface{}{
+				map[string]interface{}{
+					"totpInfo":        map[string]interface{}{},
+					"mfaEnrollmentId": "enrolledTOTPFactor",
+					"displayName":     "My MFA TOTP",
+					"enrolledAt":      "2021-03-03T13:06:20.542896Z",
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RemoveMultiFactorEnrollments() request = %v; want = %v", got, want)
+	}
+}
+
+func TestRemoveAllMultiFactorEnrollments(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	err := s.Client.RemoveMultiFactorEnrollments(
+		context.Background(), "uid", "enrolledTOTPFactor", "enrolledPhoneFactor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"localId":"uid","mfa":{}}`
+	if string(s.Rbody) != want {
+		t.Errorf("RemoveMultiFactorEnrollments() request = %s; want = %s", string(s.Rbody), want)
+	}
+}
+
+func TestRemoveMultiFactorEnrollmentsNotEnrolled(t *testing.T) {
+	s := echoServer(testGetUserResponse, t)
+	defer s.Close()
+
+	err := s.Client.RemoveMultiFactorEnrollments(context.Background(), "uid", "enrolledPhoneFactor", "unknown")
+	want := `no second factor with uid "unknown" is enrolled for user "uid"`
+	if err == nil || err.Error() != want {
+		t.Errorf("RemoveMultiFactorEnrollments() = %v; want = %q", err, want)
+	}
+	if len(s.Req) != 1 {
+		t.Errorf("Requests = %d; want = 1", len(s.Req))
+	}
+}
+
+func TestInvalidRemoveMultiFactorEnrollments(t *testing.T) {
+	cases := []struct {
+		uid        string
+		factorUIDs []string
+		want       string
+	}{
+		{"", []string{"factor"}, "uid must be a non-empty string"},
+		{"uid", nil, "at least one second factor uid must be specified"},
+		{"uid", []string{""}, "second factor uid must be a non-empty string"},
+	}
+	client := &Client{
+		baseClient: &baseClient{},
+	}
+	for _, tc := range cases {
+		err := client.RemoveMultiFactorEnrollments(context.Background(), tc.uid, tc.factorUIDs...)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("RemoveMultiFactorEnrollments(%q, %v) = %v; want = %q", tc.uid, tc.factorUIDs, err, tc.want)
+		}
+	}
+}
+
+func TestResetMultiFactorEnrollments(t *testing.T) {
+	s := echoServer([]byte(`{"localId": "uid"}`), t)
+	defer s.Close()
+
+	if err := s.Client.ResetMultiFactorEnrollments(context.Background(), "uid"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"localId":"uid","mfa":{}}`
+	if string(s.Rbody) != want {
+		t.Errorf("ResetMultiFactorEnrollments() request = %s; want = %s", string(s.Rbody), want)
+	}
+	if len(s.Req) != 1 {
+		t.Errorf("Requests = %d; want = 1", len(s.Req))
+	}
+
+	if err := s.Client.ResetMultiFactorEnrollments(context.Background(), ""); err == nil {
+		t.Errorf("ResetMultiFactorEnrollments(\"\") = nil; want = error")
+	}
+}
+
+// claimsServer emulates the lookup and update endpoints for the custom claims of a single user.
+// The onLookup hook is called before each lookup is served, and the onUpdate hook after each update
+// is stored. Both may modify the stored claims to simulate concurrent updates.
+type claimsServer struct {
+	*mockAuthServer
+	attrs    string
+	lookups  int
+	updates  []string
+	onLookup func(cs *claimsServer)
+	onUpdate func(cs *claimsServer)
+}
+
+func newClaimsServer(t *testing.T, attrs string) *claimsServer {
+	cs := &claimsServer{attrs: attrs}
+	cs.mockAuthServer = bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		if _, ok := body["customAttributes"]; ok {
+			cs.attrs = body["customAttributes"].(string)
+			cs.updates = append(cs.updates, cs.attrs)
+			if cs.onUpdate != nil {
+				cs.onUpdate(cs)
+			}
+			w.Write([]byte(`{"localId": "uid"}`))
+			return
+		}
+		cs.lookups++
+		if cs.onLookup != nil {
+			cs.onLookup(cs)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"users": []map[string]interface{}{{"localId": "uid", "customAttributes": cs.attrs}},
+		})
+	})
+	return cs
+}
+
+// setClaimsUpdateBackoff sets the backoff of UpdateCustomClaims, and returns a function that
+// restores it.
+func setClaimsUpdateBackoff(d time.Duration) func() {
+	old := claimsUpdateBackoff
+	claimsUpdateBackoff = d
+	return func() { claimsUpdateBackoff = old }
+}
+
+func TestUpdateCustomClaims(t *testing.T) {
+	s := newClaimsServer(t, `{"roles": ["reader"], "level": 1}`)
+	defer s.Close()
+
+	err := s.Client.UpdateCustomClaims(context.Background(), "uid",
+		func(claims map[string]interface{}) (map[string]interface{}, error) {
+			want := map[string]interface{}{"roles": []interface{}{"reader"}, "level": float64(1)}
+			if !reflect.DeepEqual(claims, want) {
+				t.Errorf("claims = %v; want = %v", claims, want)
+			}
+			claims["roles"] = append(claims["roles"].([]interface{}), "writer")
+			delete(claims, "level")
+			return claims, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{`{"roles":["reader","writer"]}`}
+	if !reflect.DeepEqual(s.updates, want) {
+		t.Errorf("updates = %v; want = %v", s.updates, want)
+	}
+	if s.lookups != 2 {
+		t.Errorf("lookups = %d; want = 2", s.lookups)
+	}
+}
+
+func TestUpdateCustomClaimsNoChange(t *testing.T) {
+	s := newClaimsServer(t, `{"admin": true}`)
+	defer s.Close()
+
+	err := s.Client.UpdateCustomClaims(context.Background(), "uid",
+		func(claims map[string]interface{}) (map[string]interface{}, error) {
+			claims["admin"] = true
+			return claims, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.updates) != 0 {
+		t.Errorf("updates = %v; want = none", s.updates)
+	}
+}
+
+func TestUpdateCustomClaimsRemoveAll(t *testing.T) {
+	s := newClaimsServer(t, `{"admin": true}`)
+	defer s.Close()
+
+	err := s.Client.UpdateCustomClaims(context.Background(), "uid",
+		func(claims map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"{}"}; !reflect.DeepEqual(s.updates, want) {
+		t.Errorf("updates = %v; want = %v", s.updates, want)
+	}
+}
+
+func TestUpdateCustomClaimsConcurrentModification(t *testing.T) {
+	defer setClaimsUpdateBackoff(time.Millisecond)()
+	s := newClaimsServer(t, `{}`)
+	defer s.Close()
+	// Another client adds its claim between the first read and the compare step.
+	s.onLookup = func(cs *claimsServer) {
+		if cs.lookups == 2 {
+			cs.attrs = `{"b": true}`
+		}
+	}
+
+	calls := 0
+	err := s.Client.UpdateCustomClaims(context.Background(), "uid",
+		func(claims map[string]interface{}) (map[string]interface{}, error) {
+			calls++
+			claims["a"] = true
+			return claims, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Errorf("update calls = %d; want = 2", calls)
+	}
+	if want := []string{`{"a":true,"b":true}`}; !reflect.DeepEqual(s.updates, want) {
+		t.Errorf("updates = %v; want = %v", s.updates, want)
+	}
+}
+
+func TestUpdateCustomClaimsNoRetryAfterWrite(t *testing.T) {
+	defer setClaimsUpdateBackoff(time.Millisecond)()
+	s := newClaimsServer(t, `{"counter": 0}`)
+	defer s.Close()
+	// Another client adds its claim on top of ours right after the write.
+	s.onUpdate = func(cs *claimsServer) {
+		if len(cs.updates) == 1 {
+			cs.attrs = `{"counter":1,"b":true}`
+		}
+	}
+
+	calls := 0
+	err := s.Client.UpdateCustomClaims(context.Background(), "uid",
+		func(claims map[string]interface{}) (map[string]interface{}, error) {
+			calls++
+			claims["counter"] = claims["counter"].(float64) + 1
+			return claims, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Errorf("update calls = %d; want = 1", calls)
+	}
+	if want := []string{`{"counter":1}`}; !reflect.DeepEqual(s.updates, want) {
+		t.Errorf("updates = %v; want = %v", s.updates, want)
+	}
+	if want := `{"counter":1,"b":true}`; s.attrs != want {
+		t.Errorf("claims = %s; want = %s", s.attrs, want)
+	}
+}
+
+func TestUpdateCustomClaimsGiveUp(t *testing.T) {
+	defer setClaimsUpdateBackoff(time.Millisecond)()
+	s := newClaimsServer(t, `{}`)
+	defer s.Close()
+	s.onLookup = func(cs *claimsServer) {
+		cs.attrs = fmt.Sprintf(`{"counter": %d}`, cs.lookups)
+	}
+
+	err := s.Client.UpdateCustomClaims(context.Background(), "uid",
+		func(claims map[string]interface{}) (map[string]interface{}, error) {
+			claims["a"] = true
+			return claims, nil
+		})
+	if !IsConcurrentModification(err) || !errorutils.IsAborted(err) {
+		t.Errorf("UpdateCustomClaims() = %v; want = ConcurrentModification", err)
+	}
+	if len(s.updates) != 0 || s.lookups != 2*maxClaimsUpdateAttempts {
+		t.Errorf("(updates, lookups) = (%d, %d); want = (0, %d)", len(s.updates), s.lookups, 2*maxClaimsUpdateAttempts)
+	}
+}
+
+func TestUpdateCustomClaimsCanceledDuringBackoff(t *testing.T) {
+	defer setClaimsUpdateBackoff(time.Hour)()
+	s := newClaimsServer(t, `{}`)
+	defer s.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	// Another client modifies the claims before the compare step, and the caller gives up while
+	// waiting to retry.
+	s.onLookup = func(cs *claimsServer) {
+		if cs.lookups == 2 {
+			cs.attrs = `{"b": true}`
+			cancel()
+		}
+	}
+
+	err := s.Client.UpdateCustomClaims(ctx, "uid",
+		func(claims map[string]interface{}) (map[string]interface{}, error) {
+			claims["a"] = true
+			return claims, nil
+		})
+	if err != context.Canceled {
+		t.Errorf("UpdateCustomClaims() = %v; want = %v", err, context.Canceled)
+	}
+	if len(s.updates) != 0 || s.lookups != 2 {
+		t.Errorf("(updates, lookups) = (%d, %d); want = (0, 2)", len(s.updates), s.lookups)
+	}
+}
+
+func TestUpdateCustomClaimsError(t *testing.T) {
+	s := newClaimsServer(t, `{}`)
+	defer s.Close()
+
+	cases := []struct {
+		name   string
+		uid    string
+		update func(map[string]interface{}) (map[string]interface{}, error)
+		want   string
+	}{
+		{
+			"EmptyUID",
+			"",
+			func(claims map[string]interface{}) (map[string]interface{}, error) { return claims, nil },
+			"uid must be a non-empty string",
+		},
+		{
+			"NilUpdate",
+			"uid",
+			nil,
+			"update function must not be nil",
+		},
+		{
+			"UpdateError",
+			"uid",
+			func(claims map[string]interface{}) (map[string]interface{}, error) {
+				return nil, errors.New("update failed")
+			},
+			"update failed",
+		},
+		{
+			"ReservedClaim",
+			"uid",
+			func(claims map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"iss": "x"}, nil
+			},
+			`claim "iss" is reserved and must not be set`,
+		},
+		{
+			"TooLarge",
+			"uid",
+			func(claims map[string]interface{}) (map[string]interface{}, error) {
+				return map[string]interface{}{"a": strings.Repeat("a", 1000)}, nil
+			},
+			"serialized custom claims must not exceed 1000 characters",
+		},
+	}
+	for _, tc := range cases {
+		err := s.Client.UpdateCustomClaims(context.Background(), tc.uid, tc.update)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("UpdateCustomClaims(%s) = %v; want = %q", tc.name, err, tc.want)
+		}
+	}
+	if len(s.updates) != 0 {
+		t.Errorf("updates = %v; want = none", s.updates)
+	}
+}
+
 func TestInvalidSetCustomClaims(t *testing.T) {
 	cases := []struct {
 		cc   map[string]interface{}
@@ -2387,6 +2928,11 @@ func TestHTTPErrorWithCode(t *testing.T) {
 			errorutils.IsAlreadyExists,
 			"user with the provided email already exists",
 		},
+		"EXPIRED_OOB_CODE": {
+			IsExpiredActionCode,
+			errorutils.IsInvalidArgument,
+			"the action code has expired",
+		},
 		"INVALID_DYNAMIC_LINK_DOMAIN": {
 			IsInvalidDynamicLinkDomain,
 			errorutils.IsInvalidArgument,
@@ -2397,11 +2943,26 @@ func TestHTTPErrorWithCode(t *testing.T) {
 			errorutils.IsInvalidArgument,
 			"the provided hosting link domain is not configured in Firebase Hosting or is not owned by the current project",
 		},
+		"PASSWORD_DOES_NOT_MEET_REQUIREMENTS": {
+			IsPasswordDoesNotMeetRequirements,
+			errorutils.IsInvalidArgument,
+			"the password does not meet the password policy requirements",
+		},
+		"INVALID_OOB_CODE": {
+			IsInvalidActionCode,
+			errorutils.IsInvalidArgument,
+			"the action code is invalid; it may be malformed or already used",
+		},
 		"PHONE_NUMBER_EXISTS": {
 			IsPhoneNumberAlreadyExists,
 			errorutils.IsAlreadyExists,
 			"user with the provided phone number already exists",
 		},
+		"QUOTA_EXCEEDED": {
+			IsQuotaExceeded,
+			errorutils.IsResourceExhausted,
+			"the request exceeded a quota of the project",
+		},
 		"UNAUTHORIZED_DOMAIN": {
 			IsUnauthorizedContinueURI,
 			errorutils.IsInvalidArgument,
@@ -0,0 +1,280 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/iterator"
+)
+
+const maxQueryUsersResults = 500
+
+// UserQuery is a set of filters used to search for user accounts with SearchUsers.
+//
+// Email, PhoneNumber and UID filters are evaluated by the backend. All the other filters are not
+// supported by the backend, and are evaluated client-side on each page of results. A query that
+// only has client-side filters therefore reads all the user accounts of the project or tenant.
+//
+// All the filters of a query must match for a user account to be returned.
+type UserQuery struct {
+	email       string
+	phoneNumber string
+	uid         string
+	sortBy      SortBy
+	order       Order
+	filters     []func(*UserRecord) bool
+}
+
+// Email matches user accounts with the given email address. The comparison is case-insensitive.
+func (q *UserQuery) Email(email string) *UserQuery {
+	q.email = email
+	return q
+}
+
+// PhoneNumber matches user accounts with the given phone number.
+func (q *UserQuery) PhoneNumber(phone string) *UserQuery {
+	q.phoneNumber = phone
+	return q
+}
+
+// UID matches the user account with the given uid.
+func (q *UserQuery) UID(uid string) *UserQuery {
+	q.uid = uid
+	return q
+}
+
+// ProviderID matches user accounts that are linked to the given identity provider, such as
+// "password", "phone" or "google.com".
+func (q *UserQuery) ProviderID(providerID string) *UserQuery {
+	return q.where(func(u *UserRecord) bool {
+		for _, info := range u.ProviderUserInfo {
+			if info.ProviderID == providerID {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Disabled matches user accounts whose disabled state is the given value.
+func (q *UserQuery) Disabled(disabled bool) *UserQuery {
+	return q.where(func(u *UserRecord) bool {
+		return u.Disabled == disabled
+	})
+}
+
+// EmailVerified matches user accounts whose email verification state is the given value.
+func (q *UserQuery) EmailVerified(verified bool) *UserQuery {
+	return q.where(func(u *UserRecord) bool {
+		return u.EmailVerified == verified
+	})
+}
+
+// CreatedBetween matches user accounts created in the given time range. Both bounds are inclusive,
+// and a zero time leaves the corresponding end of the range open.
+func (q *UserQuery) CreatedBetween(start, end time.Time) *UserQuery {
+	return q.where(func(u *UserRecord) bool {
+		if u.UserMetadata == nil {
+			return false
+		}
+		return inTimeRange(u.UserMetadata.CreationTimestamp, start, end)
+	})
+}
+
+// LastLoginBetween matches user accounts that last signed in during the given time range. Both
+// bounds are inclusive, and a zero time leaves the corresponding end of the range open. User
+// accounts that have never signed in do not match.
+func (q *UserQuery) LastLoginBetween(start, end time.Time) *UserQuery {
+	return q.where(func(u *UserRecord) bool {
+		if u.UserMetadata == nil || u.UserMetadata.LastLogInTimestamp == 0 {
+			return false
+		}
+		return inTimeRange(u.UserMetadata.LastLogInTimestamp, start, end)
+	})
+}
+
+// CustomClaim matches user accounts that have the given custom claim, and whose claim value
+// satisfies the predicate. Claim values are decoded from JSON, hence numbers are float64 values.
+//
+// The predicate may be nil, in which case user accounts that have the claim match regardless of
+// its value.
+func (q *UserQuery) CustomClaim(name string, predicate func(value interface{}) bool) *UserQuery {
+	return q.where(func(u *UserRecord) bool {
+		v, ok := u.CustomClaims[name]
+		return ok && (predicate == nil || predicate(v))
+	})
+}
+
+// CustomClaimEquals matches user accounts whose custom claim has the given value.
+//
+// The value is compared with the claim value decoded from JSON. Numbers must therefore be given
+// as float64 values.
+func (q *UserQuery) CustomClaimEquals(name string, value interface{}) *UserQuery {
+	return q.CustomClaim(name, func(v interface{}) bool {
+		return reflect.DeepEqual(v, value)
+	})
+}
+
+// Sort sets the order of the results.
+func (q *UserQuery) Sort(sortBy SortBy, order Order) *UserQuery {
+	q.sortBy = sortBy
+	q.order = order
+	return q
+}
+
+func (q *UserQuery) where(filter func(*UserRecord) bool) *UserQuery {
+	q.filters = append(q.filters, filter)
+	return q
+}
+
+// request builds the backend request for a page of results.
+//
+// The backend only evaluates a single identifier expression, so the email takes precedence over the
+// phone number, which takes precedence over the uid. The identifiers that are not sent are checked
+// client-side by matches.
+func (q *UserQuery) request(limit, offset int64) *QueryUsersRequest {
+	req := &QueryUsersRequest{
+		Limit:  limit,
+		Offset: offset,
+		SortBy: q.sortBy,
+		Order:  q.order,
+	}
+	switch {
+	case q.email != "":
+		req.Expression = []*Expression{{Email: q.email}}
+	case q.phoneNumber != "":
+		req.Expression = []*Expression{{PhoneNumber: q.phoneNumber}}
+	case q.uid != "":
+		req.Expression = []*Expression{{UID: q.uid}}
+	}
+	return req
+}
+
+func (q *UserQuery) matches(u *UserRecord) bool {
+	if q.email != "" && !strings.EqualFold(u.Email, q.email) {
+		return false
+	}
+	if q.phoneNumber != "" && u.PhoneNumber != q.phoneNumber {
+		return false
+	}
+	if q.uid != "" && u.UID != q.uid {
+		return false
+	}
+	for _, filter := range q.filters {
+		if !filter(u) {
+			return false
+		}
+	}
+	return true
+}
+
+func inTimeRange(millis int64, start, end time.Time) bool {
+	t := time.Unix(0, millis*int64(time.Millisecond))
+	if !start.IsZero() && t.Before(start) {
+		return false
+	}
+	if !end.IsZero() && t.After(end) {
+		return false
+	}
+	return true
+}
+
+// SearchUsers returns an iterator over the user accounts that match the given query.
+//
+// The iterator pages through the QueryUsers API automatically. A nil query matches all the user
+// accounts.
+func (c *baseClient) SearchUsers(ctx context.Context, query *UserQuery) *QueryUsersIterator {
+	if query == nil {
+		query = &UserQuery{}
+	}
+	it := &QueryUsersIterator{
+		ctx:    ctx,
+		client: c,
+		query:  query,
+	}
+	it.pageInfo, it.nextFunc = iterator.NewPageInfo(
+		it.fetch,
+		func() int { return len(it.users) },
+		func() interface{} { b := it.users; it.users = nil; return b })
+	it.pageInfo.MaxSize = maxQueryUsersResults
+	return it
+}
+
+// QueryUsersIterator is an iterator over the results of a UserQuery.
+//
+// Page tokens are offsets into the backend results, before any client-side filters are applied.
+type QueryUsersIterator struct {
+	client   *baseClient
+	ctx      context.Context
+	query    *UserQuery
+	nextFunc func() error
+	pageInfo *iterator.PageInfo
+	users    []*UserRecord
+}
+
+// PageInfo supports pagination.
+func (it *QueryUsersIterator) PageInfo() *iterator.PageInfo {
+	return it.pageInfo
+}
+
+// Next returns the next matching UserRecord. The error value of [iterator.Done] is
+// returned if there are no more results. Once Next returns [iterator.Done], all
+// subsequent calls will return [iterator.Done].
+func (it *QueryUsersIterator) Next() (*UserRecord, error) {
+	if err := it.nextFunc(); err != nil {
+		return nil, err
+	}
+
+	user := it.users[0]
+	it.users = it.users[1:]
+	return user, nil
+}
+
+func (it *QueryUsersIterator) fetch(pageSize int, pageToken string) (string, error) {
+	var offset int64
+	if pageToken != "" {
+		var err error
+		if offset, err = strconv.ParseInt(pageToken, 10, 64); err != nil || offset < 0 {
+			return "", fmt.Errorf("invalid page token: %q", pageToken)
+		}
+	}
+
+	limit := int64(pageSize)
+	if limit <= 0 || limit > maxQueryUsersResults {
+		limit = maxQueryUsersResults
+	}
+	result, err := it.client.QueryUsers(it.ctx, it.query.request(limit, offset))
+	if err != nil {
+		return "", err
+	}
+
+	for _, u := range result.Users {
+		if it.query.matches(u) {
+			it.users = append(it.users, u)
+		}
+	}
+
+	offset += int64(len(result.Users))
+	if int64(len(result.Users)) < limit || (result.Count > 0 && offset >= result.Count) {
+		return "", nil
+	}
+	return strconv.FormatInt(offset, 10), nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/api/iterator"
+)
+
+// queryUsersServer serves n user accounts from the accounts:query endpoint, honoring the limit and
+// offset of each request. Even numbered users are disabled, users with a number divisible by 3
+// have verified emails and a "premium" custom claim, and user i was created at i * 1000 seconds.
+func queryUsersServer(t *testing.T, n int, requests *[]map[string]interface{}) *mockAuthServer {
+	return bulkServer(t, func(w http.ResponseWriter, body map[string]interface{}) {
+		*requests = append(*requests, body)
+		limit, _ := strconv.Atoi(fmt.Sprint(body["limit"]))
+		offset, _ := strconv.Atoi(fmt.Sprint(body["offset"]))
+
+		users := []map[string]interface{}{}
+		for i := offset; i < n && i < offset+limit; i++ {
+			provider := "password"
+			if i%2 == 1 {
+				provider = "google.com"
+			}
+			user := map[string]interface{}{
+				"localId":          fmt.Sprintf("user%d", i),
+				"email":            fmt.Sprintf("user%d@example.com", i),
+				"disabled":         i%2 == 0,
+				"emailVerified":    i%3 == 0,
+				"createdAt":        strconv.Itoa(i * 1000000),
+				"providerUserInfo": []map[string]interface{}{{"providerId": provider}},
+			}
+			if i%3 == 0 {
+				user["customAttributes"] = fmt.Sprintf(`{"premium": true, "level": %d}`, i)
+			}
+			if i > 0 {
+				user["lastLoginAt"] = strconv.Itoa(i * 2000000)
+			}
+			users = append(users, user)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"userInfo":     users,
+			"recordsCount": strconv.Itoa(n),
+		})
+	})
+}
+
+func searchUIDs(t *testing.T, it *QueryUsersIterator) []string {
+	var uids []string
+	for {
+		user, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		uids = append(uids, user.UID)
+	}
+	return uids
+}
+
+func TestSearchUsers(t *testing.T) {
+	var requests []map[string]interface{}
+	s := queryUsersServer(t, 1200, &requests)
+	defer s.Close()
+
+	it := s.Client.SearchUsers(context.Background(), nil)
+	uids := searchUIDs(t, it)
+	if len(uids) != 1200 || uids[0] != "user0" || uids[1199] != "user1199" {
+		t.Errorf("SearchUsers() = %d users; want = 1200", len(uids))
+	}
+
+	wantOffsets := []interface{}{nil, "500", "1000"}
+	if len(requests) != len(wantOffsets) {
+		t.Fatalf("SearchUsers() requests = %d; want = %d", len(requests), len(wantOffsets))
+	}
+	for i, req := range requests {
+		if req["offset"] != wantOffsets[i] || req["limit"] != "500" {
+			t.Errorf("SearchUsers() request[%d] = %v; want offset = %v", i, req, wantOffsets[i])
+		}
+	}
+}
+
+func TestSearchUsersFilters(t *testing.T) {
+	cases := []struct {
+		name  string
+		query *UserQuery
+		want  []string
+	}{
+		{
+			"ProviderID",
+			(&UserQuery{}).ProviderID("google.com").UID("user3"),
+			[]string{"user3"},
+		},
+		{
+			"DisabledAndEmailVerified",
+			(&UserQuery{}).Disabled(true).EmailVerified(true),
+			[]string{"user0", "user6"},
+		},
+		{
+			"CreatedBetween",
+			(&UserQuery{}).CreatedBetween(time.Unix(2000, 0), time.Unix(4000, 0)),
+			[]string{"user2", "user3", "user4"},
+		},
+		{
+			"LastLoginBetween",
+			(&UserQuery{}).LastLoginBetween(time.Time{}, time.Unix(4000, 0)),
+			[]string{"user1", "user2"},
+		},
+		{
+			"CustomClaim",
+			(&UserQuery{}).CustomClaim("premium", nil),
+			[]string{"user0", "user3", "user6"},
+		},
+		{
+			"CustomClaimPredicate",
+			(&UserQuery{}).CustomClaim("level", func(v interface{}) bool {
+				level, ok := v.(float64)
+				return ok && level >= 3
+			}),
+			[]string{"user3", "user6"},
+		},
+		{
+			"CustomClaimEquals",
+			(&UserQuery{}).CustomClaimEquals("level", float64(6)),
+			[]string{"user6"},
+		},
+		{
+			"NoMatch",
+			(&UserQuery{}).Disabled(true).ProviderID("google.com"),
+			nil,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []map[string]interface{}
+			s := queryUsersServer(t, 8, &requests)
+			defer s.Close()
+
+			uids := searchUIDs(t, s.Client.SearchUsers(context.Background(), tc.query))
+			if !reflect.DeepEqual(uids, tc.want) {
+				t.Errorf("SearchUsers() = %v; want = %v", uids, tc.want)
+			}
+		})
+	}
+}
+
+func TestSearchUsersPageSize(t *testing.T) {
+	var requests []map[string]interface{}
+	s := queryUsersServer(t, 10, &requests)
+	defer s.Close()
+
+	it := s.Client.SearchUsers(context.Background(), (&UserQuery{}).Disabled(false))
+	pager := iterator.NewPager(it, 4, "")
+	var page []*UserRecord
+	token, err := pager.NextPage(&page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uids []string
+	for _, u := range page {
+		uids = append(uids, u.UID)
+	}
+	if want := []string{"user1", "user3", "user5", "user7"}; !reflect.DeepEqual(uids, want) || token != "8" {
+		t.Errorf("NextPage() = (%v, %q); want = (%v, %q)", uids, token, want, "8")
+	}
+
+	it = s.Client.SearchUsers(context.Background(), nil)
+	it.PageInfo().Token = "8"
+	if uids := searchUIDs(t, it); !reflect.DeepEqual(uids, []string{"user8", "user9"}) {
+		t.Errorf("SearchUsers(token = 8) = %v; want = [user8 user9]", uids)
+	}
+}
+
+func TestSearchUsersRequest(t *testing.T) {
+	var requests []map[string]interface{}
+	s := queryUsersServer(t, 0, &requests)
+	defer s.Close()
+
+	query := (&UserQuery{}).
+		UID("user1").
+		Email("User1@Example.com").
+		Sort(CreatedAt, Desc)
+	if uids := searchUIDs(t, s.Client.SearchUsers(context.Background(), query)); uids != nil {
+		t.Errorf("SearchUsers() = %v; want = nil", uids)
+	}
+
+	want := map[string]interface{}{
+		"returnUserInfo": true,
+		"limit":          "500",
+		"sortBy":         "CREATED_AT",
+		"order":          "DESC",
+		"expression":     []interface{}{map[string]interface{}{"email": "User1@Example.com"}},
+	}
+	if !reflect.DeepEqual(requests[0], want) {
+		t.Errorf("SearchUsers() request = %v; want = %v", requests[0], want)
+	}
+}
+
+func TestUserQueryMatchesIdentifiers(t *testing.T) {
+	user := &UserRecord{
+		UserInfo: &UserInfo{UID: "user1", Email: "user1@example.com", PhoneNumber: "+11234567890"},
+	}
+	cases := []struct {
+		query *UserQuery
+		want  bool
+	}{
+		{(&UserQuery{}).Email("USER1@example.com"), true},
+		{(&UserQuery{}).Email("user1@example.com").UID("user1").PhoneNumber("+11234567890"), true},
+		{(&UserQuery{}).Email("user1@example.com").UID("user2"), false},
+		{(&UserQuery{}).PhoneNumber("+10987654321"), false},
+	}
+	for i, tc := range cases {
+		if got := tc.query.matches(user); got != tc.want {
+			t.Errorf("matches(%d) = %v; want = %v", i, got, tc.want)
+		}
+	}
+}
+
+func TestSearchUsersError(t *testing.T) {
+	s := echoServer([]byte(`{"error": {"message": "INVALID_QUERY"}}`), t)
+	defer s.Close()
+	s.Status = http.StatusBadRequest
+	s.Client.baseClient.httpClient.RetryConfig = nil
+
+	it := s.Client.SearchUsers(context.Background(), nil)
+	if user, err := it.Next(); user != nil || err == nil {
+		t.Errorf("Next() = (%v, %v); want = (nil, error)", user, err)
+	}
+
+	it = s.Client.SearchUsers(context.Background(), (&UserQuery{}).Email("not-an-email"))
+	if user, err := it.Next(); user != nil || err == nil {
+		t.Errorf("Next() = (%v, %v); want = (nil, error)", user, err)
+	}
+
+	it = s.Client.SearchUsers(context.Background(), nil)
+	it.PageInfo().Token = "invalid"
+	if user, err := it.Next(); user != nil || err == nil || err.Error() != `invalid page token: "invalid"` {
+		t.Errorf("Next() = (%v, %v); want = (nil, invalid page token)", user, err)
+	}
+}
@@ -144,7 +144,10 @@ func (a *App) Messaging(ctx context.Context) (*messaging.Client, error) {
 // AppCheck returns an instance of appcheck.Client.
 func (a *App) AppCheck(ctx context.Context) (*appcheck.Client, error) {
 	conf := &internal.AppCheckConfig{
-		ProjectID: a.projectID,
+		ProjectID:        a.projectID,
+		Opts:             a.opts,
+		ServiceAccountID: a.serviceAccountID,
+		Version:          Version,
 	}
 	return appcheck.NewClient(ctx, conf)
 }
@@ -174,6 +177,8 @@ func (a *App) PhoneNumberVerification(ctx context.Context) (*phonenumberverifica
 
 	conf := &internal.PhoneNumberVerificationConfig{
 		ProjectID: a.projectID,
+		Opts:      a.opts,
+		Version:   Version,
 	}
 	client, err := phonenumberverification.NewClient(ctx, conf)
 	if err != nil {
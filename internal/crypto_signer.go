@@ -0,0 +1,283 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/transport"
+)
+
+const algorithmRS256 = "RS256"
+
+// CryptoSigner is used to cryptographically sign data, and query the identity of the signer.
+type CryptoSigner interface {
+	Algorithm() string
+	Sign(context.Context, []byte) ([]byte, error)
+	Email(context.Context) (string, error)
+}
+
+// NewCryptoSigner creates a CryptoSigner by following the go/firebase-admin-sign protocol:
+//   - If the client options contain service account credentials, uses the private key present in
+//     the credentials to sign data locally.
+//   - If a service account ID is specified, calls the IAMCredentials service with that service
+//     account to sign data remotely.
+//   - Otherwise uses the signing capabilities of the current platform (App Engine, or the IAM
+//     service in conjunction with the local Metadata server).
+func NewCryptoSigner(ctx context.Context, opts []option.ClientOption, serviceAccountID, version string) (CryptoSigner, error) {
+	creds, _ := transport.Creds(ctx, opts...)
+	if creds != nil && len(creds.JSON) > 0 {
+		signer, err := SignerFromCreds(creds.JSON)
+		if err == nil {
+			return signer, nil
+		}
+		if err != ErrNotAServiceAcct {
+			return nil, err
+		}
+	}
+
+	if serviceAccountID != "" {
+		return NewIAMSigner(ctx, opts, serviceAccountID, version)
+	}
+
+	return newPlatformSigner(ctx, opts, version)
+}
+
+// EncodeJWT encodes the given header and payload into a JSON web token signed by the given
+// signer.
+func EncodeJWT(ctx context.Context, signer CryptoSigner, header, payload interface{}) (string, error) {
+	encode := func(i interface{}) (string, error) {
+		b, err := json.Marshal(i)
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	}
+	encodedHeader, err := encode(header)
+	if err != nil {
+		return "", err
+	}
+	encodedPayload, err := encode(payload)
+	if err != nil {
+		return "", err
+	}
+
+	tokenData := fmt.Sprintf("%s.%s", encodedHeader, encodedPayload)
+	sig, err := signer.Sign(ctx, []byte(tokenData))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%s", tokenData, base64.RawURLEncoding.EncodeToString(sig)), nil
+}
+
+type serviceAccount struct {
+	PrivateKey  string `json:"private_key"`
+	ClientEmail string `json:"client_email"`
+}
+
+// ErrNotAServiceAcct is returned by SignerFromCreds when the credentials JSON is not a service
+// account.
+var ErrNotAServiceAcct = errors.New("credentials json is not a service account")
+
+// SignerFromCreds creates a ServiceAccountSigner from the given service account credentials JSON.
+func SignerFromCreds(creds []byte) (CryptoSigner, error) {
+	var sa serviceAccount
+	if err := json.Unmarshal(creds, &sa); err != nil {
+		return nil, err
+	}
+	if sa.PrivateKey != "" && sa.ClientEmail != "" {
+		return newServiceAccountSigner(sa)
+	}
+	return nil, ErrNotAServiceAcct
+}
+
+// ServiceAccountSigner is a CryptoSigner that signs data using service account credentials.
+type ServiceAccountSigner struct {
+	privateKey  *rsa.PrivateKey
+	clientEmail string
+}
+
+func newServiceAccountSigner(sa serviceAccount) (*ServiceAccountSigner, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("no private key data found in: %q", sa.PrivateKey)
+	}
+	parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsedKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("private key should be a PEM or plain PKCS1 or PKCS8; parse error: %v", err)
+		}
+	}
+	rsaKey, ok := parsedKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return &ServiceAccountSigner{
+		privateKey:  rsaKey,
+		clientEmail: sa.ClientEmail,
+	}, nil
+}
+
+// Algorithm returns the JWT algorithm name of the signatures produced by this signer.
+func (s ServiceAccountSigner) Algorithm() string {
+	return algorithmRS256
+}
+
+// Sign signs the given bytes using the service account private key.
+func (s ServiceAccountSigner) Sign(ctx context.Context, b []byte) ([]byte, error) {
+	hash := sha256.New()
+	hash.Write(b)
+	return rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, hash.Sum(nil))
+}
+
+// Email returns the client email of the service account.
+func (s ServiceAccountSigner) Email(ctx context.Context) (string, error) {
+	return s.clientEmail, nil
+}
+
+// IAMSigner is a CryptoSigner that signs data by sending them to the IAMCredentials service. See
+// https://cloud.google.com/iam/docs/reference/credentials/rest/v1/projects.serviceAccounts/signBlob
+// for details regarding the REST API.
+//
+// IAMCredentials requires the identity of a service account. This can be specified explicitly
+// at initialization. If not specified IAMSigner attempts to discover a service account identity by
+// calling the local metadata service (works in environments like Google Compute Engine).
+type IAMSigner struct {
+	// HTTPClient is the client used to call the IAMCredentials service.
+	HTTPClient *HTTPClient
+
+	mutex        *sync.Mutex
+	serviceAcct  string
+	metadataHost string
+	iamHost      string
+}
+
+// NewIAMSigner creates a new IAMSigner from the given client options.
+//
+// If serviceAccountID is empty, the service account identity is discovered from the local
+// metadata service on first use.
+func NewIAMSigner(ctx context.Context, opts []option.ClientOption, serviceAccountID, version string) (*IAMSigner, error) {
+	hc, _, err := NewHTTPClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	hc.Opts = []HTTPOption{
+		WithHeader("x-goog-api-client", GetMetricsHeader(version)),
+	}
+
+	return &IAMSigner{
+		mutex:        &sync.Mutex{},
+		HTTPClient:   hc,
+		serviceAcct:  serviceAccountID,
+		metadataHost: "http://metadata.google.internal",
+		iamHost:      "https://iamcredentials.googleapis.com",
+	}, nil
+}
+
+// Algorithm returns the JWT algorithm name of the signatures produced by this signer.
+func (s IAMSigner) Algorithm() string {
+	return algorithmRS256
+}
+
+// Sign signs the given bytes by calling the IAMCredentials signBlob API.
+func (s IAMSigner) Sign(ctx context.Context, b []byte) ([]byte, error) {
+	account, err := s.Email(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1/projects/-/serviceAccounts/%s:signBlob", s.iamHost, account)
+	body := map[string]interface{}{
+		"payload": base64.StdEncoding.EncodeToString(b),
+	}
+	req := &Request{
+		Method: http.MethodPost,
+		URL:    url,
+		Body:   NewJSONEntity(body),
+	}
+	var signResponse struct {
+		Signature string `json:"signedBlob"`
+	}
+	if _, err := s.HTTPClient.DoAndUnmarshal(ctx, req, &signResponse); err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(signResponse.Signature)
+}
+
+// Email returns the service account used to sign data, discovering it from the local metadata
+// service when necessary.
+func (s IAMSigner) Email(ctx context.Context) (string, error) {
+	if s.serviceAcct != "" {
+		return s.serviceAcct, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result, err := s.callMetadataService(ctx)
+	if err != nil {
+		msg := "failed to determine service account: %v; initialize the SDK with service " +
+			"account credentials or specify a service account with iam.serviceAccounts.signBlob " +
+			"permission; refer to https://firebase.google.com/docs/auth/admin/create-custom-tokens " +
+			"for more details on creating custom tokens"
+		return "", fmt.Errorf(msg, err)
+	}
+
+	s.serviceAcct = result
+	return result, nil
+}
+
+func (s IAMSigner) callMetadataService(ctx context.Context) (string, error) {
+	// Use the built-in default client without request authorization or retries for this call.
+	noAuthClient := &HTTPClient{
+		Client: http.DefaultClient,
+	}
+
+	url := fmt.Sprintf("%s/computeMetadata/v1/instance/service-accounts/default/email", s.metadataHost)
+	req := &Request{
+		Method: http.MethodGet,
+		URL:    url,
+		Opts: []HTTPOption{
+			WithHeader("Metadata-Flavor", "Google"),
+		},
+	}
+
+	resp, err := noAuthClient.Do(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	result := strings.TrimSpace(string(resp.Body))
+	if result == "" {
+		return "", errors.New("unexpected response from metadata service")
+	}
+
+	return result, nil
+}
@@ -15,21 +15,25 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package auth
+package internal
 
 import (
 	"context"
 
-	"firebase.google.com/go/v4/internal"
+	"google.golang.org/api/option"
 	"google.golang.org/appengine/v2"
 )
 
 type aeSigner struct{}
 
-func newCryptoSigner(ctx context.Context, conf *internal.AuthConfig) (cryptoSigner, error) {
+func newPlatformSigner(ctx context.Context, opts []option.ClientOption, version string) (CryptoSigner, error) {
 	return aeSigner{}, nil
 }
 
+func (s aeSigner) Algorithm() string {
+	return algorithmRS256
+}
+
 func (s aeSigner) Email(ctx context.Context) (string, error) {
 	return appengine.ServiceAccount(ctx)
 }
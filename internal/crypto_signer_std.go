@@ -15,14 +15,14 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package auth
+package internal
 
 import (
 	"context"
 
-	"firebase.google.com/go/v4/internal"
+	"google.golang.org/api/option"
 )
 
-func newCryptoSigner(ctx context.Context, conf *internal.AuthConfig) (cryptoSigner, error) {
-	return newIAMSigner(ctx, conf)
+func newPlatformSigner(ctx context.Context, opts []option.ClientOption, version string) (CryptoSigner, error) {
+	return NewIAMSigner(ctx, opts, "", version)
 }
@@ -0,0 +1,268 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+const testVersion = "test-version"
+
+var testOpts = []option.ClientOption{
+	option.WithTokenSource(&MockTokenSource{AccessToken: "test.token"}),
+}
+
+func TestServiceAccountSigner(t *testing.T) {
+	b, err := ioutil.ReadFile("../testdata/service_account.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sa serviceAccount
+	if err := json.Unmarshal(b, &sa); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := newServiceAccountSigner(sa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	algorithm := signer.Algorithm()
+	if algorithm != algorithmRS256 {
+		t.Errorf("Algorithm() = %q; want = %q", algorithm, algorithmRS256)
+	}
+	email, err := signer.Email(context.Background())
+	if email != sa.ClientEmail || err != nil {
+		t.Errorf("Email() = (%q, %v); want = (%q, nil)", email, err, sa.ClientEmail)
+	}
+	sign, err := signer.Sign(context.Background(), []byte("test"))
+	if sign == nil || err != nil {
+		t.Errorf("Sign() = (%v, %v); want = (bytes, nil)", email, err)
+	}
+}
+
+type fixedSigner struct{}
+
+func (s fixedSigner) Algorithm() string                         { return algorithmRS256 }
+func (s fixedSigner) Email(ctx context.Context) (string, error) { return "", nil }
+func (s fixedSigner) Sign(ctx context.Context, b []byte) ([]byte, error) {
+	return []byte("signature"), nil
+}
+
+func TestEncodeJWT(t *testing.T) {
+	header := map[string]string{"alg": algorithmRS256, "typ": "JWT"}
+	payload := map[string]interface{}{"sub": "subject"}
+	token, err := EncodeJWT(context.Background(), fixedSigner{}, header, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segments := strings.Split(token, ".")
+	want := []string{
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"subject"}`)),
+		base64.RawURLEncoding.EncodeToString([]byte("signature")),
+	}
+	if strings.Join(segments, ".") != strings.Join(want, ".") {
+		t.Errorf("EncodeJWT() = %q; want = %q", token, strings.Join(want, "."))
+	}
+
+	if _, err := EncodeJWT(context.Background(), fixedSigner{}, header, func() {}); err == nil {
+		t.Errorf("EncodeJWT(invalid payload) = nil; want = error")
+	}
+}
+
+func TestIAMSigner(t *testing.T) {
+	ctx := context.Background()
+	serviceAcct := "test-service-account"
+	signer, err := NewIAMSigner(ctx, testOpts, serviceAcct, testVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	algorithm := signer.Algorithm()
+	if algorithm != algorithmRS256 {
+		t.Errorf("Algorithm() = %q; want = %q", algorithm, algorithmRS256)
+	}
+	email, err := signer.Email(ctx)
+	if email != serviceAcct || err != nil {
+		t.Errorf("Email() = (%q, %v); want = (%q, nil)", email, err, serviceAcct)
+	}
+
+	wantSignature := "test-signature"
+	server := iamServer(t, email, wantSignature)
+	defer server.Close()
+	signer.iamHost = server.URL
+
+	signature, err := signer.Sign(ctx, []byte("input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(signature) != wantSignature {
+		t.Errorf("Sign() = %q; want = %q", string(signature), wantSignature)
+	}
+}
+
+func TestIAMSignerHTTPError(t *testing.T) {
+	signer, err := NewIAMSigner(context.Background(), testOpts, "test-service-account", testVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.WriteHeader(http.StatusForbidden)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error": {"status": "PERMISSION_DENIED", "message": "test reason"}}`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	signer.iamHost = server.URL
+
+	want := "test reason"
+	_, err = signer.Sign(context.Background(), []byte("input"))
+	if err == nil || !HasPlatformErrorCode(err, PermissionDenied) || err.Error() != want {
+		t.Errorf("Sign() = %v; want = %q", err, want)
+	}
+}
+
+func TestIAMSignerUnknownHTTPError(t *testing.T) {
+	signer, err := NewIAMSigner(context.Background(), testOpts, "test-service-account", testVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.WriteHeader(http.StatusForbidden)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`not json`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	signer.iamHost = server.URL
+
+	want := "unexpected http response with status: 403\nnot json"
+	_, err = signer.Sign(context.Background(), []byte("input"))
+	if err == nil || !HasPlatformErrorCode(err, PermissionDenied) || err.Error() != want {
+		t.Errorf("Sign() = %v; want = %q", err, want)
+	}
+}
+
+func TestIAMSignerWithMetadataService(t *testing.T) {
+	ctx := context.Background()
+	signer, err := NewIAMSigner(ctx, testOpts, "", testVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// start mock metadata service and test Email()
+	serviceAcct := "discovered-service-account"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		flavor := r.Header.Get("Metadata-Flavor")
+		if flavor != "Google" {
+			t.Errorf("Header(Metadata-Flavor) = %q; want = %q", flavor, "Google")
+		}
+		w.Header().Set("Content-Type", "application/text")
+		w.Write([]byte(serviceAcct))
+	})
+	metadata := httptest.NewServer(handler)
+	defer metadata.Close()
+	signer.metadataHost = metadata.URL
+	email, err := signer.Email(ctx)
+	if email != serviceAcct || err != nil {
+		t.Errorf("Email() = (%q, %v); want = (%q, nil)", email, err, serviceAcct)
+	}
+
+	// start mock IAM service and test Sign()
+	wantSignature := "test-signature"
+	server := iamServer(t, email, wantSignature)
+	defer server.Close()
+	signer.iamHost = server.URL
+
+	signature, err := signer.Sign(ctx, []byte("input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(signature) != wantSignature {
+		t.Errorf("Sign() = %q; want = %q", string(signature), wantSignature)
+	}
+}
+
+func TestIAMSignerNoMetadataService(t *testing.T) {
+	ctx := context.Background()
+	signer, err := NewIAMSigner(ctx, testOpts, "", testVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer.metadataHost = "http://non-existing.metadata.service"
+
+	want := "failed to determine service account: "
+	_, err = signer.Email(ctx)
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Email() = %v; want = %q", err, want)
+	}
+
+	_, err = signer.Sign(ctx, []byte("input"))
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Sign() = %v; want = %q", err, want)
+	}
+}
+
+func iamServer(t *testing.T, serviceAcct, signature string) *httptest.Server {
+	resp := map[string]interface{}{
+		"signedBlob": base64.StdEncoding.EncodeToString([]byte(signature)),
+	}
+	wantPath := fmt.Sprintf("/v1/projects/-/serviceAccounts/%s:signBlob", serviceAcct)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		reqBody, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(reqBody, &m); err != nil {
+			t.Fatal(err)
+		}
+		if m["payload"] == "" {
+			t.Fatal("payload = empty; want = non-empty")
+		}
+		if r.URL.Path != wantPath {
+			t.Errorf("Path = %q; want = %q", r.URL.Path, wantPath)
+		}
+		xGoogAPIClientHeader := GetMetricsHeader(testVersion)
+		if h := r.Header.Get("x-goog-api-client"); h != xGoogAPIClientHeader {
+			t.Errorf("x-goog-api-client header = %q; want = %q", h, xGoogAPIClientHeader)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		b, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	})
+	return httptest.NewServer(handler)
+}
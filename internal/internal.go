@@ -83,12 +83,27 @@ type RemoteConfigClientConfig struct {
 
 // AppCheckConfig represents the configuration of App Check service.
 type AppCheckConfig struct {
-	ProjectID string
+	Opts             []option.ClientOption
+	ProjectID        string
+	ServiceAccountID string
+	Version          string
+	// JWKSURL overrides the URL of the JWKS used to verify tokens.
+	JWKSURL string
+	// KeySource overrides the source of the public keys used to verify tokens. JWKSURL is ignored
+	// when it is set.
+	KeySource JWKSSource
 }
 
 // PhoneNumberVerificationConfig represents the configuration of Firebase Phone Number Verification service.
 type PhoneNumberVerificationConfig struct {
+	Opts      []option.ClientOption
 	ProjectID string
+	Version   string
+	// JWKSURL overrides the URL of the JWKS used to verify tokens.
+	JWKSURL string
+	// KeySource overrides the source of the public keys used to verify tokens. JWKSURL is ignored
+	// when it is set.
+	KeySource JWKSSource
 }
 
 // MockTokenSource is a TokenSource implementation that can be used for testing.
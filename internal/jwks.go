@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MicahParks/keyfunc"
+	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/api/option"
+	"google.golang.org/api/option/internaloption"
+)
+
+// JWKSSource is used to look up the public key that should be used to verify the signature of
+// a JWT, from a JSON Web Key Set (JWKS).
+type JWKSSource interface {
+	Keyfunc(ctx context.Context, token *jwt.Token) (interface{}, error)
+}
+
+// NewStaticJWKSSource creates a JWKSSource from the given JWKS JSON document. The returned
+// source never refreshes its keys, which makes it suitable for tests.
+func NewStaticJWKSSource(jwks []byte) (JWKSSource, error) {
+	keys, err := keyfunc.NewJSON(jwks)
+	if err != nil {
+		return nil, err
+	}
+	return &staticJWKSSource{keys: keys}, nil
+}
+
+type staticJWKSSource struct {
+	keys *keyfunc.JWKS
+}
+
+func (s *staticJWKSSource) Keyfunc(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	return s.keys.Keyfunc(token)
+}
+
+// HTTPJWKSSource fetches a JWKS from a remote HTTP server, and caches it in memory.
+//
+// Keys are fetched lazily on first use, and refreshed once RefreshInterval has elapsed since the
+// last fetch attempt. When RefreshUnknownKID is set, a token with a key ID that is not in the
+// cached key set triggers a refresh, at most once every RefreshRateLimit. Failed attempts count
+// towards these intervals, and previously cached keys continue to be used if a refresh fails.
+//
+// The mutex is not held while keys are fetched. Only one fetch is in flight at a time; other
+// callers use the cached keys in the meantime, or wait for the fetch if there are none.
+type HTTPJWKSSource struct {
+	URL               string
+	HTTPClient        *HTTPClient
+	RefreshInterval   time.Duration
+	RefreshUnknownKID bool
+	RefreshRateLimit  time.Duration
+	Clock             Clock
+
+	mutex       sync.Mutex
+	keys        *keyfunc.JWKS
+	lastErr     error
+	lastAttempt time.Time
+	fetching    chan struct{}
+}
+
+// NewHTTPJWKSSource creates a new HTTPJWKSSource that fetches keys from the given URL using
+// the given HTTPClient.
+//
+// JWKS are public, and the URL may be overridden by users. The HTTPClient should therefore not
+// attach any credentials to its requests. NewJWKSHTTPClient returns such a client.
+func NewHTTPJWKSSource(url string, hc *HTTPClient) *HTTPJWKSSource {
+	return &HTTPJWKSSource{
+		URL:        url,
+		HTTPClient: hc,
+		Clock:      SystemClock,
+	}
+}
+
+// NewJWKSHTTPClient creates an HTTPClient for fetching public JWKS from the given client options.
+//
+// The options of the App are honored, including option.WithHTTPClient, proxies and transports,
+// but the credentials they provide are not attached to the requests. A client set with
+// option.WithHTTPClient is used as is. The returned client uses the default RetryConfig.
+func NewJWKSHTTPClient(
+	ctx context.Context, opts []option.ClientOption, httpOpts ...HTTPOption) (*HTTPClient, error) {
+	// Credential options are incompatible with WithoutAuthentication unless validation is skipped.
+	opts = append(append([]option.ClientOption{}, opts...),
+		option.WithoutAuthentication(), internaloption.SkipDialSettingsValidation())
+	hc, _, err := NewHTTPClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	hc.Opts = httpOpts
+	return hc, nil
+}
+
+// Keyfunc returns the public key that should be used to verify the given token.
+func (s *HTTPJWKSSource) Keyfunc(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	keys, err := s.keySet(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keys.Keyfunc(token)
+	if errors.Is(err, keyfunc.ErrKIDNotFound) && s.RefreshUnknownKID {
+		keys, refreshErr := s.keySet(ctx, true)
+		if refreshErr != nil {
+			return nil, err
+		}
+		return keys.Keyfunc(token)
+	}
+	return key, err
+}
+
+func (s *HTTPJWKSSource) keySet(ctx context.Context, unknownKID bool) (*keyfunc.JWKS, error) {
+	s.mutex.Lock()
+	if s.fetching != nil {
+		// Callers that cannot do anything useful with the cached keys wait for the fetch that is
+		// already in flight.
+		if s.keys != nil && !unknownKID {
+			defer s.mutex.Unlock()
+			return s.keys, nil
+		}
+		fetching := s.fetching
+		s.mutex.Unlock()
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return s.cachedKeySet()
+	}
+
+	if s.keys != nil && !s.shouldRefresh(unknownKID) {
+		defer s.mutex.Unlock()
+		return s.keys, nil
+	}
+	fetching := make(chan struct{})
+	s.fetching = fetching
+	s.lastAttempt = s.Clock.Now()
+	s.mutex.Unlock()
+
+	keys, err := s.fetch(ctx)
+
+	s.mutex.Lock()
+	if err == nil {
+		s.keys = keys
+	}
+	s.lastErr = err
+	s.fetching = nil
+	close(fetching)
+	s.mutex.Unlock()
+	return s.cachedKeySet()
+}
+
+// cachedKeySet returns the cached keys, or the error of the last fetch if there are none.
+func (s *HTTPJWKSSource) cachedKeySet() (*keyfunc.JWKS, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.keys != nil {
+		return s.keys, nil
+	}
+	return nil, s.lastErr
+}
+
+func (s *HTTPJWKSSource) shouldRefresh(unknownKID bool) bool {
+	elapsed := s.Clock.Now().Sub(s.lastAttempt)
+	if unknownKID {
+		return elapsed >= s.RefreshRateLimit
+	}
+	return s.RefreshInterval > 0 && elapsed >= s.RefreshInterval
+}
+
+func (s *HTTPJWKSSource) fetch(ctx context.Context) (*keyfunc.JWKS, error) {
+	req := &Request{
+		Method: http.MethodGet,
+		URL:    s.URL,
+	}
+	resp, err := s.HTTPClient.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := keyfunc.NewJSON(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS from %q: %v", s.URL, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,343 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/api/option"
+)
+
+const testKID = "FGQdnRlzAmKyKr6-Hg_kMQrBkj_H6i6ADnBQz4OI6BU"
+
+func TestHTTPJWKSSourceLazyLoad(t *testing.T) {
+	jwks, err := ioutil.ReadFile("../testdata/mock.jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if h := r.Header.Get("x-test"); h != "value" {
+			t.Errorf("Header(x-test) = %q; want = %q", h, "value")
+		}
+		w.Write(jwks)
+	}))
+	defer server.Close()
+
+	hc := &HTTPClient{
+		Client: http.DefaultClient,
+		Opts:   []HTTPOption{WithHeader("x-test", "value")},
+	}
+	source := NewHTTPJWKSSource(server.URL, hc)
+	if calls != 0 {
+		t.Fatalf("JWKS requests before first use = %d; want = 0", calls)
+	}
+
+	for i := 0; i < 3; i++ {
+		key, err := source.Keyfunc(context.Background(), tokenWithKID(testKID))
+		if key == nil || err != nil {
+			t.Fatalf("Keyfunc() = (%v, %v); want = (key, nil)", key, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("JWKS requests = %d; want = 1", calls)
+	}
+}
+
+func TestHTTPJWKSSourceRefreshInterval(t *testing.T) {
+	server, calls := jwksServer(t, http.StatusOK)
+	defer server.Close()
+
+	clock := &MockClock{Timestamp: time.Now()}
+	source := NewHTTPJWKSSource(server.URL, &HTTPClient{Client: http.DefaultClient})
+	source.RefreshInterval = time.Hour
+	source.Clock = clock
+
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); err != nil {
+		t.Fatal(err)
+	}
+	clock.Timestamp = clock.Timestamp.Add(59 * time.Minute)
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 1 {
+		t.Errorf("JWKS requests = %d; want = 1", *calls)
+	}
+
+	clock.Timestamp = clock.Timestamp.Add(time.Minute)
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 2 {
+		t.Errorf("JWKS requests = %d; want = 2", *calls)
+	}
+}
+
+func TestHTTPJWKSSourceUnknownKID(t *testing.T) {
+	server, calls := jwksServer(t, http.StatusOK)
+	defer server.Close()
+
+	clock := &MockClock{Timestamp: time.Now()}
+	source := NewHTTPJWKSSource(server.URL, &HTTPClient{Client: http.DefaultClient})
+	source.Clock = clock
+
+	// Without RefreshUnknownKID an unknown key ID does not trigger a refresh.
+	if key, err := source.Keyfunc(context.Background(), tokenWithKID("unknown")); key != nil || err == nil {
+		t.Errorf("Keyfunc(unknown) = (%v, %v); want = (nil, error)", key, err)
+	}
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID("unknown")); err == nil {
+		t.Errorf("Keyfunc(unknown) = nil; want = error")
+	}
+	if *calls != 1 {
+		t.Errorf("JWKS requests = %d; want = 1", *calls)
+	}
+
+	source.RefreshUnknownKID = true
+	source.RefreshRateLimit = 5 * time.Minute
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID("unknown")); err == nil {
+		t.Errorf("Keyfunc(unknown) = nil; want = error")
+	}
+	if *calls != 1 {
+		t.Errorf("JWKS requests within rate limit = %d; want = 1", *calls)
+	}
+
+	clock.Timestamp = clock.Timestamp.Add(5 * time.Minute)
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID("unknown")); err == nil {
+		t.Errorf("Keyfunc(unknown) = nil; want = error")
+	}
+	if *calls != 2 {
+		t.Errorf("JWKS requests after rate limit = %d; want = 2", *calls)
+	}
+}
+
+func TestHTTPJWKSSourceError(t *testing.T) {
+	server, calls := jwksServer(t, http.StatusInternalServerError)
+	defer server.Close()
+
+	source := NewHTTPJWKSSource(server.URL, &HTTPClient{Client: http.DefaultClient})
+	if key, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); key != nil || err == nil {
+		t.Errorf("Keyfunc() = (%v, %v); want = (nil, error)", key, err)
+	}
+
+	// Failed fetches are not cached.
+	source.Keyfunc(context.Background(), tokenWithKID(testKID))
+	if *calls != 2 {
+		t.Errorf("JWKS requests = %d; want = 2", *calls)
+	}
+}
+
+func TestHTTPJWKSSourceKeepsCachedKeysOnError(t *testing.T) {
+	status := http.StatusOK
+	jwks, err := ioutil.ReadFile("../testdata/mock.jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write(jwks)
+	}))
+	defer server.Close()
+
+	clock := &MockClock{Timestamp: time.Now()}
+	source := NewHTTPJWKSSource(server.URL, &HTTPClient{Client: http.DefaultClient})
+	source.RefreshInterval = time.Hour
+	source.Clock = clock
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); err != nil {
+		t.Fatal(err)
+	}
+
+	status = http.StatusInternalServerError
+	clock.Timestamp = clock.Timestamp.Add(2 * time.Hour)
+	if key, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); key == nil || err != nil {
+		t.Errorf("Keyfunc() = (%v, %v); want = (key, nil)", key, err)
+	}
+}
+
+func TestHTTPJWKSSourceRecordsFailedRefresh(t *testing.T) {
+	status := http.StatusOK
+	var calls int
+	jwks, err := ioutil.ReadFile("../testdata/mock.jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		w.Write(jwks)
+	}))
+	defer server.Close()
+
+	clock := &MockClock{Timestamp: time.Now()}
+	source := NewHTTPJWKSSource(server.URL, &HTTPClient{Client: http.DefaultClient})
+	source.RefreshInterval = time.Hour
+	source.Clock = clock
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); err != nil {
+		t.Fatal(err)
+	}
+
+	status = http.StatusInternalServerError
+	clock.Timestamp = clock.Timestamp.Add(2 * time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("JWKS requests = %d; want = 2", calls)
+	}
+
+	clock.Timestamp = clock.Timestamp.Add(time.Hour)
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("JWKS requests = %d; want = 3", calls)
+	}
+}
+
+func TestHTTPJWKSSourceDoesNotBlockOnRefresh(t *testing.T) {
+	jwks, err := ioutil.ReadFile("../testdata/mock.jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			close(blocked)
+			<-release
+		}
+		w.Write(jwks)
+	}))
+	defer server.Close()
+
+	clock := &MockClock{Timestamp: time.Now()}
+	source := NewHTTPJWKSSource(server.URL, &HTTPClient{Client: http.DefaultClient})
+	source.RefreshInterval = time.Hour
+	source.Clock = clock
+	if _, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Timestamp = clock.Timestamp.Add(2 * time.Hour)
+	done := make(chan error)
+	go func() {
+		_, err := source.Keyfunc(context.Background(), tokenWithKID(testKID))
+		done <- err
+	}()
+	<-blocked
+
+	// Cached keys are used while the refresh is in flight.
+	if key, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); key == nil || err != nil {
+		t.Errorf("Keyfunc() = (%v, %v); want = (key, nil)", key, err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("JWKS requests = %d; want = 2", n)
+	}
+}
+
+func TestNewJWKSHTTPClient(t *testing.T) {
+	var authHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	// Credentials of the App are not sent.
+	opts := []option.ClientOption{option.WithTokenSource(&MockTokenSource{AccessToken: "token"})}
+	hc, err := NewJWKSHTTPClient(context.Background(), opts, WithHeader("x-test", "value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hc.RetryConfig == nil || len(hc.Opts) != 1 {
+		t.Errorf("NewJWKSHTTPClient() = %#v; want = client with retries and options", hc)
+	}
+	resp, err := hc.Do(context.Background(), &Request{Method: http.MethodGet, URL: ts.URL})
+	if err != nil || resp.Status != http.StatusOK {
+		t.Fatalf("Do() = (%v, %v); want = (200, nil)", resp, err)
+	}
+	if authHeader != "" {
+		t.Errorf("Authorization = %q; want = none", authHeader)
+	}
+
+	// A client set with WithHTTPClient is used.
+	custom := &http.Client{}
+	hc, err = NewJWKSHTTPClient(context.Background(), []option.ClientOption{option.WithHTTPClient(custom)})
+	if err != nil || hc.Client != custom {
+		t.Errorf("NewJWKSHTTPClient(WithHTTPClient) = (%v, %v); want = custom client", hc, err)
+	}
+}
+
+func TestStaticJWKSSource(t *testing.T) {
+	jwks, err := ioutil.ReadFile("../testdata/mock.jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewStaticJWKSSource(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key, err := source.Keyfunc(context.Background(), tokenWithKID(testKID)); key == nil || err != nil {
+		t.Errorf("Keyfunc() = (%v, %v); want = (key, nil)", key, err)
+	}
+	if key, err := source.Keyfunc(context.Background(), tokenWithKID("unknown")); key != nil || err == nil {
+		t.Errorf("Keyfunc(unknown) = (%v, %v); want = (nil, error)", key, err)
+	}
+
+	if _, err := NewStaticJWKSSource([]byte("not json")); err == nil {
+		t.Errorf("NewStaticJWKSSource(invalid) = nil; want = error")
+	}
+}
+
+func jwksServer(t *testing.T, status int) (*httptest.Server, *int) {
+	jwks, err := ioutil.ReadFile("../testdata/mock.jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodGet {
+			t.Errorf("Method = %q; want = %q", r.Method, http.MethodGet)
+		}
+		w.WriteHeader(status)
+		w.Write(jwks)
+	}))
+	return server, &calls
+}
+
+func tokenWithKID(kid string) *jwt.Token {
+	return &jwt.Token{
+		Header: map[string]interface{}{
+			"alg": "RS256",
+			"kid": kid,
+		},
+	}
+}
@@ -18,21 +18,23 @@ package phonenumberverification
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"slices"
 	"strings"
 	"time"
 
-	"github.com/MicahParks/keyfunc"
 	"github.com/golang-jwt/jwt/v4"
 
 	"firebase.google.com/go/v4/internal"
 )
 
 const (
-	jwksURL      = "https://fpnv.googleapis.com/v1beta/jwks"
-	issuerPrefix = "https://fpnv.googleapis.com/projects/"
-	algorithm    = "ES256"
-	headerTyp    = "JWT"
+	jwksURL            = "https://fpnv.googleapis.com/v1beta/jwks"
+	issuerPrefix       = "https://fpnv.googleapis.com/projects/"
+	algorithm          = "ES256"
+	headerTyp          = "JWT"
+	emulatorHostEnvVar = "FIREBASE_PHONE_NUMBER_VERIFICATION_EMULATOR_HOST"
 )
 
 var (
@@ -81,27 +83,43 @@ type DecodedVerificationToken struct {
 // Client is the client for the Firebase Phone Number Verification service.
 type Client struct {
 	projectID string
-	jwks      *keyfunc.JWKS
+	keySource internal.JWKSSource
 }
 
 // NewClient creates a new instance of the Firebase Phone Number Verification Client.
 //
 // This function can only be invoked from within the SDK. Client applications should access the
 // FPNV service through firebase.App.
+//
+// The public keys used to verify tokens are fetched with the HTTP client options of the App, but
+// without its credentials. When the FIREBASE_PHONE_NUMBER_VERIFICATION_EMULATOR_HOST environment
+// variable is set, the keys are fetched from the emulator at that host.
 func NewClient(ctx context.Context, conf *internal.PhoneNumberVerificationConfig) (*Client, error) {
-	// TODO: Add support for overriding the HTTP client using the App one.
-	jwks, err := keyfunc.Get(jwksURL, keyfunc.Options{
-		Ctx:               ctx,
-		RefreshUnknownKID: true,
-		RefreshRateLimit:  5 * time.Minute, // Prevent network floods from malicious tokens
-	})
-	if err != nil {
-		return nil, err
+	keySource := conf.KeySource
+	if keySource == nil {
+		url := jwksURL
+		if host := os.Getenv(emulatorHostEnvVar); host != "" {
+			url = fmt.Sprintf("http://%s/fpnv.googleapis.com/v1beta/jwks", host)
+		}
+		if conf.JWKSURL != "" {
+			url = conf.JWKSURL
+		}
+
+		// The JWKS is public, so it is fetched without the credentials of the App.
+		hc, err := internal.NewJWKSHTTPClient(ctx, conf.Opts,
+			internal.WithHeader("x-goog-api-client", internal.GetMetricsHeader(conf.Version)))
+		if err != nil {
+			return nil, err
+		}
+		source := internal.NewHTTPJWKSSource(url, hc)
+		source.RefreshUnknownKID = true
+		source.RefreshRateLimit = 5 * time.Minute // Prevent network floods from malicious tokens
+		keySource = source
 	}
 
 	return &Client{
 		projectID: conf.ProjectID,
-		jwks:      jwks,
+		keySource: keySource,
 	}, nil
 }
 
@@ -116,6 +134,9 @@ func NewClient(ctx context.Context, conf *internal.PhoneNumberVerificationConfig
 //
 // If any of the above conditions are not met, an error is returned.
 // Otherwise, a pointer to a decoded FPNV token is returned.
+//
+// The public keys used to verify FPNV tokens are fetched on first use, and refreshed when a
+// token signed with an unknown key is encountered.
 func (c *Client) VerifyToken(token string) (*DecodedVerificationToken, error) {
 	if c.projectID == "" {
 		return nil, ErrProjectIDRequired
@@ -142,7 +163,7 @@ func (c *Client) VerifyToken(token string) (*DecodedVerificationToken, error) {
 		if !ok || typ != headerTyp {
 			return nil, ErrTokenType
 		}
-		return c.jwks.Keyfunc(t)
+		return c.keySource.Keyfunc(context.Background(), t)
 	})
 
 	if err != nil {
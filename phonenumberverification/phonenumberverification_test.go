@@ -21,13 +21,15 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"firebase.google.com/go/v4/internal"
-	"github.com/MicahParks/keyfunc"
 	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/api/option"
 )
 
 func TestNewClient(t *testing.T) {
@@ -66,6 +68,135 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewClientLazyJWKS(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid := "test-key-id"
+	jwksJSON, err := createJWKSJSON(&privateKey.PublicKey, kid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if h := r.Header.Get("Authorization"); h != "" {
+			t.Errorf("Authorization = %q; want = none", h)
+		}
+		w.Write(jwksJSON)
+	}))
+	defer ts.Close()
+
+	projectID := "my-project-id"
+	client, err := NewClient(context.Background(), &internal.PhoneNumberVerificationConfig{
+		ProjectID: projectID,
+		Opts:      []option.ClientOption{option.WithTokenSource(&internal.MockTokenSource{AccessToken: "test"})},
+		JWKSURL:   ts.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Fatalf("JWKS requests after NewClient() = %d; want = 0", calls)
+	}
+
+	token := generateToken(t, privateKey, &kid, jwt.MapClaims{
+		"iss": issuerPrefix + projectID,
+		"aud": []string{issuerPrefix + projectID},
+		"sub": "+15555550100",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	for i := 0; i < 2; i++ {
+		decoded, err := client.VerifyToken(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.PhoneNumber != "+15555550100" {
+			t.Errorf("PhoneNumber = %q; want = %q", decoded.PhoneNumber, "+15555550100")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("JWKS requests = %d; want = 1", calls)
+	}
+}
+
+func TestNewClientKeySource(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid := "test-key-id"
+	jwksJSON, err := createJWKSJSON(&privateKey.PublicKey, kid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keySource, err := internal.NewStaticJWKSSource(jwksJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projectID := "my-project-id"
+	client, err := NewClient(context.Background(), &internal.PhoneNumberVerificationConfig{
+		ProjectID: projectID,
+		JWKSURL:   "http://unused",
+		KeySource: keySource,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := generateToken(t, privateKey, &kid, jwt.MapClaims{
+		"iss": issuerPrefix + projectID,
+		"aud": []string{issuerPrefix + projectID},
+		"sub": "+15555550100",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := client.VerifyToken(token); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewClientEmulator(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid := "test-key-id"
+	jwksJSON, err := createJWKSJSON(&privateKey.PublicKey, kid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emulator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fpnv.googleapis.com/v1beta/jwks" {
+			t.Errorf("Path = %q; want = %q", r.URL.Path, "/fpnv.googleapis.com/v1beta/jwks")
+		}
+		w.Write(jwksJSON)
+	}))
+	defer emulator.Close()
+	t.Setenv(emulatorHostEnvVar, strings.TrimPrefix(emulator.URL, "http://"))
+
+	projectID := "my-project-id"
+	client, err := NewClient(context.Background(), &internal.PhoneNumberVerificationConfig{
+		ProjectID: projectID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := generateToken(t, privateKey, &kid, jwt.MapClaims{
+		"iss": issuerPrefix + projectID,
+		"aud": []string{issuerPrefix + projectID},
+		"sub": "+15555550100",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := client.VerifyToken(token); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestVerifyToken(t *testing.T) {
 	// Set up a valid EC key pair (P-256) matching the service's ES256 algorithm
 	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
@@ -81,8 +212,8 @@ func TestVerifyToken(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// Initialize the keyfunc from the JSON
-	jwks, err := keyfunc.NewJSON(jwksJSON)
+	// Initialize the key source from the JSON
+	jwks, err := internal.NewStaticJWKSSource(jwksJSON)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -91,7 +222,7 @@ func TestVerifyToken(t *testing.T) {
 	projectID := "my-project-id"
 	client := &Client{
 		projectID: projectID,
-		jwks:      jwks,
+		keySource: jwks,
 	}
 
 	// Common claims for valid tokens
@@ -129,7 +260,7 @@ func TestVerifyToken(t *testing.T) {
 			name: "No project ID",
 			client: &Client{
 				projectID: "",
-				jwks:      jwks,
+				keySource: jwks,
 			},
 			validAudience: issuerPrefix + "",
 			token: func() string {